@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/accrual/accrualtest"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	config := accrualtest.DefaultConfig()
+	address := flag.String("a", "localhost:8081", "Server address")
+	progression := flag.String("progression", strings.Join(config.Progression, ","), "Comma separated statuses of orders without scenario, one per request")
+	scenarioFile := flag.String("scenario", "", "YAML file with scripted statuses per order number")
+	flag.Float64Var(&config.Accrual, "accrual", config.Accrual, "Accrual of processed orders")
+	flag.Float64Var(&config.MaxAccrual, "max-accrual", config.MaxAccrual, "Upper bound of random accrual, ignored if not greater than accrual")
+	flag.BoolVar(&config.RegisterAll, "register-all", config.RegisterAll, "Answer all orders, otherwise unknown orders get 204 until registered")
+	flag.Float64Var(&config.RateLimit, "rps", config.RateLimit, "Allowed requests per second, 0 disables 429 responses")
+	flag.DurationVar(&config.RetryAfter, "retry-after", config.RetryAfter, "Retry-After of 429 responses")
+	flag.DurationVar(&config.Latency, "latency", config.Latency, "Delay of every response")
+	flag.Float64Var(&config.ErrorRate, "error-rate", config.ErrorRate, "Share of requests answered with 500")
+	flag.Int64Var(&config.Seed, "seed", time.Now().UnixNano(), "Seed of random accruals and failures")
+	flag.Parse()
+
+	logger, err := logging.New("info", "console")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer logger.Sync()
+
+	config.Progression = strings.Split(*progression, ",")
+	handler := accrualtest.NewHandler(config)
+	if *scenarioFile != "" {
+		if err := loadScenario(handler, *scenarioFile); err != nil {
+			logger.Fatal("Error during load scenario", zap.String("file", *scenarioFile), zap.Error(err))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: *address, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Error during start server", zap.Error(err))
+		}
+	}()
+	logger.Info("Accrual mock started", zap.String("address", *address))
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error during shutdown server", zap.Error(err))
+	}
+}
+
+func loadScenario(handler *accrualtest.Handler, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var scenario map[string][]accrualtest.Step
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return err
+	}
+
+	for orderNumber, steps := range scenario {
+		handler.Register(orderNumber, steps...)
+	}
+	return nil
+}
@@ -2,113 +2,279 @@ package main
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
 	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/desepticon55/gofemart/internal/api/account"
 	"github.com/desepticon55/gofemart/internal/api/auth"
 	"github.com/desepticon55/gofemart/internal/api/balance"
+	"github.com/desepticon55/gofemart/internal/api/export"
+	"github.com/desepticon55/gofemart/internal/api/health"
+	"github.com/desepticon55/gofemart/internal/api/identifier"
+	"github.com/desepticon55/gofemart/internal/api/intake"
 	customMiddleware "github.com/desepticon55/gofemart/internal/api/middleware"
 	"github.com/desepticon55/gofemart/internal/api/order"
+	"github.com/desepticon55/gofemart/internal/api/reward"
+	"github.com/desepticon55/gofemart/internal/api/statement"
 	"github.com/desepticon55/gofemart/internal/api/withdrawal"
+	"github.com/desepticon55/gofemart/internal/breaker"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/notification"
 	"github.com/desepticon55/gofemart/internal/service"
+	accntSrv "github.com/desepticon55/gofemart/internal/service/account"
+	"github.com/desepticon55/gofemart/internal/service/accountworker"
 	blcSrv "github.com/desepticon55/gofemart/internal/service/balance"
+	exprtSrv "github.com/desepticon55/gofemart/internal/service/export"
+	hlthSrv "github.com/desepticon55/gofemart/internal/service/health"
+	"github.com/desepticon55/gofemart/internal/service/holdworker"
+	intkSrv "github.com/desepticon55/gofemart/internal/service/intake"
+	"github.com/desepticon55/gofemart/internal/service/membership"
 	ordSrv "github.com/desepticon55/gofemart/internal/service/order"
 	"github.com/desepticon55/gofemart/internal/service/orderworker"
+	"github.com/desepticon55/gofemart/internal/service/ratelimit"
+	rwrdSrv "github.com/desepticon55/gofemart/internal/service/reward"
+	stmntSrv "github.com/desepticon55/gofemart/internal/service/statement"
+	"github.com/desepticon55/gofemart/internal/service/statementworker"
 	usrSrv "github.com/desepticon55/gofemart/internal/service/user"
 	wdrvlSrv "github.com/desepticon55/gofemart/internal/service/withdrawal"
 	"github.com/desepticon55/gofemart/internal/storage"
+	"github.com/desepticon55/gofemart/internal/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/gojek/heimdall/v7/httpclient"
 	"github.com/gojektech/heimdall"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-const (
-	workerCount = 4
-)
-
 func main() {
-	logger := initLogger()
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		printConfig(os.Args[3:])
+		return
+	}
+
+	config, err := internal.ParseConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logger := initLogger(config)
 	defer logger.Sync()
 
-	config := parseConfig()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	maskedConfig := config.Masked()
 	logger.Debug("Config created",
-		zap.String("Server address", config.ServerAddress),
-		zap.String("Database connection string", config.DatabaseConnString),
-		zap.String("Accrual system address", config.AccrualSystemAddress))
+		zap.String("Server address", maskedConfig.ServerAddress),
+		zap.String("Database connection string", maskedConfig.DatabaseConnString),
+		zap.String("Accrual system address", maskedConfig.AccrualSystemAddress))
+
+	shutdownTracing, err := tracing.Init(context.Background(), config.TracingExporter)
+	if err != nil {
+		logger.Fatal("Error during initialize tracing", zap.Error(err))
+	}
+	defer shutdownTracing(context.Background())
 
 	router := chi.NewRouter()
+	appMetrics := metrics.NewPrometheusMetrics()
+
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.RequestID)
+	router.Use(customMiddleware.TracingMiddleware())
 	router.Use(middleware.RealIP)
-	router.Use(middleware.Logger)
-	router.Use(middleware.Timeout(60 * time.Second))
+	router.Use(customMiddleware.AccessLogMiddleware(logger))
+	router.Use(customMiddleware.MetricsMiddleware(appMetrics))
 	router.Use(customMiddleware.CompressingMiddleware())
 	router.Use(customMiddleware.DecompressingMiddleware())
 
-	pool, err := createConnectionPool(context.Background(), config.DatabaseConnString)
+	pool, err := createConnectionPool(ctx, config)
 	if err != nil {
 		logger.Fatal("Error during initialize DB connection", zap.Error(err))
 	}
 	runMigrations(config.DatabaseConnString, logger)
+	appMetrics.RegisterPool(pool)
 
 	userRepository := storage.NewUserRepository(pool, logger)
-	userService := usrSrv.NewUserService(logger, userRepository)
+	userService := usrSrv.NewUserService(logger, userRepository, notification.NewLogNotifier(logger))
 
 	orderRepository := storage.NewOrderRepository(pool, logger)
 	orderService := ordSrv.NewOrderService(logger, orderRepository)
+	intakeService := intkSrv.NewIntakeService(logger, orderRepository, appMetrics)
 
 	balanceRepository := storage.NewBalanceRepository(pool, logger)
-	balanceService := blcSrv.NewBalanceService(logger, balanceRepository)
+	balanceService := blcSrv.NewBalanceService(logger, balanceRepository, appMetrics)
 
 	withdrawalRepository := storage.NewWithdrawalRepository(pool, logger)
 	withdrawalService := wdrvlSrv.NewWithdrawalService(logger, withdrawalRepository)
 
-	router.Method(http.MethodPost, "/api/user/register", auth.RegisterHandler(logger, userService)) //регистрация пользователя
-	router.Method(http.MethodPost, "/api/user/login", auth.LoginHandler(logger, userService))       //аутентификация пользователя
+	rewardRepository := storage.NewRewardRepository(pool, logger)
+	rewardService := rwrdSrv.NewRewardService(logger, rewardRepository, balanceRepository)
+
+	exportRepository := storage.NewExportRepository(pool, logger)
+	exportService := exprtSrv.NewExportService(logger, exportRepository)
+
+	documentStore := storage.NewFileStore(config.StatementsDir)
+	statementRepository := storage.NewStatementRepository(pool, logger)
+	statementService := stmntSrv.NewStatementService(logger, statementRepository, documentStore)
+
+	accountRepository := storage.NewAccountRepository(pool, logger)
+	accountService := accntSrv.NewAccountService(logger, accountRepository)
+
+	workerRegistry := hlthSrv.NewWorkerRegistry()
+	accrualBreaker := breaker.NewCircuitBreaker(logger, appMetrics, "accrual", breaker.Settings{
+		FailureThreshold: config.AccrualBreakerFailureThreshold,
+		OpenTimeout:      config.AccrualBreakerOpenTimeout,
+		HalfOpenRequests: config.AccrualBreakerHalfOpenRequests,
+	})
+	accrualProbe := hlthSrv.NewAccrualProbe(&http.Client{Timeout: config.AccrualTimeout}, config.AccrualSystemAddress, config.AccrualProbeTTL)
+	healthService := hlthSrv.NewHealthService(logger, storage.NewHealthRepository(pool, logger), accrualProbe, workerRegistry, lastMigrationVersion(logger), accrualBreaker)
+
+	requestTimeout := middleware.Timeout(config.RequestTimeout)
+
+	router.Group(func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Method(http.MethodGet, "/healthz", health.LivenessHandler(logger))                //проверка, что процесс жив
+		r.Method(http.MethodGet, "/readyz", health.ReadinessHandler(logger, healthService)) //проверка готовности сервиса принимать запросы
+
+		r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(appMetrics.Registry(), promhttp.HandlerOpts{DisableCompression: true})) //метрики Prometheus
+		r.Method(http.MethodPost, "/api/user/register", auth.RegisterHandler(logger, userService))                                       //регистрация пользователя
+		r.Method(http.MethodPost, "/api/user/login", auth.LoginHandler(logger, userService))                                             //аутентификация пользователя
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Use(customMiddleware.CheckAuthMiddleware(logger, userService))
+		r.Method(http.MethodPost, "/api/user/orders", order.UploadOrderHandler(logger, orderService))                                //загрузка пользователем номера заказа для расчёта
+		r.Method(http.MethodPost, "/api/user/orders/batch", order.UploadOrdersBatchHandler(logger, orderService))                    //пакетная загрузка номеров заказов
+		r.Method(http.MethodPost, "/api/user/balance/withdraw", balance.WithdrawBalanceHandler(logger, balanceService))              //запрос на списание баллов с накопительного счёта в счёт оплаты нового заказа
+		r.Method(http.MethodGet, "/api/user/orders", order.FindAllOrdersHandler(logger, orderService))                               //получение списка загруженных пользователем номеров заказов, статусов их обработки и информации о начислениях
+		r.Method(http.MethodGet, "/api/user/orders/{number}", order.FindOrderHandler(logger, orderService))                          //получение заказа с историей изменения статусов
+		r.Method(http.MethodGet, "/api/user/balance", balance.FindUserBalanceHandler(logger, balanceService))                        //получение текущего баланса счёта баллов лояльности пользователя
+		r.Method(http.MethodGet, "/api/user/withdrawals", withdrawal.FindAllWithdrawalsHandler(logger, withdrawalService))           //получение информации о выводе средств с накопительного счёта пользователем
+		r.Method(http.MethodPost, "/api/user/balance/holds", balance.AuthorizeHandler(logger, balanceService))                       //резервирование баллов под оплату заказа
+		r.Method(http.MethodPost, "/api/user/balance/holds/{id}/capture", balance.CaptureHoldHandler(logger, balanceService))        //списание зарезервированных баллов
+		r.Method(http.MethodPost, "/api/user/balance/holds/{id}/void", balance.VoidHoldHandler(logger, balanceService))              //отмена резервирования баллов
+		r.Method(http.MethodGet, "/api/user/rewards", reward.FindAvailableRewardsHandler(logger, rewardService))                     //получение каталога доступных наград
+		r.Method(http.MethodPost, "/api/user/rewards/{id}/redeem", reward.RedeemRewardHandler(logger, rewardService))                //обмен баллов на награду из каталога
+		r.Method(http.MethodGet, "/api/user/statements", statement.FindStatementsHandler(logger, statementService))                  //получение списка ежемесячных выписок
+		r.Method(http.MethodGet, "/api/user/statements/{id}/{format}", statement.DownloadStatementHandler(logger, statementService)) //скачивание выписки в PDF или CSV
+		r.Method(http.MethodDelete, "/api/user", account.RequestDeletionHandler(logger, accountService))                             //запрос на удаление учётной записи
+		r.Method(http.MethodPost, "/api/user/restore", account.CancelDeletionHandler(logger, accountService))                        //отмена удаления учётной записи
+		r.Method(http.MethodGet, "/api/user/data-export", account.DataExportHandler(logger, accountService))                         //выгрузка всех персональных данных пользователя
+		r.Method(http.MethodPut, "/api/user/login", auth.ChangeLoginHandler(logger, userService))                                    //смена логина пользователя
+		r.Method(http.MethodGet, "/api/user/identifiers", identifier.FindIdentifiersHandler(logger, userService))                    //получение списка email и телефонов пользователя
+		r.Method(http.MethodPost, "/api/user/identifiers", identifier.AddIdentifierHandler(logger, userService))                     //добавление email или телефона с отправкой кода подтверждения
+		r.Method(http.MethodPost, "/api/user/identifiers/{id}/verify", identifier.VerifyIdentifierHandler(logger, userService))      //подтверждение email или телефона кодом
+		r.Method(http.MethodDelete, "/api/user/identifiers/{id}", identifier.DeleteIdentifierHandler(logger, userService))           //удаление email или телефона
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Use(customMiddleware.CheckAdminMiddleware(logger, config.AdminToken))
+		r.Method(http.MethodGet, "/api/admin/rewards", reward.FindAllRewardsHandler(logger, rewardService))                //получение всего каталога наград
+		r.Method(http.MethodPost, "/api/admin/rewards", reward.CreateRewardHandler(logger, rewardService))                 //добавление награды в каталог
+		r.Method(http.MethodPut, "/api/admin/rewards/{id}", reward.UpdateRewardHandler(logger, rewardService))             //изменение награды
+		r.Method(http.MethodDelete, "/api/admin/rewards/{id}", reward.DeleteRewardHandler(logger, rewardService))          //снятие награды с публикации
+		r.Method(http.MethodGet, "/debug/status", health.StatusHandler(logger, healthService))                             //подробное состояние зависимостей и воркеров
+		r.Method(http.MethodGet, "/api/admin/orders/dead-letter", order.FindDeadLetterOrdersHandler(logger, orderService)) //получение заказов, исчерпавших попытки обработки
+		r.Method(http.MethodPost, "/api/admin/orders/{number}/replay", order.ReplayOrderHandler(logger, orderService))     //повторная постановка заказа в обработку
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Use(customMiddleware.CheckSignatureMiddleware(logger, config.AccrualCallbackSecret))
+		r.Method(http.MethodPost, "/api/internal/accrual/callback", intake.AccrualCallbackHandler(logger, intakeService)) //приём результата расчёта начислений от системы начислений
+	})
 
 	router.Group(func(r chi.Router) {
-		r.Use(customMiddleware.CheckAuthMiddleware(logger))
-		r.Method(http.MethodPost, "/api/user/orders", order.UploadOrderHandler(logger, orderService))                      //загрузка пользователем номера заказа для расчёта
-		r.Method(http.MethodPost, "/api/user/balance/withdraw", balance.WithdrawBalanceHandler(logger, balanceService))    //запрос на списание баллов с накопительного счёта в счёт оплаты нового заказа
-		r.Method(http.MethodGet, "/api/user/orders", order.FindAllOrdersHandler(logger, orderService))                     //получение списка загруженных пользователем номеров заказов, статусов их обработки и информации о начислениях
-		r.Method(http.MethodGet, "/api/user/balance", balance.FindUserBalanceHandler(logger, balanceService))              //получение текущего баланса счёта баллов лояльности пользователя
-		r.Method(http.MethodGet, "/api/user/withdrawals", withdrawal.FindAllWithdrawalsHandler(logger, withdrawalService)) //получение информации о выводе средств с накопительного счёта пользователем
+		r.Use(middleware.Timeout(config.ExportTimeout))
+		r.Use(customMiddleware.CheckAuthMiddleware(logger, userService))
+		r.Method(http.MethodGet, "/api/user/export", export.ExportTransactionsHandler(logger, exportService)) //выгрузка истории операций пользователя в CSV или JSON
 	})
 
-	interval := service.Module / workerCount
+	holdWorker := holdworker.NewWorker(logger, balanceRepository, config.HoldInterval)
+	go holdWorker.ExpireHolds(ctx)
+
+	statementWorker := statementworker.NewWorker(logger, statementRepository, documentStore, config.StatementInterval)
+	go statementWorker.GenerateStatements(ctx)
 
-	backoff := heimdall.NewExponentialBackoff(1*time.Second, 5*time.Second, 2, 0)
-	client := httpclient.NewClient(
-		httpclient.WithHTTPTimeout(1*time.Second),
+	accountWorker := accountworker.NewWorker(logger, accountRepository, documentStore, config.AccountInterval)
+	go accountWorker.DeleteAccounts(ctx)
+
+	queueMonitor := orderworker.NewQueueMonitor(logger, orderRepository, appMetrics, config.QueueMonitorInterval)
+	go queueMonitor.ReportQueueDepth(ctx)
+
+	backoff := heimdall.NewExponentialBackoff(config.AccrualBackoffMin, config.AccrualBackoffMax, config.AccrualBackoffFactor, 0)
+	httpClient := httpclient.NewClient(
+		httpclient.WithHTTPClient(&http.Client{Timeout: config.AccrualTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}),
 		httpclient.WithRetrier(heimdall.NewRetrier(backoff)),
-		httpclient.WithRetryCount(3),
+		httpclient.WithRetryCount(config.AccrualRetryCount),
 	)
+	accrualClient := accrual.NewHTTPClient(breaker.NewClient(httpClient, accrualBreaker), config.AccrualSystemAddress, appMetrics)
+
+	retryPolicy := orderworker.RetryPolicy{BaseDelay: config.OrderRetryBaseDelay, MaxDelay: config.OrderRetryMaxDelay, MaxAttempts: config.OrderMaxAttempts}
+	lookupPolicy := orderworker.LookupPolicy{Mode: config.AccrualLookupMode, BatchSize: config.AccrualBatchSize, Concurrency: config.AccrualConcurrency}
+	accrualLimiter := ratelimit.NewSharedLimiter(logger, storage.NewRateLimitRepository(pool, logger), "accrual", config.AccrualRateLimit, config.AccrualRateBurst)
+	if err := accrualLimiter.Init(ctx); err != nil {
+		logger.Fatal("Error during init accrual rate limit", zap.Error(err))
+	}
+
+	membershipRepository := storage.NewMembershipRepository(pool, logger)
+	instanceID := newInstanceID()
+	for i := 0; i < config.OrderWorkerCount; i++ {
+		workerID := fmt.Sprintf("%s-%d", instanceID, i)
+		workerMembership := membership.NewMembership(logger, membershipRepository, workerID, service.Module, config.HeartbeatInterval, config.MembershipTTL)
+		go workerMembership.Run(ctx)
 
-	for i := 0; i < workerCount; i++ {
-		from := i * interval
-		to := from + interval
-		worker := orderworker.NewWorker(logger, orderRepository, client, from, to)
+		worker := orderworker.NewWorker(logger, orderRepository, accrualClient, accrualLimiter, accrualBreaker, appMetrics, workerRegistry, workerMembership, workerID, config.OrderLeaseTTL, config.AccrualCallbackWindow, retryPolicy, lookupPolicy)
 
-		go worker.ProcessOrders(context.Background(), config.AccrualSystemAddress)
+		go worker.ProcessOrders(ctx)
 	}
 
-	http.ListenAndServe(config.ServerAddress, router)
+	server := &http.Server{Addr: config.ServerAddress, Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Error during start server", zap.Error(err))
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("Shutting down server")
+	healthService.Shutdown()
+	time.Sleep(config.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error during shutdown server", zap.Error(err))
+	}
 }
 
-func createConnectionPool(ctx context.Context, connectionString string) (*pgxpool.Pool, error) {
-	config, err := pgxpool.ParseConfig(connectionString)
+func createConnectionPool(ctx context.Context, appConfig internal.Config) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(appConfig.DatabaseConnString)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing database config: %w", err)
 	}
+	config.MaxConns = int32(appConfig.DatabaseMaxConns)
+	config.MinConns = int32(appConfig.DatabaseMinConns)
+	config.MaxConnLifetime = appConfig.DatabaseConnLifetime
+	config.MaxConnIdleTime = appConfig.DatabaseConnIdleTime
+	config.ConnConfig.Logger = tracing.NewPgxLogger()
+	config.ConnConfig.LogLevel = pgx.LogLevelInfo
 
 	pool, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
@@ -133,20 +299,46 @@ func runMigrations(connectionString string, logger *zap.Logger) {
 	}
 }
 
-func parseConfig() internal.Config {
-	config := internal.ParseConfig()
-	flag.Parse()
-	return config
+func lastMigrationVersion(logger *zap.Logger) int64 {
+	migrations, err := goose.CollectMigrations("migrations", 0, goose.MaxVersion)
+	if err != nil {
+		logger.Error("Error during collect database migrations", zap.Error(err))
+		return 0
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		logger.Error("Error during find last database migration", zap.Error(err))
+		return 0
+	}
+	return last.Version
+}
+
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "gophermart"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+}
+
+func printConfig(args []string) {
+	config, err := internal.ParseConfig(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	content, err := config.Masked().YAML()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(string(content))
 }
 
-func initLogger() *zap.Logger {
-	level := zap.NewAtomicLevel()
-	level.SetLevel(zap.DebugLevel)
-	productionConfig := zap.NewProductionConfig()
-	productionConfig.Encoding = "console"
-	productionConfig.Level = level
-	productionConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	logger, err := productionConfig.Build()
+func initLogger(config internal.Config) *zap.Logger {
+	logger, err := logging.New(config.LogLevel, config.LogEncoding)
 	if err != nil {
 		panic(err)
 	}
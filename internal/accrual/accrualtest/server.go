@@ -0,0 +1,245 @@
+// Package accrualtest implements the accrual system API for local development and tests.
+package accrualtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/time/rate"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Step struct {
+	Status  string  `json:"status" yaml:"status"`
+	Accrual float64 `json:"accrual,omitempty" yaml:"accrual"`
+}
+
+type Config struct {
+	Accrual     float64
+	MaxAccrual  float64
+	Progression []string
+	RegisterAll bool
+	RateLimit   float64
+	RetryAfter  time.Duration
+	Latency     time.Duration
+	ErrorRate   float64
+	Seed        int64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Accrual:     500,
+		Progression: []string{accrual.RegisteredStatus, accrual.ProcessingStatus, accrual.ProcessedStatus},
+		RegisterAll: true,
+		RetryAfter:  60 * time.Second,
+	}
+}
+
+type order struct {
+	steps    []Step
+	requests int
+}
+
+type Handler struct {
+	config  Config
+	limiter *rate.Limiter
+	router  chi.Router
+	mu      sync.Mutex
+	random  *rand.Rand
+	orders  map[string]*order
+}
+
+func NewHandler(config Config) *Handler {
+	h := &Handler{
+		config: config,
+		random: rand.New(rand.NewSource(config.Seed)),
+		orders: make(map[string]*order),
+	}
+	if config.RateLimit > 0 {
+		h.limiter = rate.NewLimiter(rate.Limit(config.RateLimit), max(1, int(config.RateLimit)))
+	}
+
+	router := chi.NewRouter()
+	router.Get("/api/orders/{number}", h.findOrder)
+	router.Post("/api/orders/batch", h.findOrders)
+	router.Post("/api/orders", h.registerOrder)
+	h.router = router
+	return h
+}
+
+func (h *Handler) Register(orderNumber string, steps ...Step) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.orders[orderNumber] = &order{steps: steps}
+}
+
+func (h *Handler) Requests(orderNumber string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if o, ok := h.orders[orderNumber]; ok {
+		return o.requests
+	}
+	return 0
+}
+
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	h.router.ServeHTTP(writer, request)
+}
+
+func (h *Handler) admit(writer http.ResponseWriter) bool {
+	if h.config.Latency > 0 {
+		time.Sleep(h.config.Latency)
+	}
+
+	if h.limiter != nil && !h.limiter.Allow() {
+		writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(h.config.RetryAfter.Seconds()))))
+		writer.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(writer, "No more than %d requests per minute allowed", int(h.config.RateLimit*60))
+		return false
+	}
+	return true
+}
+
+func (h *Handler) findOrder(writer http.ResponseWriter, request *http.Request) {
+	if !h.admit(writer) {
+		return
+	}
+
+	step, ok := h.nextStep(chi.URLParam(request, "number"))
+	if !ok {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if step == nil {
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Order string `json:"order"`
+		Step
+	}{Order: chi.URLParam(request, "number"), Step: *step}
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(response)
+}
+
+func (h *Handler) findOrders(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Orders []string `json:"orders"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.admit(writer) {
+		return
+	}
+
+	type result struct {
+		Order string `json:"order"`
+		Step
+	}
+	response := make([]result, 0, len(body.Orders))
+	for _, orderNumber := range body.Orders {
+		step, ok := h.nextStep(orderNumber)
+		if !ok {
+			continue
+		}
+		if step == nil {
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		response = append(response, result{Order: orderNumber, Step: *step})
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(response)
+}
+
+func (h *Handler) registerOrder(writer http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Order string `json:"order"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Order == "" {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.orders[body.Order]; ok {
+		http.Error(writer, "Order is already registered", http.StatusConflict)
+		return
+	}
+	h.orders[body.Order] = &order{}
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) nextStep(orderNumber string) (*Step, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	o, ok := h.orders[orderNumber]
+	if !ok {
+		if !h.config.RegisterAll {
+			return nil, false
+		}
+		o = &order{}
+		h.orders[orderNumber] = o
+	}
+	o.requests++
+
+	if h.config.ErrorRate > 0 && h.random.Float64() < h.config.ErrorRate {
+		return nil, true
+	}
+
+	if len(o.steps) == 0 {
+		o.steps = h.progression()
+	}
+	step := o.steps[min(o.requests, len(o.steps))-1]
+	return &step, true
+}
+
+func (h *Handler) progression() []Step {
+	statuses := h.config.Progression
+	if len(statuses) == 0 {
+		statuses = []string{accrual.ProcessedStatus}
+	}
+
+	steps := make([]Step, len(statuses))
+	for i, status := range statuses {
+		steps[i] = Step{Status: status}
+		if status == accrual.ProcessedStatus {
+			steps[i].Accrual = h.randomAccrual()
+		}
+	}
+	return steps
+}
+
+func (h *Handler) randomAccrual() float64 {
+	if h.config.MaxAccrual <= h.config.Accrual {
+		return h.config.Accrual
+	}
+	value := h.config.Accrual + h.random.Float64()*(h.config.MaxAccrual-h.config.Accrual)
+	return math.Round(value*100) / 100
+}
+
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+func NewServer(config Config) *Server {
+	handler := NewHandler(config)
+	return &Server{Server: httptest.NewServer(handler), Handler: handler}
+}
@@ -0,0 +1,123 @@
+package accrualtest
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should follow progression and stay on last status", func(t *testing.T) {
+		server := NewServer(DefaultConfig())
+		defer server.Close()
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		var statuses []string
+		for i := 0; i < 4; i++ {
+			result, err := client.FindOrder(ctx, "12345678903")
+			assert.NoError(t, err)
+			statuses = append(statuses, result.Status)
+		}
+
+		assert.Equal(t, []string{model.ProcessingOrderStatus, model.ProcessingOrderStatus, model.ProcessedOrderStatus, model.ProcessedOrderStatus}, statuses)
+		assert.Equal(t, 4, server.Requests("12345678903"))
+	})
+
+	t.Run("should answer scripted steps", func(t *testing.T) {
+		server := NewServer(DefaultConfig())
+		defer server.Close()
+		server.Register("12345678903", Step{Status: accrual.InvalidStatus})
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		result, err := client.FindOrder(ctx, "12345678903")
+
+		assert.NoError(t, err)
+		assert.Equal(t, accrual.Result{OrderNumber: "12345678903", Status: model.InvalidOrderStatus}, result)
+	})
+
+	t.Run("should return 204 for unknown orders", func(t *testing.T) {
+		config := DefaultConfig()
+		config.RegisterAll = false
+		server := NewServer(config)
+		defer server.Close()
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		_, err := client.FindOrder(ctx, "12345678903")
+		assert.ErrorIs(t, err, accrual.ErrNotRegistered)
+
+		resp, err := server.Client().Post(server.URL+"/api/orders", "application/json", strings.NewReader(`{"order":"12345678903"}`))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		_, err = client.FindOrder(ctx, "12345678903")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should answer registered orders of batch", func(t *testing.T) {
+		config := DefaultConfig()
+		config.RegisterAll = false
+		server := NewServer(config)
+		defer server.Close()
+		server.Register("12345678903", Step{Status: accrual.ProcessedStatus, Accrual: 100})
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		results, err := client.FindOrders(ctx, []string{"12345678903", "79927398713"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]accrual.Result{
+			"12345678903": {OrderNumber: "12345678903", Status: model.ProcessedOrderStatus, Accrual: 100},
+		}, results)
+		assert.Equal(t, 1, server.Requests("12345678903"))
+	})
+
+	t.Run("should limit requests", func(t *testing.T) {
+		config := DefaultConfig()
+		config.RateLimit = 1
+		config.RetryAfter = 5 * time.Second
+		server := NewServer(config)
+		defer server.Close()
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		_, err := client.FindOrder(ctx, "12345678903")
+		assert.NoError(t, err)
+
+		_, err = client.FindOrder(ctx, "12345678903")
+		assert.Equal(t, &accrual.ErrRateLimited{RetryAfter: 5 * time.Second, RequestsPerMinute: 60}, err)
+	})
+
+	t.Run("should inject failures", func(t *testing.T) {
+		config := DefaultConfig()
+		config.ErrorRate = 1
+		server := NewServer(config)
+		defer server.Close()
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		_, err := client.FindOrder(ctx, "12345678903")
+		assert.ErrorContains(t, err, "500")
+	})
+
+	t.Run("should pay random accrual in range", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Progression = []string{accrual.ProcessedStatus}
+		config.Accrual = 100
+		config.MaxAccrual = 200
+		server := NewServer(config)
+		defer server.Close()
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+
+		result, err := client.FindOrder(ctx, "12345678903")
+
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, result.Accrual, 100.0)
+		assert.LessOrEqual(t, result.Accrual, 200.0)
+	})
+}
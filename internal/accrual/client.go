@@ -0,0 +1,190 @@
+package accrual
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	RegisteredStatus = "REGISTERED"
+	InvalidStatus    = "INVALID"
+	ProcessingStatus = "PROCESSING"
+	ProcessedStatus  = "PROCESSED"
+
+	defaultRetryAfter = 60 * time.Second
+	maxErrorBodySize  = 1024
+)
+
+var (
+	ErrNotRegistered       = errors.New("order is not registered in accrual system")
+	ErrBatchIsNotSupported = errors.New("accrual system does not support batch lookup")
+
+	rateLimitPattern = regexp.MustCompile(`No more than (\d+) requests per minute allowed`)
+)
+
+type ErrRateLimited struct {
+	RetryAfter        time.Duration
+	RequestsPerMinute int
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("accrual system rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+type Result struct {
+	OrderNumber string
+	Status      string
+	Accrual     float64
+}
+
+type Client interface {
+	FindOrder(ctx context.Context, orderNumber string) (Result, error)
+
+	FindOrders(ctx context.Context, orderNumbers []string) (map[string]Result, error)
+}
+
+type HTTPClient struct {
+	doer    doer
+	address string
+	metrics metrics.Metrics
+}
+
+func NewHTTPClient(doer doer, address string, metrics metrics.Metrics) *HTTPClient {
+	return &HTTPClient{doer: doer, address: address, metrics: metrics}
+}
+
+func (c *HTTPClient) FindOrder(ctx context.Context, orderNumber string) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+"/api/orders/"+url.PathEscape(orderNumber), nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("error during create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		c.metrics.ObserveAccrualRequest(0, time.Since(start))
+		return Result{}, fmt.Errorf("%w: %w", model.ErrAccrualIsUnavailable, err)
+	}
+	defer resp.Body.Close()
+	c.metrics.ObserveAccrualRequest(resp.StatusCode, time.Since(start))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return decodeResult(resp.Body)
+	case http.StatusNoContent:
+		return Result{}, ErrNotRegistered
+	case http.StatusTooManyRequests:
+		return Result{}, rateLimited(resp)
+	default:
+		return Result{}, fmt.Errorf("unexpected accrual response status: %s", resp.Status)
+	}
+}
+
+func (c *HTTPClient) FindOrders(ctx context.Context, orderNumbers []string) (map[string]Result, error) {
+	body, err := json.Marshal(struct {
+		Orders []string `json:"orders"`
+	}{Orders: orderNumbers})
+	if err != nil {
+		return nil, fmt.Errorf("error during encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.address+"/api/orders/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error during create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		c.metrics.ObserveAccrualRequest(0, time.Since(start))
+		return nil, fmt.Errorf("%w: %w", model.ErrAccrualIsUnavailable, err)
+	}
+	defer resp.Body.Close()
+	c.metrics.ObserveAccrualRequest(resp.StatusCode, time.Since(start))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return decodeResults(resp.Body)
+	case http.StatusNoContent, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil, ErrBatchIsNotSupported
+	case http.StatusTooManyRequests:
+		return nil, rateLimited(resp)
+	default:
+		return nil, fmt.Errorf("unexpected accrual response status: %s", resp.Status)
+	}
+}
+
+func decodeResults(body io.Reader) (map[string]Result, error) {
+	var response []struct {
+		Order   string  `json:"order"`
+		Status  string  `json:"status"`
+		Accrual float64 `json:"accrual"`
+	}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error during decode response: %w", err)
+	}
+
+	results := make(map[string]Result, len(response))
+	for _, item := range response {
+		status, err := OrderStatus(item.Status)
+		if err != nil {
+			return nil, err
+		}
+		results[item.Order] = Result{OrderNumber: item.Order, Status: status, Accrual: item.Accrual}
+	}
+	return results, nil
+}
+
+func decodeResult(body io.Reader) (Result, error) {
+	var response struct {
+		Order   string  `json:"order"`
+		Status  string  `json:"status"`
+		Accrual float64 `json:"accrual"`
+	}
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return Result{}, fmt.Errorf("error during decode response: %w", err)
+	}
+
+	status, err := OrderStatus(response.Status)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{OrderNumber: response.Order, Status: status, Accrual: response.Accrual}, nil
+}
+
+func OrderStatus(status string) (string, error) {
+	switch status {
+	case RegisteredStatus, ProcessingStatus:
+		return model.ProcessingOrderStatus, nil
+	case InvalidStatus:
+		return model.InvalidOrderStatus, nil
+	case ProcessedStatus:
+		return model.ProcessedOrderStatus, nil
+	}
+	return "", fmt.Errorf("unknown accrual order status %q", status)
+}
+
+func rateLimited(resp *http.Response) *ErrRateLimited {
+	err := &ErrRateLimited{RetryAfter: defaultRetryAfter}
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds >= 0 {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if match := rateLimitPattern.FindSubmatch(body); match != nil {
+		err.RequestsPerMinute, _ = strconv.Atoi(string(match[1]))
+	}
+	return err
+}
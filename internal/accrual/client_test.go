@@ -0,0 +1,184 @@
+package accrual
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_FindOrder(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedResult Result
+		expectedError  error
+		errorContains  string
+	}{
+		{
+			name: "Processed order",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/orders/12345678903", r.URL.Path)
+				w.Write([]byte(`{"order":"12345678903","status":"PROCESSED","accrual":500}`))
+			},
+			expectedResult: Result{OrderNumber: "12345678903", Status: model.ProcessedOrderStatus, Accrual: 500},
+		},
+		{
+			name: "Registered order is processing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"order":"12345678903","status":"REGISTERED"}`))
+			},
+			expectedResult: Result{OrderNumber: "12345678903", Status: model.ProcessingOrderStatus},
+		},
+		{
+			name: "Invalid order",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"order":"12345678903","status":"INVALID"}`))
+			},
+			expectedResult: Result{OrderNumber: "12345678903", Status: model.InvalidOrderStatus},
+		},
+		{
+			name: "Unknown status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"order":"12345678903","status":"COMPLETED"}`))
+			},
+			errorContains: `unknown accrual order status "COMPLETED"`,
+		},
+		{
+			name: "Order is not registered",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedError: ErrNotRegistered,
+		},
+		{
+			name: "Rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte("No more than 120 requests per minute allowed"))
+			},
+			expectedError: &ErrRateLimited{RetryAfter: 30 * time.Second, RequestsPerMinute: 120},
+		},
+		{
+			name: "Rate limited without Retry-After",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			expectedError: &ErrRateLimited{RetryAfter: defaultRetryAfter},
+		},
+		{
+			name: "Internal server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			errorContains: "unexpected accrual response status: 500 Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			client := NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+			result, err := client.FindOrder(context.Background(), "12345678903")
+
+			switch {
+			case tt.errorContains != "":
+				assert.ErrorContains(t, err, tt.errorContains)
+			case tt.expectedError != nil:
+				assert.Equal(t, tt.expectedError, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+
+	t.Run("Accrual system is unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		client := NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+		_, err := client.FindOrder(context.Background(), "12345678903")
+
+		assert.ErrorIs(t, err, model.ErrAccrualIsUnavailable)
+	})
+}
+
+func TestHTTPClient_FindOrders(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		expectedResults map[string]Result
+		expectedError   error
+		errorContains   string
+	}{
+		{
+			name: "Registered orders",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/orders/batch", r.URL.Path)
+				w.Write([]byte(`[{"order":"12345678903","status":"PROCESSED","accrual":500},{"order":"79927398713","status":"REGISTERED"}]`))
+			},
+			expectedResults: map[string]Result{
+				"12345678903": {OrderNumber: "12345678903", Status: model.ProcessedOrderStatus, Accrual: 500},
+				"79927398713": {OrderNumber: "79927398713", Status: model.ProcessingOrderStatus},
+			},
+		},
+		{
+			name: "Orders are not registered",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[]`))
+			},
+			expectedResults: map[string]Result{},
+		},
+		{
+			name: "Batch is answered as unknown order",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedError: ErrBatchIsNotSupported,
+		},
+		{
+			name: "Rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			expectedError: &ErrRateLimited{RetryAfter: 30 * time.Second},
+		},
+		{
+			name: "Batch is not supported",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedError: ErrBatchIsNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			client := NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+			results, err := client.FindOrders(context.Background(), []string{"12345678903", "79927398713"})
+
+			switch {
+			case tt.errorContains != "":
+				assert.ErrorContains(t, err, tt.errorContains)
+			case tt.expectedError != nil:
+				assert.Equal(t, tt.expectedError, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResults, results)
+			}
+		})
+	}
+}
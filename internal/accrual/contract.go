@@ -0,0 +1,9 @@
+package accrual
+
+import (
+	"net/http"
+)
+
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
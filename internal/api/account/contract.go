@@ -0,0 +1,14 @@
+package account
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type accountService interface {
+	RequestDeletion(ctx context.Context, settlement string) (model.AccountDeletion, error)
+
+	CancelDeletion(ctx context.Context) error
+
+	ExportData(ctx context.Context) (model.UserData, error)
+}
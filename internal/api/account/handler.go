@@ -0,0 +1,162 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+type deletionRequest struct {
+	Settlement string `json:"balance_settlement"`
+}
+
+func RequestDeletionHandler(logger *zap.Logger, service accountService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodDelete {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var deletionRequest deletionRequest
+		if err := json.NewDecoder(request.Body).Decode(&deletionRequest); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		deletion, err := service.RequestDeletion(request.Context(), deletionRequest.Settlement)
+		if err != nil {
+			if errors.Is(err, model.ErrBalanceSettlementIsNotValid) {
+				http.Error(writer, "Balance settlement is not valid", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrAccountDeletionIsAlreadyRequested) {
+				http.Error(writer, "Account deletion is already requested", http.StatusConflict)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(&deletion)
+		if err != nil {
+			logger.Error("Error during marshal account deletion.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusAccepted)
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write account deletion.", zap.Error(err))
+		}
+	}
+}
+
+func CancelDeletionHandler(logger *zap.Logger, service accountService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		err := service.CancelDeletion(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrAccountDeletionWasNotFound) {
+				http.Error(writer, "Account deletion was not requested", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+func DataExportHandler(logger *zap.Logger, service accountService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		data, err := service.ExportData(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrUserWasNotFound) {
+				http.Error(writer, "User was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		archive, err := buildArchive(data)
+		if err != nil {
+			logger.Error("Error during build data export archive.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/zip")
+		writer.Header().Set("Content-Disposition", `attachment; filename="data-export.zip"`)
+		if _, err = writer.Write(archive); err != nil {
+			logger.Error("Error write data export.", zap.Error(err))
+		}
+	}
+}
+
+func buildArchive(data model.UserData) ([]byte, error) {
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"user.json", data.Profile},
+		{"balance.json", struct {
+			Current  float64 `json:"current"`
+			Reserved float64 `json:"reserved"`
+		}{data.Balance.Balance, data.Balance.Reserved}},
+		{"orders.json", nonNil(data.Orders)},
+		{"withdrawals.json", nonNil(data.Withdrawals)},
+		{"holds.json", nonNil(data.Holds)},
+		{"redemptions.json", nonNil(data.Redemptions)},
+		{"statements.json", nonNil(data.Statements)},
+		{"identifiers.json", nonNil(data.Identifiers)},
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = entry.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,231 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockAccountService struct {
+	RequestDeletionFunc func(ctx context.Context, settlement string) (model.AccountDeletion, error)
+	CancelDeletionFunc  func(ctx context.Context) error
+	ExportDataFunc      func(ctx context.Context) (model.UserData, error)
+}
+
+func (m *mockAccountService) RequestDeletion(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+	return m.RequestDeletionFunc(ctx, settlement)
+}
+
+func (m *mockAccountService) CancelDeletion(ctx context.Context) error {
+	return m.CancelDeletionFunc(ctx)
+}
+
+func (m *mockAccountService) ExportData(ctx context.Context) (model.UserData, error) {
+	return m.ExportDataFunc(ctx)
+}
+
+func TestRequestDeletionHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	deleteAfter := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		service        accountService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful request without body",
+			body: "",
+			service: &mockAccountService{
+				RequestDeletionFunc: func(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+					assert.Equal(t, "", settlement)
+					return model.AccountDeletion{Settlement: model.ForfeitBalanceSettlement, DeleteAfter: deleteAfter}, nil
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"delete_after":"2024-09-01T00:00:00Z","balance_settlement":"FORFEIT"}`,
+		},
+		{
+			name: "Successful request with payout",
+			body: `{"balance_settlement":"payout"}`,
+			service: &mockAccountService{
+				RequestDeletionFunc: func(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+					assert.Equal(t, "payout", settlement)
+					return model.AccountDeletion{Settlement: model.PayoutBalanceSettlement, PayoutID: "1", DeleteAfter: deleteAfter}, nil
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"delete_after":"2024-09-01T00:00:00Z","balance_settlement":"PAYOUT","payout_id":"1"}`,
+		},
+		{
+			name:           "Invalid payload",
+			body:           `{`,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid settlement",
+			body: `{"balance_settlement":"donate"}`,
+			service: &mockAccountService{
+				RequestDeletionFunc: func(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+					return model.AccountDeletion{}, model.ErrBalanceSettlementIsNotValid
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Already requested",
+			service: &mockAccountService{
+				RequestDeletionFunc: func(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+					return model.AccountDeletion{}, model.ErrAccountDeletionIsAlreadyRequested
+				},
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/api/user", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := RequestDeletionHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestCancelDeletionHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		service        accountService
+		expectedStatus int
+	}{
+		{
+			name: "Successful restore",
+			service: &mockAccountService{
+				CancelDeletionFunc: func(ctx context.Context) error {
+					return nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Deletion was not requested",
+			service: &mockAccountService{
+				CancelDeletionFunc: func(ctx context.Context) error {
+					return model.ErrAccountDeletionWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/restore", nil)
+			rec := httptest.NewRecorder()
+
+			handler := CancelDeletionHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestDataExportHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("Successful export", func(t *testing.T) {
+		service := &mockAccountService{
+			ExportDataFunc: func(ctx context.Context) (model.UserData, error) {
+				return model.UserData{
+					Profile:     model.UserProfile{ID: "5f0d1a3c-8b7e-4c2d-9a6f-1e2b3c4d5e6f", Username: "testUser"},
+					Balance:     model.Balance{UserID: "testUser", Balance: 100, Reserved: 10},
+					Withdrawals: []model.Withdrawal{{OrderNumber: "12345678903", Sum: 10, CreateDate: time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)}},
+					Identifiers: []model.UserIdentifier{{ID: "1", Type: model.EmailIdentifierType, Value: "user@example.com", Verified: true,
+						CreateDate: time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)}},
+				}, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/user/data-export", nil)
+		rec := httptest.NewRecorder()
+
+		handler := DataExportHandler(logger, service)
+		handler.ServeHTTP(rec, req)
+
+		res := rec.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/zip", res.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.NoError(t, err)
+
+		files := make(map[string]string)
+		for _, file := range archive.File {
+			reader, err := file.Open()
+			assert.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			reader.Close()
+			files[file.Name] = string(content)
+		}
+
+		assert.Equal(t, 8, len(files))
+		assert.JSONEq(t, `{"id":"5f0d1a3c-8b7e-4c2d-9a6f-1e2b3c4d5e6f","login":"testUser"}`, files["user.json"])
+		assert.JSONEq(t, `{"current":100,"reserved":10}`, files["balance.json"])
+		assert.JSONEq(t, `[{"order":"12345678903","sum":10,"processed_at":"2024-08-01T10:00:00Z"}]`, files["withdrawals.json"])
+		assert.JSONEq(t, `[]`, files["orders.json"])
+		assert.JSONEq(t, `[{"id":"1","type":"EMAIL","value":"user@example.com","verified":true,"created_at":"2024-08-01T10:00:00Z"}]`, files["identifiers.json"])
+	})
+
+	t.Run("Internal server error", func(t *testing.T) {
+		service := &mockAccountService{
+			ExportDataFunc: func(ctx context.Context) (model.UserData, error) {
+				return model.UserData{}, errors.New("general error")
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/user/data-export", nil)
+		rec := httptest.NewRecorder()
+
+		handler := DataExportHandler(logger, service)
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Result().StatusCode)
+	})
+}
@@ -6,7 +6,9 @@ import (
 )
 
 type userService interface {
-	CreateUser(ctx context.Context, user model.User) error
+	CreateUser(ctx context.Context, user model.User) (model.User, error)
 
 	FindUser(ctx context.Context, user model.User) (model.User, error)
+
+	ChangeUsername(ctx context.Context, userName string) error
 }
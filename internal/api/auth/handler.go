@@ -40,13 +40,13 @@ func LoginHandler(logger *zap.Logger, service userService) http.HandlerFunc {
 			return
 		}
 
-		token, err := createJWTToken(user.Username)
+		token, err := createJWTToken(foundUser.ID, foundUser.Username)
 		if err != nil {
 			logger.Error("Error during create token", zap.String("username", user.Username), zap.Error(err))
 			http.Error(writer, "Could not create token", http.StatusInternalServerError)
 			return
 		}
-		logger.Debug("Successfully create token", zap.String("username", user.Username), zap.String("token", token))
+		logger.Debug("Successfully create token", zap.String("username", user.Username))
 
 		writer.Header().Set("Authorization", "Bearer "+token)
 		writer.WriteHeader(http.StatusOK)
@@ -67,7 +67,7 @@ func RegisterHandler(logger *zap.Logger, service userService) http.HandlerFunc {
 			return
 		}
 
-		err = service.CreateUser(request.Context(), user)
+		createdUser, err := service.CreateUser(request.Context(), user)
 		if err != nil {
 			if errors.Is(err, model.ErrUserDataIsNotValid) {
 				http.Error(writer, "Invalid request payload", http.StatusBadRequest)
@@ -84,15 +84,57 @@ func RegisterHandler(logger *zap.Logger, service userService) http.HandlerFunc {
 		}
 		logger.Debug("Successfully save user", zap.String("username", user.Username))
 
-		token, err := createJWTToken(user.Username)
+		token, err := createJWTToken(createdUser.ID, createdUser.Username)
 		if err != nil {
 			logger.Error("Error during create token", zap.String("username", user.Username), zap.Error(err))
 			http.Error(writer, "Could not create token", http.StatusInternalServerError)
 			return
 		}
-		logger.Debug("Successfully create token", zap.String("username", user.Username), zap.String("token", token))
+		logger.Debug("Successfully create token", zap.String("username", user.Username))
 
 		writer.Header().Set("Authorization", "Bearer "+token)
 		writer.WriteHeader(http.StatusOK)
 	}
 }
+
+type changeLoginRequest struct {
+	Username string `json:"login"`
+}
+
+func ChangeLoginHandler(logger *zap.Logger, service userService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPut {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var changeLoginRequest changeLoginRequest
+		if err := json.NewDecoder(request.Body).Decode(&changeLoginRequest); err != nil {
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		err := service.ChangeUsername(request.Context(), changeLoginRequest.Username)
+		if err != nil {
+			if errors.Is(err, model.ErrUserDataIsNotValid) {
+				http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrUserAlreadyExists) {
+				http.Error(writer, "Login is already taken", http.StatusConflict)
+				return
+			}
+
+			if errors.Is(err, model.ErrUserWasNotFound) {
+				http.Error(writer, "User was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		logger.Debug("Successfully change login")
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
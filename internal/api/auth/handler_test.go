@@ -14,24 +14,29 @@ import (
 )
 
 type mockUserService struct {
-	FindUserFunc   func(ctx context.Context, user model.User) (model.User, error)
-	CreateUserFunc func(ctx context.Context, user model.User) error
+	FindUserFunc       func(ctx context.Context, user model.User) (model.User, error)
+	CreateUserFunc     func(ctx context.Context, user model.User) (model.User, error)
+	ChangeUsernameFunc func(ctx context.Context, userName string) error
 }
 
 func (m *mockUserService) FindUser(ctx context.Context, user model.User) (model.User, error) {
 	return m.FindUserFunc(ctx, user)
 }
 
-func (m *mockUserService) CreateUser(ctx context.Context, user model.User) error {
+func (m *mockUserService) CreateUser(ctx context.Context, user model.User) (model.User, error) {
 	return m.CreateUserFunc(ctx, user)
 }
 
+func (m *mockUserService) ChangeUsername(ctx context.Context, userName string) error {
+	return m.ChangeUsernameFunc(ctx, userName)
+}
+
 func TestLoginHandler(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	defer logger.Sync()
 
 	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
-	mockUser := model.User{Username: "testUser", Password: string(passwordHash)}
+	mockUser := model.User{ID: "1", Username: "testUser", Password: string(passwordHash)}
 
 	tests := []struct {
 		name           string
@@ -131,8 +136,8 @@ func TestRegisterHandler(t *testing.T) {
 			method: http.MethodPost,
 			body:   `{"login":"testUser", "password":"password"}`,
 			service: &mockUserService{
-				CreateUserFunc: func(ctx context.Context, user model.User) error {
-					return nil
+				CreateUserFunc: func(ctx context.Context, user model.User) (model.User, error) {
+					return model.User{ID: "1", Username: user.Username}, nil
 				},
 			},
 			expectedStatus: http.StatusOK,
@@ -149,8 +154,8 @@ func TestRegisterHandler(t *testing.T) {
 			method: http.MethodPost,
 			body:   `{"login":"", "password":""}`,
 			service: &mockUserService{
-				CreateUserFunc: func(ctx context.Context, user model.User) error {
-					return model.ErrUserDataIsNotValid
+				CreateUserFunc: func(ctx context.Context, user model.User) (model.User, error) {
+					return model.User{}, model.ErrUserDataIsNotValid
 				},
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -160,8 +165,8 @@ func TestRegisterHandler(t *testing.T) {
 			method: http.MethodPost,
 			body:   `{"login":"existingUser", "password":"password"}`,
 			service: &mockUserService{
-				CreateUserFunc: func(ctx context.Context, user model.User) error {
-					return model.ErrUserAlreadyExists
+				CreateUserFunc: func(ctx context.Context, user model.User) (model.User, error) {
+					return model.User{}, model.ErrUserAlreadyExists
 				},
 			},
 			expectedStatus: http.StatusConflict,
@@ -189,3 +194,72 @@ func TestRegisterHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestChangeLoginHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	defer logger.Sync()
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		service        userService
+		expectedStatus int
+	}{
+		{
+			name:   "Successful change",
+			method: http.MethodPut,
+			body:   `{"login":"renamed"}`,
+			service: &mockUserService{
+				ChangeUsernameFunc: func(ctx context.Context, userName string) error {
+					assert.Equal(t, "renamed", userName)
+					return nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid method",
+			method:         http.MethodGet,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Invalid login",
+			method: http.MethodPut,
+			body:   `{"login":""}`,
+			service: &mockUserService{
+				ChangeUsernameFunc: func(ctx context.Context, userName string) error {
+					return model.ErrUserDataIsNotValid
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Login is taken",
+			method: http.MethodPut,
+			body:   `{"login":"existingUser"}`,
+			service: &mockUserService{
+				ChangeUsernameFunc: func(ctx context.Context, userName string) error {
+					return model.ErrUserAlreadyExists
+				},
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/user/login", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := ChangeLoginHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
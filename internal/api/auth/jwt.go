@@ -8,9 +8,10 @@ import (
 
 var JwtKey = []byte("hard_coded_jwt_secret_key")
 
-func createJWTToken(username string) (string, error) {
+func createJWTToken(userID string, username string) (string, error) {
 	expirationTime := time.Now().Add(5 * time.Minute)
 	claims := &model.Claims{
+		UserID:   userID,
 		Username: username,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
@@ -10,8 +10,9 @@ import (
 
 func TestCreateJWTToken(t *testing.T) {
 	t.Run("should create a valid JWT token", func(t *testing.T) {
+		userID := "e3b0c442-98fc-4c14-9afb-f4c8996fb924"
 		username := "testUser"
-		tokenString, err := createJWTToken(username)
+		tokenString, err := createJWTToken(userID, username)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, tokenString)
 
@@ -23,6 +24,7 @@ func TestCreateJWTToken(t *testing.T) {
 
 		claims, ok := token.Claims.(*model.Claims)
 		assert.True(t, ok)
+		assert.Equal(t, userID, claims.UserID)
 		assert.Equal(t, username, claims.Username)
 
 		expectedExpiration := time.Now().Add(5 * time.Minute).Truncate(time.Second)
@@ -9,4 +9,10 @@ type balanceService interface {
 	FindBalanceStats(ctx context.Context) (model.BalanceStats, error)
 
 	Withdraw(ctx context.Context, orderNumber string, sum float64) error
+
+	Authorize(ctx context.Context, orderNumber string, sum float64) (model.Hold, error)
+
+	Capture(ctx context.Context, holdID string) error
+
+	Void(ctx context.Context, holdID string) error
 }
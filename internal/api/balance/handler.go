@@ -1,10 +1,12 @@
 package balance
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
 )
@@ -87,3 +89,96 @@ func WithdrawBalanceHandler(logger *zap.Logger, service balanceService) http.Han
 		writer.WriteHeader(http.StatusOK)
 	}
 }
+
+func AuthorizeHandler(logger *zap.Logger, service balanceService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var req struct {
+			OrderNumber string  `json:"order"`
+			Sum         float64 `json:"sum"`
+		}
+
+		err := json.NewDecoder(request.Body).Decode(&req)
+		if err != nil {
+			logger.Error("Invalid request payload", zap.Error(err))
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		hold, err := service.Authorize(request.Context(), req.OrderNumber, req.Sum)
+		if err != nil {
+			if errors.Is(err, model.ErrOrderNumberOrSumIsNotFilled) {
+				http.Error(writer, "Order number or sum is not filled", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderNumberIsNotValid) {
+				http.Error(writer, "Order number is not valid", http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, model.ErrUserBalanceLessThanSumToWithdraw) {
+				http.Error(writer, "Balance less than sum to withdrawal", http.StatusPaymentRequired)
+				return
+			}
+
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(&hold)
+		if err != nil {
+			logger.Error("Error during marshal hold.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write hold.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func CaptureHoldHandler(logger *zap.Logger, service balanceService) http.HandlerFunc {
+	return holdActionHandler(logger, service.Capture)
+}
+
+func VoidHoldHandler(logger *zap.Logger, service balanceService) http.HandlerFunc {
+	return holdActionHandler(logger, service.Void)
+}
+
+func holdActionHandler(logger *zap.Logger, action func(ctx context.Context, holdID string) error) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		holdID := chi.URLParam(request, "id")
+		err := action(request.Context(), holdID)
+		if err != nil {
+			if errors.Is(err, model.ErrHoldWasNotFound) {
+				http.Error(writer, "Hold was not found", http.StatusNotFound)
+				return
+			}
+
+			if errors.Is(err, model.ErrHoldIsNotAuthorized) {
+				http.Error(writer, "Hold was already captured, voided or expired", http.StatusConflict)
+				return
+			}
+
+			logger.Error("Error during process hold", zap.String("holdID", holdID), zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
@@ -4,19 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/desepticon55/gofemart/internal/service"
+	balance2 "github.com/desepticon55/gofemart/internal/service/balance"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type mockBalanceService struct {
 	FindBalanceStatsFunc func(ctx context.Context) (model.BalanceStats, error)
 	WithdrawFunc         func(ctx context.Context, orderNumber string, sum float64) error
+	AuthorizeFunc        func(ctx context.Context, orderNumber string, sum float64) (model.Hold, error)
+	CaptureFunc          func(ctx context.Context, holdID string) error
+	VoidFunc             func(ctx context.Context, holdID string) error
 }
 
 func (m *mockBalanceService) FindBalanceStats(ctx context.Context) (model.BalanceStats, error) {
@@ -27,6 +35,18 @@ func (m *mockBalanceService) Withdraw(ctx context.Context, orderNumber string, s
 	return m.WithdrawFunc(ctx, orderNumber, sum)
 }
 
+func (m *mockBalanceService) Authorize(ctx context.Context, orderNumber string, sum float64) (model.Hold, error) {
+	return m.AuthorizeFunc(ctx, orderNumber, sum)
+}
+
+func (m *mockBalanceService) Capture(ctx context.Context, holdID string) error {
+	return m.CaptureFunc(ctx, holdID)
+}
+
+func (m *mockBalanceService) Void(ctx context.Context, holdID string) error {
+	return m.VoidFunc(ctx, holdID)
+}
+
 func TestFindUserBalanceHandler(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	defer logger.Sync()
@@ -44,7 +64,7 @@ func TestFindUserBalanceHandler(t *testing.T) {
 			service: &mockBalanceService{
 				FindBalanceStatsFunc: func(ctx context.Context) (model.BalanceStats, error) {
 					return model.BalanceStats{
-						Username:  "testUser",
+						UserID:    "testUser",
 						Balance:   1000.0,
 						Withdrawn: 200.0,
 					}, nil
@@ -75,7 +95,7 @@ func TestFindUserBalanceHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+			ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 			req := httptest.NewRequest(tt.method, "/balance", nil).WithContext(ctx)
 			rec := httptest.NewRecorder()
 
@@ -204,3 +224,144 @@ func TestWithdrawBalanceHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizeHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	defer logger.Sync()
+
+	createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		service        balanceService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Successful authorize",
+			method: http.MethodPost,
+			body:   `{"order":"12345678903","sum":200}`,
+			service: &mockBalanceService{
+				AuthorizeFunc: func(ctx context.Context, orderNumber string, sum float64) (model.Hold, error) {
+					return model.Hold{
+						ID:          "1",
+						OrderNumber: orderNumber,
+						Sum:         sum,
+						Status:      model.AuthorizedHoldStatus,
+						CreateDate:  createDate,
+						ExpireDate:  createDate.Add(15 * time.Minute),
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"1","order":"12345678903","sum":200,"status":"AUTHORIZED","created_at":"2024-08-01T10:00:00Z","expires_at":"2024-08-01T10:15:00Z"}`,
+		},
+		{
+			name:           "Invalid method",
+			method:         http.MethodGet,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Insufficient balance",
+			method: http.MethodPost,
+			body:   `{"order":"12345678903","sum":2000}`,
+			service: &mockBalanceService{
+				AuthorizeFunc: func(ctx context.Context, orderNumber string, sum float64) (model.Hold, error) {
+					return model.Hold{}, model.ErrUserBalanceLessThanSumToWithdraw
+				},
+			},
+			expectedStatus: http.StatusPaymentRequired,
+		},
+		{
+			name:   "Order number not valid",
+			method: http.MethodPost,
+			body:   `{"order":"invalid","sum":200}`,
+			service: &mockBalanceService{
+				AuthorizeFunc: func(ctx context.Context, orderNumber string, sum float64) (model.Hold, error) {
+					return model.Hold{}, model.ErrOrderNumberIsNotValid
+				},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/holds", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := AuthorizeHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestCaptureHoldHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	defer logger.Sync()
+
+	tests := []struct {
+		name           string
+		captureErr     error
+		expectedStatus int
+	}{
+		{name: "Successful capture", captureErr: nil, expectedStatus: http.StatusOK},
+		{name: "Hold not found", captureErr: model.ErrHoldWasNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Hold already voided", captureErr: model.ErrHoldIsNotAuthorized, expectedStatus: http.StatusConflict},
+		{name: "General error", captureErr: errors.New("general error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", "1")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeContext)
+			req := httptest.NewRequest(http.MethodPost, "/holds/1/capture", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			handler := CaptureHoldHandler(logger, &mockBalanceService{
+				CaptureFunc: func(ctx context.Context, holdID string) error {
+					assert.Equal(t, "1", holdID)
+					return tt.captureErr
+				},
+			})
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestCaptureHoldHandler_InvalidHoldID(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	defer logger.Sync()
+
+	router := chi.NewRouter()
+	router.Method(http.MethodPost, "/holds/{id}/capture", CaptureHoldHandler(logger, balance2.NewBalanceService(logger, nil, metrics.NewNoopMetrics())))
+
+	req := httptest.NewRequest(http.MethodPost, "/holds/not-a-uuid/capture", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -1,3 +1,7 @@
 package api
 
-const ()
+import "github.com/desepticon55/gofemart/internal/service"
+
+const (
+	MaxOrderBatchBodySize = int64(service.MaxOrderBatchSize) * 64
+)
@@ -0,0 +1,11 @@
+package export
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"time"
+)
+
+type exportService interface {
+	ExportTransactions(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/api"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	csvFormat    = "csv"
+	jsonFormat   = "json"
+	ndjsonFormat = "ndjson"
+
+	flushEvery = 100
+)
+
+var formatContentTypes = map[string]string{
+	csvFormat:    "text/csv",
+	jsonFormat:   "application/json",
+	ndjsonFormat: "application/x-ndjson",
+}
+
+var csvHeader = []string{"type", "order", "status", "amount", "processed_at"}
+
+func ExportTransactionsHandler(logger *zap.Logger, service exportService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		format, ok := negotiateFormat(request)
+		if !ok {
+			http.Error(writer, "Unsupported export format", http.StatusNotAcceptable)
+			return
+		}
+
+		from, to, err := api.ParseDateRange(request)
+		if err != nil {
+			http.Error(writer, "Invalid export period", http.StatusBadRequest)
+			return
+		}
+
+		encoder := newTransactionEncoder(writer, format)
+		err = service.ExportTransactions(request.Context(), from, to, encoder.encode)
+		if err == nil {
+			err = encoder.close()
+		}
+		if err != nil {
+			if encoder.started {
+				logger.Error("Error during stream export, response is truncated", zap.Error(err))
+				return
+			}
+			if errors.Is(err, model.ErrExportRequestIsNotValid) {
+				http.Error(writer, "Invalid export period", http.StatusBadRequest)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
+func negotiateFormat(request *http.Request) (string, bool) {
+	if format := strings.ToLower(request.URL.Query().Get("format")); format != "" {
+		_, ok := formatContentTypes[format]
+		return format, ok
+	}
+
+	accept := request.Header.Get("Accept")
+	if accept == "" {
+		return jsonFormat, true
+	}
+
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "*/*", "application/*":
+			return jsonFormat, true
+		case "text/*":
+			return csvFormat, true
+		}
+		for format, contentType := range formatContentTypes {
+			if mediaType == contentType {
+				return format, true
+			}
+		}
+	}
+	return "", false
+}
+
+type transactionEncoder struct {
+	writer  http.ResponseWriter
+	format  string
+	csv     *csv.Writer
+	started bool
+	count   int
+}
+
+func newTransactionEncoder(writer http.ResponseWriter, format string) *transactionEncoder {
+	return &transactionEncoder{writer: writer, format: format}
+}
+
+func (e *transactionEncoder) start() error {
+	e.started = true
+	e.writer.Header().Set("Content-Type", formatContentTypes[e.format])
+	e.writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="transactions.%s"`, e.format))
+	e.writer.WriteHeader(http.StatusOK)
+
+	switch e.format {
+	case csvFormat:
+		e.csv = csv.NewWriter(e.writer)
+		return e.csv.Write(csvHeader)
+	case jsonFormat:
+		_, err := io.WriteString(e.writer, "[")
+		return err
+	}
+	return nil
+}
+
+func (e *transactionEncoder) encode(transaction model.Transaction) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch e.format {
+	case csvFormat:
+		err = e.csv.Write([]string{
+			transaction.Type,
+			transaction.OrderNumber,
+			transaction.Status,
+			strconv.FormatFloat(transaction.Amount, 'f', -1, 64),
+			transaction.CreateDate.Format(time.RFC3339),
+		})
+	case jsonFormat:
+		if e.count > 0 {
+			if _, err = io.WriteString(e.writer, ","); err != nil {
+				return err
+			}
+		}
+		err = e.writeJSON(transaction)
+	case ndjsonFormat:
+		if err = e.writeJSON(transaction); err == nil {
+			_, err = io.WriteString(e.writer, "\n")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	e.count++
+	if e.count%flushEvery == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *transactionEncoder) close() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if e.format == jsonFormat {
+		if _, err := io.WriteString(e.writer, "]"); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+func (e *transactionEncoder) writeJSON(transaction model.Transaction) error {
+	bytes, err := json.Marshal(&transaction)
+	if err != nil {
+		return err
+	}
+	_, err = e.writer.Write(bytes)
+	return err
+}
+
+func (e *transactionEncoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := e.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
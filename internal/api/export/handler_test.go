@@ -0,0 +1,156 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockExportService struct {
+	ExportTransactionsFunc func(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error
+}
+
+func (m *mockExportService) ExportTransactions(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+	return m.ExportTransactionsFunc(ctx, from, to, consumer)
+}
+
+func TestExportTransactionsHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{Type: model.OrderTransactionType, OrderNumber: "12345678903", Status: model.NewOrderStatus, CreateDate: createDate},
+		{Type: model.AccrualTransactionType, OrderNumber: "12345678903", Status: model.ProcessedOrderStatus, Amount: 500.5, CreateDate: createDate},
+		{Type: model.WithdrawalTransactionType, OrderNumber: "79927398713", Amount: 100, CreateDate: createDate},
+	}
+	streamService := &mockExportService{
+		ExportTransactionsFunc: func(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+			for _, transaction := range transactions {
+				if err := consumer(transaction); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name                string
+		url                 string
+		accept              string
+		service             exportService
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "CSV format",
+			url:                 "/api/user/export?format=csv",
+			service:             streamService,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody: "type,order,status,amount,processed_at\n" +
+				"order,12345678903,NEW,0,2024-08-01T10:00:00Z\n" +
+				"accrual,12345678903,PROCESSED,500.5,2024-08-01T10:00:00Z\n" +
+				"withdrawal,79927398713,,100,2024-08-01T10:00:00Z\n",
+		},
+		{
+			name:                "JSON format",
+			url:                 "/api/user/export?format=json",
+			service:             streamService,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: `[{"type":"order","order":"12345678903","status":"NEW","amount":0,"processed_at":"2024-08-01T10:00:00Z"},` +
+				`{"type":"accrual","order":"12345678903","status":"PROCESSED","amount":500.5,"processed_at":"2024-08-01T10:00:00Z"},` +
+				`{"type":"withdrawal","order":"79927398713","amount":100,"processed_at":"2024-08-01T10:00:00Z"}]`,
+		},
+		{
+			name:                "NDJSON format negotiated by Accept header",
+			url:                 "/api/user/export",
+			accept:              "application/x-ndjson",
+			service:             streamService,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody: `{"type":"order","order":"12345678903","status":"NEW","amount":0,"processed_at":"2024-08-01T10:00:00Z"}` + "\n" +
+				`{"type":"accrual","order":"12345678903","status":"PROCESSED","amount":500.5,"processed_at":"2024-08-01T10:00:00Z"}` + "\n" +
+				`{"type":"withdrawal","order":"79927398713","amount":100,"processed_at":"2024-08-01T10:00:00Z"}` + "\n",
+		},
+		{
+			name: "Empty JSON export",
+			url:  "/api/user/export",
+			service: &mockExportService{
+				ExportTransactionsFunc: func(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+					return nil
+				},
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `[]`,
+		},
+		{
+			name:           "Unsupported format",
+			url:            "/api/user/export?format=xml",
+			service:        streamService,
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:           "Invalid period",
+			url:            "/api/user/export?from=yesterday",
+			service:        streamService,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Period is not valid",
+			url:  "/api/user/export?from=2024-08-02&to=2024-08-01",
+			service: &mockExportService{
+				ExportTransactionsFunc: func(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+					assert.Equal(t, time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC), *from)
+					assert.Equal(t, time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC), *to)
+					return model.ErrExportRequestIsNotValid
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Internal server error",
+			url:  "/api/user/export",
+			service: &mockExportService{
+				ExportTransactionsFunc: func(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+					return errors.New("general error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			handler := ExportTransactionsHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedContentType, res.Header.Get("Content-Type"))
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
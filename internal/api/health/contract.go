@@ -0,0 +1,12 @@
+package health
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type healthService interface {
+	Ready(ctx context.Context) model.Readiness
+
+	Status(ctx context.Context) model.SystemStatus
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func LivenessHandler(logger *zap.Logger) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err := writer.Write([]byte(`{"status":"ok"}`)); err != nil {
+			logger.Error("Error write liveness status.", zap.Error(err))
+		}
+	}
+}
+
+func ReadinessHandler(logger *zap.Logger, service healthService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		readiness := service.Ready(request.Context())
+		bytes, err := json.Marshal(readiness)
+		if err != nil {
+			logger.Error("Error during marshal readiness.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if !readiness.Ready {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write readiness.", zap.Error(err))
+		}
+	}
+}
+
+func StatusHandler(logger *zap.Logger, service healthService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		bytes, err := json.Marshal(service.Status(request.Context()))
+		if err != nil {
+			logger.Error("Error during marshal status.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write status.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
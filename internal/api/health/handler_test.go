@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockHealthService struct {
+	ReadyFunc  func(ctx context.Context) model.Readiness
+	StatusFunc func(ctx context.Context) model.SystemStatus
+}
+
+func (m *mockHealthService) Ready(ctx context.Context) model.Readiness {
+	return m.ReadyFunc(ctx)
+}
+
+func (m *mockHealthService) Status(ctx context.Context) model.SystemStatus {
+	return m.StatusFunc(ctx)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LivenessHandler(zaptest.NewLogger(t)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		readiness      model.Readiness
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Ready",
+			readiness:      model.Readiness{Ready: true, Checks: map[string]string{"database": "ok"}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"ready":true,"checks":{"database":"ok"}}`,
+		},
+		{
+			name:           "Not ready",
+			readiness:      model.Readiness{Ready: false, Checks: map[string]string{"shutdown": "in progress"}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"ready":false,"checks":{"shutdown":"in progress"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockHealthService{
+				ReadyFunc: func(ctx context.Context) model.Readiness {
+					return tt.readiness
+				},
+			}
+			rec := httptest.NewRecorder()
+
+			ReadinessHandler(logger, service).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(body))
+		})
+	}
+}
+
+func TestStatusHandler(t *testing.T) {
+	service := &mockHealthService{
+		StatusFunc: func(ctx context.Context) model.SystemStatus {
+			return model.SystemStatus{
+				Readiness: model.Readiness{Ready: true, Checks: map[string]string{"workers": "ok"}},
+				Workers:   []model.WorkerStatus{{Name: "gophermart-1", Running: true, LastError: "timeout"}},
+			}
+		},
+	}
+	rec := httptest.NewRecorder()
+
+	StatusHandler(zaptest.NewLogger(t), service).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/status", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"ready":true,"checks":{"workers":"ok"},
+		"workers":[{"name":"gophermart-1","running":true,"last_error":"timeout"}]}`, rec.Body.String())
+}
@@ -0,0 +1,16 @@
+package identifier
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type identifierService interface {
+	FindIdentifiers(ctx context.Context) ([]model.UserIdentifier, error)
+
+	AddIdentifier(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error)
+
+	VerifyIdentifier(ctx context.Context, identifierID string, code string) (model.UserIdentifier, error)
+
+	DeleteIdentifier(ctx context.Context, identifierID string) error
+}
@@ -0,0 +1,161 @@
+package identifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type addIdentifierRequest struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type verifyIdentifierRequest struct {
+	Code string `json:"code"`
+}
+
+func FindIdentifiersHandler(logger *zap.Logger, service identifierService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		identifiers, err := service.FindIdentifiers(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrIdentifiersWasNotFound) {
+				http.Error(writer, "Identifiers was not found", http.StatusNoContent)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(identifiers)
+		if err != nil {
+			logger.Error("Error during marshal identifiers.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write identifiers.", zap.Error(err))
+		}
+	}
+}
+
+func AddIdentifierHandler(logger *zap.Logger, service identifierService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var addIdentifierRequest addIdentifierRequest
+		if err := json.NewDecoder(request.Body).Decode(&addIdentifierRequest); err != nil {
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		identifier, err := service.AddIdentifier(request.Context(), addIdentifierRequest.Type, addIdentifierRequest.Value)
+		if err != nil {
+			if errors.Is(err, model.ErrIdentifierIsNotValid) {
+				http.Error(writer, "Identifier is not valid", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrIdentifierAlreadyExists) {
+				http.Error(writer, "Identifier is already taken", http.StatusConflict)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeIdentifier(logger, writer, identifier, http.StatusAccepted)
+	}
+}
+
+func VerifyIdentifierHandler(logger *zap.Logger, service identifierService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var verifyIdentifierRequest verifyIdentifierRequest
+		if err := json.NewDecoder(request.Body).Decode(&verifyIdentifierRequest); err != nil {
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		identifier, err := service.VerifyIdentifier(request.Context(), chi.URLParam(request, "id"), verifyIdentifierRequest.Code)
+		if err != nil {
+			if errors.Is(err, model.ErrIdentifierWasNotFound) {
+				http.Error(writer, "Identifier was not found", http.StatusNotFound)
+				return
+			}
+
+			if errors.Is(err, model.ErrVerificationCodeIsNotValid) {
+				http.Error(writer, "Verification code is not valid", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrVerificationCodeIsExpired) {
+				http.Error(writer, "Verification code is expired", http.StatusGone)
+				return
+			}
+
+			if errors.Is(err, model.ErrIdentifierAlreadyExists) {
+				http.Error(writer, "Identifier is already taken", http.StatusConflict)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeIdentifier(logger, writer, identifier, http.StatusOK)
+	}
+}
+
+func DeleteIdentifierHandler(logger *zap.Logger, service identifierService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodDelete {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		err := service.DeleteIdentifier(request.Context(), chi.URLParam(request, "id"))
+		if err != nil {
+			if errors.Is(err, model.ErrIdentifierWasNotFound) {
+				http.Error(writer, "Identifier was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+func writeIdentifier(logger *zap.Logger, writer http.ResponseWriter, identifier model.UserIdentifier, status int) {
+	bytes, err := json.Marshal(&identifier)
+	if err != nil {
+		logger.Error("Error during marshal identifier.", zap.Error(err))
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if _, err = writer.Write(bytes); err != nil {
+		logger.Error("Error write identifier.", zap.Error(err))
+	}
+}
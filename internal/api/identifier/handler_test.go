@@ -0,0 +1,257 @@
+package identifier
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockIdentifierService struct {
+	FindIdentifiersFunc  func(ctx context.Context) ([]model.UserIdentifier, error)
+	AddIdentifierFunc    func(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error)
+	VerifyIdentifierFunc func(ctx context.Context, identifierID string, code string) (model.UserIdentifier, error)
+	DeleteIdentifierFunc func(ctx context.Context, identifierID string) error
+}
+
+func (m *mockIdentifierService) FindIdentifiers(ctx context.Context) ([]model.UserIdentifier, error) {
+	return m.FindIdentifiersFunc(ctx)
+}
+
+func (m *mockIdentifierService) AddIdentifier(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error) {
+	return m.AddIdentifierFunc(ctx, identifierType, value)
+}
+
+func (m *mockIdentifierService) VerifyIdentifier(ctx context.Context, identifierID string, code string) (model.UserIdentifier, error) {
+	return m.VerifyIdentifierFunc(ctx, identifierID, code)
+}
+
+func (m *mockIdentifierService) DeleteIdentifier(ctx context.Context, identifierID string) error {
+	return m.DeleteIdentifierFunc(ctx, identifierID)
+}
+
+func withIdentifierID(request *http.Request, identifierID string) *http.Request {
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", identifierID)
+	return request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routeContext))
+}
+
+func TestFindIdentifiersHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		service        identifierService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful return identifiers",
+			service: &mockIdentifierService{
+				FindIdentifiersFunc: func(ctx context.Context) ([]model.UserIdentifier, error) {
+					return []model.UserIdentifier{{ID: "1", Type: model.EmailIdentifierType, Value: "user@example.com", CreateDate: createDate}}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":"1","type":"EMAIL","value":"user@example.com","verified":false,"created_at":"2024-08-01T10:00:00Z"}]`,
+		},
+		{
+			name: "Identifiers not found",
+			service: &mockIdentifierService{
+				FindIdentifiersFunc: func(ctx context.Context) ([]model.UserIdentifier, error) {
+					return nil, model.ErrIdentifiersWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Internal server error",
+			service: &mockIdentifierService{
+				FindIdentifiersFunc: func(ctx context.Context) ([]model.UserIdentifier, error) {
+					return nil, errors.New("general error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/user/identifiers", nil)
+			rec := httptest.NewRecorder()
+
+			handler := FindIdentifiersHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestAddIdentifierHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		body           string
+		service        identifierService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful add",
+			body: `{"type":"phone","value":"+7 999 123-45-67"}`,
+			service: &mockIdentifierService{
+				AddIdentifierFunc: func(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error) {
+					assert.Equal(t, "phone", identifierType)
+					assert.Equal(t, "+7 999 123-45-67", value)
+					return model.UserIdentifier{ID: "1", Type: model.PhoneIdentifierType, Value: "+79991234567", CreateDate: createDate}, nil
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `{"id":"1","type":"PHONE","value":"+79991234567","verified":false,"created_at":"2024-08-01T10:00:00Z"}`,
+		},
+		{
+			name:           "Invalid payload",
+			body:           `{`,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid identifier",
+			body: `{"type":"email","value":"not email"}`,
+			service: &mockIdentifierService{
+				AddIdentifierFunc: func(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error) {
+					return model.UserIdentifier{}, model.ErrIdentifierIsNotValid
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Identifier is taken",
+			body: `{"type":"email","value":"user@example.com"}`,
+			service: &mockIdentifierService{
+				AddIdentifierFunc: func(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error) {
+					return model.UserIdentifier{}, model.ErrIdentifierAlreadyExists
+				},
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/identifiers", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := AddIdentifierHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestVerifyIdentifierHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "Successful verify", err: nil, expectedStatus: http.StatusOK},
+		{name: "Identifier not found", err: model.ErrIdentifierWasNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Wrong code", err: model.ErrVerificationCodeIsNotValid, expectedStatus: http.StatusBadRequest},
+		{name: "Expired code", err: model.ErrVerificationCodeIsExpired, expectedStatus: http.StatusGone},
+		{name: "Identifier is taken", err: model.ErrIdentifierAlreadyExists, expectedStatus: http.StatusConflict},
+		{name: "Internal server error", err: errors.New("general error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockIdentifierService{
+				VerifyIdentifierFunc: func(ctx context.Context, identifierID string, code string) (model.UserIdentifier, error) {
+					assert.Equal(t, "1", identifierID)
+					assert.Equal(t, "123456", code)
+					return model.UserIdentifier{ID: "1", Verified: true}, tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/identifiers/1/verify", strings.NewReader(`{"code":"123456"}`))
+			rec := httptest.NewRecorder()
+
+			handler := VerifyIdentifierHandler(logger, service)
+			handler.ServeHTTP(rec, withIdentifierID(req, "1"))
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestDeleteIdentifierHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "Successful delete", err: nil, expectedStatus: http.StatusOK},
+		{name: "Identifier not found", err: model.ErrIdentifierWasNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Internal server error", err: errors.New("general error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockIdentifierService{
+				DeleteIdentifierFunc: func(ctx context.Context, identifierID string) error {
+					assert.Equal(t, "1", identifierID)
+					return tt.err
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/identifiers/1", nil)
+			rec := httptest.NewRecorder()
+
+			handler := DeleteIdentifierHandler(logger, service)
+			handler.ServeHTTP(rec, withIdentifierID(req, "1"))
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package intake
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type intakeService interface {
+	ApplyAccrual(ctx context.Context, callback model.AccrualCallback) error
+}
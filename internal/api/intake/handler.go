@@ -0,0 +1,53 @@
+package intake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func AccrualCallbackHandler(logger *zap.Logger, service intakeService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var callback model.AccrualCallback
+		if err := json.NewDecoder(request.Body).Decode(&callback); err != nil {
+			logger.Error("Invalid request payload", zap.Error(err))
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		err := service.ApplyAccrual(request.Context(), callback)
+		if err != nil {
+			if errors.Is(err, model.ErrAccrualStatusIsNotValid) {
+				http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderNumberIsNotValid) {
+				http.Error(writer, "Order number is not valid", http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderWasNotFound) {
+				http.Error(writer, "Order was not found", http.StatusNotFound)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderIsAlreadyProcessed) {
+				http.Error(writer, "Order is already processed", http.StatusConflict)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
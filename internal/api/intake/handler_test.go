@@ -0,0 +1,59 @@
+package intake
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockIntakeService struct {
+	ApplyAccrualFunc func(ctx context.Context, callback model.AccrualCallback) error
+}
+
+func (m *mockIntakeService) ApplyAccrual(ctx context.Context, callback model.AccrualCallback) error {
+	return m.ApplyAccrualFunc(ctx, callback)
+}
+
+func TestAccrualCallbackHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "Successful callback", method: http.MethodPost, body: `{"order":"12345678903","status":"PROCESSED","accrual":500}`, expectedStatus: http.StatusOK},
+		{name: "Invalid method", method: http.MethodGet, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid payload", method: http.MethodPost, body: `{`, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid status", method: http.MethodPost, body: `{"order":"12345678903","status":"COMPLETED"}`, err: model.ErrAccrualStatusIsNotValid, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid order number", method: http.MethodPost, body: `{"order":"12345","status":"PROCESSED"}`, err: model.ErrOrderNumberIsNotValid, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "Order not found", method: http.MethodPost, body: `{"order":"12345678903","status":"PROCESSED"}`, err: model.ErrOrderWasNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Order already processed", method: http.MethodPost, body: `{"order":"12345678903","status":"INVALID"}`, err: model.ErrOrderIsAlreadyProcessed, expectedStatus: http.StatusConflict},
+		{name: "Internal server error", method: http.MethodPost, body: `{"order":"12345678903","status":"PROCESSED"}`, err: errors.New("db error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockIntakeService{
+				ApplyAccrualFunc: func(ctx context.Context, callback model.AccrualCallback) error {
+					return tt.err
+				},
+			}
+			req := httptest.NewRequest(tt.method, "/api/internal/accrual/callback", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			AccrualCallbackHandler(logger, service).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package middleware
+
+import (
+	"context"
+)
+
+type userService interface {
+	IsUserActive(ctx context.Context, userID string) (bool, error)
+}
@@ -1,31 +1,54 @@
 package middleware
 
 import (
+	"bytes"
 	"compress/gzip"
-	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"github.com/desepticon55/gofemart/internal/api/auth"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/golang-jwt/jwt/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func CheckAuthMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
+const (
+	signatureTolerance = 5 * time.Minute
+	maxSignedBodySize  = 1 << 20
+)
+
+func CheckAuthMiddleware(logger *zap.Logger, users userService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 			const bearerPrefix = "Bearer "
 			authHeader := request.Header.Get("Authorization")
 			if authHeader == "" {
-				logger.Error("Authorization header is missing", zap.String("Authorization", authHeader))
+				logger.Error("Authorization header is missing")
 				http.Error(writer, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
 			if !strings.HasPrefix(authHeader, bearerPrefix) {
-				logger.Error("Invalid Authorization header format", zap.String("Authorization", authHeader))
+				logger.Error("Invalid Authorization header format")
 				http.Error(writer, "Invalid token", http.StatusUnauthorized)
 				return
 			}
@@ -37,23 +60,185 @@ func CheckAuthMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
 			})
 
 			if err != nil {
-				logger.Error("Error during parse token", zap.String("Authorization", authHeader), zap.Error(err))
+				logger.Error("Error during parse token", zap.Error(err))
 				http.Error(writer, "Expired token", http.StatusUnauthorized)
 				return
 			}
 
-			if !token.Valid {
-				logger.Error("Invalid token", zap.String("Authorization", authHeader))
+			if !token.Valid || claims.UserID == "" {
+				logger.Error("Invalid token")
 				http.Error(writer, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			ctx := context.WithValue(request.Context(), service.UserNameContextKey, claims.Username)
+			active, err := users.IsUserActive(request.Context(), claims.UserID)
+			if err != nil {
+				http.Error(writer, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if !active {
+				logger.Error("User of token is deleted", zap.String("userID", claims.UserID))
+				http.Error(writer, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := service.WithPrincipal(request.Context(), service.Principal{UserID: claims.UserID, Username: claims.Username})
+			ctx = logging.WithLogger(ctx, logging.FromContext(ctx, logger).With(zap.String("userID", claims.UserID)))
+			logging.AddRequestFields(ctx, zap.String("userID", claims.UserID))
 			next.ServeHTTP(writer, request.WithContext(ctx))
 		})
 	}
 }
 
+func CheckAdminMiddleware(logger *zap.Logger, adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if adminToken == "" {
+				logger.Error("Admin API is disabled because admin token is not configured")
+				http.Error(writer, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			token := request.Header.Get("X-Admin-Token")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+				logger.Error("Invalid admin token")
+				http.Error(writer, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func CheckSignatureMiddleware(logger *zap.Logger, secret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if secret == "" {
+				logger.Error("Signed API is disabled because signature secret is not configured")
+				http.Error(writer, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			// replays within signatureTolerance are not rejected, applying the same accrual result again changes nothing
+			timestamp := request.Header.Get("X-Signature-Timestamp")
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil || time.Since(time.Unix(seconds, 0)).Abs() > signatureTolerance {
+				logger.Error("Invalid signature timestamp", zap.String("timestamp", timestamp))
+				http.Error(writer, "Invalid signature", http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxSignedBodySize))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(writer, "Request body is too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				logger.Error("Error during read request body", zap.Error(err))
+				http.Error(writer, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			signature := request.Header.Get("X-Signature")
+			if !hmac.Equal([]byte(signature), []byte(Signature(secret, timestamp, body))) {
+				logger.Error("Invalid signature")
+				http.Error(writer, "Invalid signature", http.StatusUnauthorized)
+				return
+			}
+
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func Signature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func AccessLogMiddleware(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			requestLogger := logger.With(zap.String("requestID", middleware.GetReqID(request.Context())))
+			ctx, fields := logging.WithRequestFields(logging.WithLogger(request.Context(), requestLogger))
+			wrappedWriter := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+			next.ServeHTTP(wrappedWriter, request.WithContext(ctx))
+
+			status := wrappedWriter.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logging.FromContext(ctx, requestLogger).With(fields.Fields()...).Info("Request served",
+				zap.String("method", request.Method),
+				zap.String("path", request.URL.Path),
+				zap.Int("status", status),
+				zap.Int("bytes", wrappedWriter.BytesWritten()),
+				zap.String("remoteAddr", request.RemoteAddr),
+				zap.Duration("duration", time.Since(start)))
+		})
+	}
+}
+
+func MetricsMiddleware(metrics metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+			wrappedWriter := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+			next.ServeHTTP(wrappedWriter, request)
+
+			route := "unmatched"
+			if routeContext := chi.RouteContext(request.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+				route = routeContext.RoutePattern()
+			}
+
+			status := wrappedWriter.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			metrics.ObserveHTTPRequest(route, request.Method, status, time.Since(start))
+		})
+	}
+}
+
+func TracingMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			ctx, span := tracing.Tracer().Start(ctx, request.Method, trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPRequestMethodKey.String(request.Method), semconv.URLPath(request.URL.Path)))
+			defer span.End()
+
+			if requestID := middleware.GetReqID(ctx); requestID != "" {
+				span.SetAttributes(attribute.String("request_id", requestID))
+			}
+
+			wrappedWriter := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+			next.ServeHTTP(wrappedWriter, request.WithContext(ctx))
+
+			if routeContext := chi.RouteContext(request.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+				span.SetName(request.Method + " " + routeContext.RoutePattern())
+				span.SetAttributes(semconv.HTTPRoute(routeContext.RoutePattern()))
+			}
+
+			status := wrappedWriter.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
+
 func DecompressingMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -79,7 +264,7 @@ func CompressingMiddleware() func(http.Handler) http.Handler {
 				writer.Header().Set("Content-Encoding", "gzip")
 				gzipWriter := gzip.NewWriter(writer)
 				defer gzipWriter.Close()
-				gzipResponseWriter := &gzipResponseWriter{gzipWriter, writer}
+				gzipResponseWriter := &gzipResponseWriter{gzipWriter, writer, gzipWriter}
 				next.ServeHTTP(gzipResponseWriter, request)
 			} else {
 				next.ServeHTTP(writer, request)
@@ -91,8 +276,18 @@ func CompressingMiddleware() func(http.Handler) http.Handler {
 type gzipResponseWriter struct {
 	io.Writer
 	http.ResponseWriter
+	gzipWriter *gzip.Writer
 }
 
 func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
+
+func (w *gzipResponseWriter) Flush() {
+	if err := w.gzipWriter.Flush(); err != nil {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/api/auth"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type httpObservation struct {
+	route  string
+	method string
+	status int
+}
+
+type recordingMetrics struct {
+	*metrics.NoopMetrics
+	observations []httpObservation
+}
+
+func (m *recordingMetrics) ObserveHTTPRequest(route string, method string, status int, duration time.Duration) {
+	m.observations = append(m.observations, httpObservation{route: route, method: method, status: status})
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	recorder := &recordingMetrics{NoopMetrics: metrics.NewNoopMetrics()}
+	router := chi.NewRouter()
+	router.Use(MetricsMiddleware(recorder))
+	router.Get("/api/user/orders/{number}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	router.Get("/api/user/balance", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("{}"))
+	})
+
+	for _, path := range []string{"/api/user/orders/12345678903", "/api/user/balance", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []httpObservation{
+		{route: "/api/user/orders/{number}", method: http.MethodGet, status: http.StatusNotFound},
+		{route: "/api/user/balance", method: http.MethodGet, status: http.StatusOK},
+		{route: "unmatched", method: http.MethodGet, status: http.StatusNotFound},
+	}, recorder.observations)
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(TracingMiddleware())
+	router.Get("/api/user/orders/{number}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/user/orders/12345678903", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, "GET /api/user/orders/{number}", spans[0].Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range spans[0].Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.NotEmpty(t, attributes["request_id"].AsString())
+	assert.Equal(t, int64(http.StatusInternalServerError), attributes["http.response.status_code"].AsInt64())
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(logging.NewRedactingCore(core))
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(AccessLogMiddleware(logger))
+	router.Get("/api/user/orders/{number}", func(writer http.ResponseWriter, request *http.Request) {
+		logging.FromContext(request.Context(), zap.NewNop()).Info("Order was not found")
+		logging.AddRequestFields(request.Context(), zap.String("userID", "user-1"))
+		writer.WriteHeader(http.StatusNotFound)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/user/orders/12345678903", nil))
+
+	entries := logs.All()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "Order was not found", entries[0].Message)
+	assert.NotEmpty(t, entries[0].ContextMap()["requestID"])
+	assert.Equal(t, "/api/user/orders/{number}", entries[0].ContextMap()["route"])
+
+	accessLog := entries[1].ContextMap()
+	assert.Equal(t, "Request served", entries[1].Message)
+	assert.Equal(t, entries[0].ContextMap()["requestID"], accessLog["requestID"])
+	assert.Equal(t, "/api/user/orders/*******8903", accessLog["path"])
+	assert.Equal(t, int64(http.StatusNotFound), accessLog["status"])
+	assert.Equal(t, "/api/user/orders/{number}", accessLog["route"])
+	assert.Equal(t, "user-1", accessLog["userID"])
+}
+
+func TestCheckSignatureMiddleware(t *testing.T) {
+	const secret = "callback-secret"
+	body := `{"order":"12345678903","status":"PROCESSED","accrual":500}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name           string
+		secret         string
+		timestamp      string
+		signature      string
+		expectedStatus int
+	}{
+		{name: "Valid signature", secret: secret, timestamp: now, signature: Signature(secret, now, []byte(body)), expectedStatus: http.StatusOK},
+		{name: "Invalid signature", secret: secret, timestamp: now, signature: Signature("other", now, []byte(body)), expectedStatus: http.StatusUnauthorized},
+		{name: "Stale timestamp", secret: secret, timestamp: stale, signature: Signature(secret, stale, []byte(body)), expectedStatus: http.StatusUnauthorized},
+		{name: "Missing timestamp", secret: secret, signature: Signature(secret, "", []byte(body)), expectedStatus: http.StatusUnauthorized},
+		{name: "Secret is not configured", timestamp: now, signature: Signature("", now, []byte(body)), expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CheckSignatureMiddleware(zap.NewNop(), tt.secret)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				received, err := io.ReadAll(request.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, body, string(received))
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/api/internal/accrual/callback", strings.NewReader(body))
+			req.Header.Set("X-Signature-Timestamp", tt.timestamp)
+			req.Header.Set("X-Signature", tt.signature)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+
+	t.Run("Body is too large", func(t *testing.T) {
+		large := strings.Repeat("a", maxSignedBodySize+1)
+		handler := CheckSignatureMiddleware(zap.NewNop(), secret)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			t.Fatal("oversized request should not reach the handler")
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/api/internal/accrual/callback", strings.NewReader(large))
+		req.Header.Set("X-Signature-Timestamp", now)
+		req.Header.Set("X-Signature", Signature(secret, now, []byte(large)))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
+
+type staticUserService map[string]bool
+
+func (s staticUserService) IsUserActive(ctx context.Context, userID string) (bool, error) {
+	return s[userID], nil
+}
+
+func TestCheckAuthMiddleware(t *testing.T) {
+	users := staticUserService{"active": true, "deleted": false}
+	handler := CheckAuthMiddleware(zap.NewNop(), users)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+
+	for userID, expectedStatus := range map[string]int{"active": http.StatusOK, "deleted": http.StatusUnauthorized} {
+		t.Run(userID, func(t *testing.T) {
+			claims := &model.Claims{UserID: userID, Username: userID,
+				RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(auth.JwtKey)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, expectedStatus, rec.Code)
+		})
+	}
+}
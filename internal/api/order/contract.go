@@ -8,5 +8,15 @@ import (
 type orderService interface {
 	UploadOrder(ctx context.Context, orderNumber string) error
 
+	UploadOrders(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error)
+
 	FindAllOrders(ctx context.Context) ([]model.Order, error)
+
+	FindOrdersPage(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error)
+
+	FindOrder(ctx context.Context, orderNumber string) (model.OrderWithHistory, error)
+
+	FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error)
+
+	ReplayOrder(ctx context.Context, orderNumber string) error
 }
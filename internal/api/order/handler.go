@@ -1,15 +1,21 @@
 package order
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/desepticon55/gofemart/internal/api"
 	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"strings"
 )
 
+var orderStatuses = []string{model.NewOrderStatus, model.ProcessingOrderStatus, model.InvalidOrderStatus, model.ProcessedOrderStatus}
+
 func UploadOrderHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		if request.Method != http.MethodPost {
@@ -25,8 +31,6 @@ func UploadOrderHandler(logger *zap.Logger, service orderService) http.HandlerFu
 		}
 		defer request.Body.Close()
 
-		logger.Debug(fmt.Sprintf("Received body = %s", body))
-
 		err = service.UploadOrder(request.Context(), string(body))
 		if err != nil {
 			if errors.Is(err, model.ErrOrderNumberIsNotFilled) {
@@ -57,6 +61,101 @@ func UploadOrderHandler(logger *zap.Logger, service orderService) http.HandlerFu
 	}
 }
 
+func UploadOrdersBatchHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		request.Body = http.MaxBytesReader(writer, request.Body, api.MaxOrderBatchBodySize)
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(writer, "Order batch is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			logger.Error("Error during read body", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer request.Body.Close()
+
+		orderNumbers, err := parseOrderNumbers(request.Header.Get("Content-Type"), body)
+		if err != nil {
+			logger.Error("Invalid request payload", zap.Error(err))
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		results, err := service.UploadOrders(request.Context(), orderNumbers)
+		if err != nil {
+			if errors.Is(err, model.ErrOrderNumberIsNotFilled) {
+				http.Error(writer, "Order numbers are not filled", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderBatchIsTooLarge) {
+				http.Error(writer, "Order batch is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(results)
+		if err != nil {
+			logger.Error("Error during marshal upload results.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write upload results.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func parseOrderNumbers(contentType string, body []byte) ([]string, error) {
+	trimmed := bytes.TrimSpace(body)
+	if strings.Contains(contentType, "application/json") || bytes.HasPrefix(trimmed, []byte("[")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+
+		var values []any
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+
+		orderNumbers := make([]string, 0, len(values))
+		for _, value := range values {
+			switch v := value.(type) {
+			case string:
+				orderNumbers = append(orderNumbers, strings.TrimSpace(v))
+			case json.Number:
+				orderNumbers = append(orderNumbers, v.String())
+			default:
+				orderNumbers = append(orderNumbers, fmt.Sprintf("%v", v))
+			}
+		}
+		return orderNumbers, nil
+	}
+
+	var orderNumbers []string
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			orderNumbers = append(orderNumbers, line)
+		}
+	}
+	return orderNumbers, nil
+}
+
 func FindAllOrdersHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		if request.Method != http.MethodGet {
@@ -64,7 +163,21 @@ func FindAllOrdersHandler(logger *zap.Logger, service orderService) http.Handler
 			return
 		}
 
-		orders, err := service.FindAllOrders(request.Context())
+		page, paginated, err := api.ParsePageRequest(request, orderStatuses)
+		if err != nil {
+			http.Error(writer, "Invalid pagination parameters", http.StatusBadRequest)
+			return
+		}
+
+		var orders []model.Order
+		if paginated {
+			var result model.Page[model.Order]
+			result, err = service.FindOrdersPage(request.Context(), page)
+			orders = result.Items
+			api.SetNextPageHeaders(writer, request, result.NextCursor)
+		} else {
+			orders, err = service.FindAllOrders(request.Context())
+		}
 		if err != nil {
 			if errors.Is(err, model.ErrOrdersWasNotFound) {
 				http.Error(writer, "Orders was not found", http.StatusNoContent)
@@ -90,3 +203,100 @@ func FindAllOrdersHandler(logger *zap.Logger, service orderService) http.Handler
 		writer.WriteHeader(http.StatusOK)
 	}
 }
+
+func FindOrderHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		order, err := service.FindOrder(request.Context(), chi.URLParam(request, "number"))
+		if err != nil {
+			if errors.Is(err, model.ErrOrderNumberIsNotValid) {
+				http.Error(writer, "Order number is not valid", http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, model.ErrOrderWasNotFound) {
+				http.Error(writer, "Order was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(&order)
+		if err != nil {
+			logger.Error("Error during marshal order.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write order.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func FindDeadLetterOrdersHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		orders, err := service.FindDeadLetterOrders(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrDeadLetterOrdersWasNotFound) {
+				http.Error(writer, "Dead-lettered orders was not found", http.StatusNoContent)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(orders)
+		if err != nil {
+			logger.Error("Error during marshal dead-lettered orders.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write dead-lettered orders.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func ReplayOrderHandler(logger *zap.Logger, service orderService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		err := service.ReplayOrder(request.Context(), chi.URLParam(request, "number"))
+		if err != nil {
+			if errors.Is(err, model.ErrOrderNumberIsNotValid) {
+				http.Error(writer, "Order number is not valid", http.StatusUnprocessableEntity)
+				return
+			}
+
+			if errors.Is(err, model.ErrDeadLetterOrderWasNotFound) {
+				http.Error(writer, "Dead-lettered order was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -3,7 +3,9 @@ package order
 import (
 	"context"
 	"errors"
+	"github.com/desepticon55/gofemart/internal/api"
 	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"io"
@@ -11,11 +13,17 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 type mockOrderService struct {
-	UploadOrderFunc   func(ctx context.Context, order string) error
-	FindAllOrdersFunc func(ctx context.Context) ([]model.Order, error)
+	UploadOrderFunc          func(ctx context.Context, order string) error
+	UploadOrdersFunc         func(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error)
+	FindAllOrdersFunc        func(ctx context.Context) ([]model.Order, error)
+	FindOrdersPageFunc       func(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error)
+	FindOrderFunc            func(ctx context.Context, orderNumber string) (model.OrderWithHistory, error)
+	FindDeadLetterOrdersFunc func(ctx context.Context) ([]model.DeadLetterOrder, error)
+	ReplayOrderFunc          func(ctx context.Context, orderNumber string) error
 }
 
 func (m *mockOrderService) UploadOrder(ctx context.Context, order string) error {
@@ -26,6 +34,26 @@ func (m *mockOrderService) FindAllOrders(ctx context.Context) ([]model.Order, er
 	return m.FindAllOrdersFunc(ctx)
 }
 
+func (m *mockOrderService) FindOrdersPage(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error) {
+	return m.FindOrdersPageFunc(ctx, page)
+}
+
+func (m *mockOrderService) FindOrder(ctx context.Context, orderNumber string) (model.OrderWithHistory, error) {
+	return m.FindOrderFunc(ctx, orderNumber)
+}
+
+func (m *mockOrderService) UploadOrders(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error) {
+	return m.UploadOrdersFunc(ctx, orderNumbers)
+}
+
+func (m *mockOrderService) FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error) {
+	return m.FindDeadLetterOrdersFunc(ctx)
+}
+
+func (m *mockOrderService) ReplayOrder(ctx context.Context, orderNumber string) error {
+	return m.ReplayOrderFunc(ctx, orderNumber)
+}
+
 func TestUploadOrderHandler(t *testing.T) {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -203,3 +231,368 @@ func TestFindAllOrdersHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestFindAllOrdersHandler_Paginated(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	tests := []struct {
+		name           string
+		target         string
+		service        orderService
+		expectedStatus int
+		expectedCursor string
+	}{
+		{
+			name:   "Successful return page",
+			target: "/orders?limit=1&status=PROCESSED",
+			service: &mockOrderService{
+				FindOrdersPageFunc: func(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error) {
+					assert.Equal(t, []string{model.ProcessedOrderStatus}, page.Statuses)
+					return model.Page[model.Order]{Items: []model.Order{{OrderNumber: "12345"}}, NextCursor: "next"}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedCursor: "next",
+		},
+		{
+			name:           "Invalid status filter",
+			target:         "/orders?status=UNKNOWN",
+			service:        &mockOrderService{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Page is empty",
+			target: "/orders?limit=10",
+			service: &mockOrderService{
+				FindOrdersPageFunc: func(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error) {
+					return model.Page[model.Order]{}, model.ErrOrdersWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			handler := FindAllOrdersHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+			assert.Equal(t, tt.expectedCursor, res.Header.Get("X-Next-Cursor"))
+		})
+	}
+}
+
+func TestFindOrderHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	uploadedAt := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	accrual := 500.0
+
+	tests := []struct {
+		name           string
+		service        orderService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful return order",
+			service: &mockOrderService{
+				FindOrderFunc: func(ctx context.Context, orderNumber string) (model.OrderWithHistory, error) {
+					return model.OrderWithHistory{
+						Order: model.Order{OrderNumber: orderNumber, CreateDate: uploadedAt, Status: model.ProcessedOrderStatus, Accrual: accrual},
+						History: []model.OrderStatusTransition{
+							{Status: model.NewOrderStatus, CreateDate: uploadedAt},
+							{Status: model.ProcessedOrderStatus, Accrual: &accrual, CreateDate: uploadedAt.Add(time.Minute)},
+						},
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"number":"12345678903","uploaded_at":"2024-08-01T10:00:00Z","status":"PROCESSED","accrual":500,
+				"history":[{"status":"NEW","changed_at":"2024-08-01T10:00:00Z"},{"status":"PROCESSED","accrual":500,"changed_at":"2024-08-01T10:01:00Z"}]}`,
+		},
+		{
+			name: "Order not found",
+			service: &mockOrderService{
+				FindOrderFunc: func(ctx context.Context, orderNumber string) (model.OrderWithHistory, error) {
+					return model.OrderWithHistory{}, model.ErrOrderWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Order number is not valid",
+			service: &mockOrderService{
+				FindOrderFunc: func(ctx context.Context, orderNumber string) (model.OrderWithHistory, error) {
+					return model.OrderWithHistory{}, model.ErrOrderNumberIsNotValid
+				},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("number", "12345678903")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeContext)
+			req := httptest.NewRequest(http.MethodGet, "/orders/12345678903", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			handler := FindOrderHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestFindDeadLetterOrdersHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	deadLetteredAt := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		service        orderService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful return dead-lettered orders",
+			service: &mockOrderService{
+				FindDeadLetterOrdersFunc: func(ctx context.Context) ([]model.DeadLetterOrder, error) {
+					return []model.DeadLetterOrder{{OrderNumber: "12345678903", UserID: "testUser", Status: model.NewOrderStatus,
+						AttemptCount: 10, LastError: "timeout", DeadLetterDate: deadLetteredAt}}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"number":"12345678903","user_id":"testUser","status":"NEW","attempt_count":10,"last_error":"timeout",
+				"dead_lettered_at":"2024-08-01T10:00:00Z"}]`,
+		},
+		{
+			name: "Dead-lettered orders not found",
+			service: &mockOrderService{
+				FindDeadLetterOrdersFunc: func(ctx context.Context) ([]model.DeadLetterOrder, error) {
+					return nil, model.ErrDeadLetterOrdersWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Internal server error",
+			service: &mockOrderService{
+				FindDeadLetterOrdersFunc: func(ctx context.Context) ([]model.DeadLetterOrder, error) {
+					return nil, errors.New("internal error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/orders/dead-letter", nil)
+			rec := httptest.NewRecorder()
+
+			handler := FindDeadLetterOrdersHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestReplayOrderHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	tests := []struct {
+		name           string
+		method         string
+		service        orderService
+		expectedStatus int
+	}{
+		{
+			name:   "Successful replay",
+			method: http.MethodPost,
+			service: &mockOrderService{
+				ReplayOrderFunc: func(ctx context.Context, orderNumber string) error {
+					return nil
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:   "Dead-lettered order not found",
+			method: http.MethodPost,
+			service: &mockOrderService{
+				ReplayOrderFunc: func(ctx context.Context, orderNumber string) error {
+					return model.ErrDeadLetterOrderWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Order number is not valid",
+			method: http.MethodPost,
+			service: &mockOrderService{
+				ReplayOrderFunc: func(ctx context.Context, orderNumber string) error {
+					return model.ErrOrderNumberIsNotValid
+				},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid method",
+			method:         http.MethodGet,
+			service:        &mockOrderService{},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("number", "12345678903")
+			ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeContext)
+			req := httptest.NewRequest(tt.method, "/api/admin/orders/12345678903/replay", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			handler := ReplayOrderHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestUploadOrdersBatchHandler(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	echoService := &mockOrderService{
+		UploadOrdersFunc: func(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error) {
+			results := make([]model.OrderUploadResult, 0, len(orderNumbers))
+			for _, orderNumber := range orderNumbers {
+				results = append(results, model.OrderUploadResult{OrderNumber: orderNumber, Result: model.AcceptedUploadResult})
+			}
+			return results, nil
+		},
+	}
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		service        orderService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "JSON array of strings and numbers",
+			contentType:    "application/json",
+			body:           `["12345678903", 79927398713]`,
+			service:        echoService,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"number":"12345678903","result":"accepted"},{"number":"79927398713","result":"accepted"}]`,
+		},
+		{
+			name:           "Newline delimited text",
+			contentType:    "text/plain",
+			body:           "12345678903\r\n\n79927398713\n",
+			service:        echoService,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"number":"12345678903","result":"accepted"},{"number":"79927398713","result":"accepted"}]`,
+		},
+		{
+			name:           "Invalid JSON",
+			contentType:    "application/json",
+			body:           `["12345678903"`,
+			service:        echoService,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Empty batch",
+			contentType: "text/plain",
+			body:        "",
+			service: &mockOrderService{
+				UploadOrdersFunc: func(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error) {
+					return nil, model.ErrOrderNumberIsNotFilled
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Batch is too large",
+			contentType: "text/plain",
+			body:        "12345678903",
+			service: &mockOrderService{
+				UploadOrdersFunc: func(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error) {
+					return nil, model.ErrOrderBatchIsTooLarge
+				},
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "Body is too large",
+			contentType:    "text/plain",
+			body:           strings.Repeat("12345678903\n", int(api.MaxOrderBatchBodySize)/12+1),
+			service:        &mockOrderService{},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+
+			handler := UploadOrdersBatchHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"github.com/desepticon55/gofemart/internal/model"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var pageParams = []string{"limit", "cursor", "status", "from", "to", "sort"}
+
+func ParsePageRequest(request *http.Request, allowedStatuses []string) (model.PageRequest, bool, error) {
+	query := request.URL.Query()
+	paginated := false
+	for _, param := range pageParams {
+		if query.Has(param) {
+			paginated = true
+			break
+		}
+	}
+
+	if !paginated {
+		return model.PageRequest{}, false, nil
+	}
+
+	page := model.PageRequest{Limit: model.DefaultPageLimit}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > model.MaxPageLimit {
+			return model.PageRequest{}, true, model.ErrPageRequestIsNotValid
+		}
+		page.Limit = limit
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := model.DecodePageCursor(value)
+		if err != nil {
+			return model.PageRequest{}, true, err
+		}
+		page.Cursor = &cursor
+	}
+
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if !slices.Contains(allowedStatuses, status) {
+				return model.PageRequest{}, true, model.ErrPageRequestIsNotValid
+			}
+			page.Statuses = append(page.Statuses, status)
+		}
+	}
+
+	from, err := parsePageDate(query.Get("from"))
+	if err != nil {
+		return model.PageRequest{}, true, err
+	}
+	page.From = from
+
+	to, err := parsePageEndDate(query.Get("to"))
+	if err != nil {
+		return model.PageRequest{}, true, err
+	}
+	page.To = to
+
+	switch strings.ToLower(query.Get("sort")) {
+	case "", "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		return model.PageRequest{}, true, model.ErrPageRequestIsNotValid
+	}
+
+	return page, true, nil
+}
+
+func SetNextPageHeaders(writer http.ResponseWriter, request *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+
+	query := request.URL.Query()
+	query.Set("cursor", nextCursor)
+	next := url.URL{Path: request.URL.Path, RawQuery: query.Encode()}
+
+	writer.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	writer.Header().Set("X-Next-Cursor", nextCursor)
+}
+
+func ParseDateRange(request *http.Request) (*time.Time, *time.Time, error) {
+	query := request.URL.Query()
+	from, err := parsePageDate(query.Get("from"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := parsePageEndDate(query.Get("to"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+func parsePageDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, model.ErrPageRequestIsNotValid
+	}
+	return &date, nil
+}
+
+func parsePageEndDate(value string) (*time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return parsePageDate(value)
+	}
+
+	end := date.AddDate(0, 0, 1)
+	return &end, nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	statuses := []string{model.NewOrderStatus, model.ProcessedOrderStatus}
+
+	t.Run("should return not paginated request without params", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
+
+		_, paginated, err := ParsePageRequest(req, statuses)
+		assert.NoError(t, err)
+		assert.False(t, paginated)
+	})
+
+	t.Run("should parse all params", func(t *testing.T) {
+		cursor := model.PageCursor{CreateDate: time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC), Key: "12345678903"}
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/user/orders?limit=10&status=new,processed&from=2024-08-01&to=2024-09-01T00:00:00Z&sort=desc&cursor="+cursor.Encode(), nil)
+
+		page, paginated, err := ParsePageRequest(req, statuses)
+		assert.NoError(t, err)
+		assert.True(t, paginated)
+		assert.Equal(t, 10, page.Limit)
+		assert.Equal(t, []string{model.NewOrderStatus, model.ProcessedOrderStatus}, page.Statuses)
+		assert.Equal(t, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), *page.From)
+		assert.Equal(t, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), *page.To)
+		assert.True(t, page.Desc)
+		assert.Equal(t, cursor, *page.Cursor)
+	})
+
+	t.Run("should include the whole day of date only end", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders?to=2024-08-31", nil)
+
+		page, _, err := ParsePageRequest(req, statuses)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), *page.To)
+	})
+
+	t.Run("should use default limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/orders?sort=asc", nil)
+
+		page, paginated, err := ParsePageRequest(req, statuses)
+		assert.NoError(t, err)
+		assert.True(t, paginated)
+		assert.Equal(t, model.DefaultPageLimit, page.Limit)
+	})
+
+	t.Run("should return error for invalid params", func(t *testing.T) {
+		for _, query := range []string{"limit=0", "limit=abc", "status=UNKNOWN", "from=yesterday", "sort=random", "cursor=???"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/user/orders?"+query, nil)
+
+			_, _, err := ParsePageRequest(req, statuses)
+			assert.Equal(t, model.ErrPageRequestIsNotValid, err, query)
+		}
+	})
+}
+
+func TestSetNextPageHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders?limit=10", nil)
+	rec := httptest.NewRecorder()
+
+	SetNextPageHeaders(rec, req, "abc")
+
+	assert.Equal(t, `</api/user/orders?cursor=abc&limit=10>; rel="next"`, rec.Header().Get("Link"))
+	assert.Equal(t, "abc", rec.Header().Get("X-Next-Cursor"))
+}
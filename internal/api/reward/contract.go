@@ -0,0 +1,20 @@
+package reward
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type rewardService interface {
+	CreateReward(ctx context.Context, reward model.Reward) (model.Reward, error)
+
+	UpdateReward(ctx context.Context, reward model.Reward) error
+
+	DeleteReward(ctx context.Context, rewardID string) error
+
+	FindAllRewards(ctx context.Context) ([]model.Reward, error)
+
+	FindAvailableRewards(ctx context.Context) ([]model.Reward, error)
+
+	Redeem(ctx context.Context, rewardID string) (model.Redemption, error)
+}
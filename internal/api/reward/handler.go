@@ -0,0 +1,193 @@
+package reward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func CreateRewardHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var reward model.Reward
+		if err := json.NewDecoder(request.Body).Decode(&reward); err != nil {
+			logger.Error("Invalid request payload", zap.Error(err))
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		reward, err := service.CreateReward(request.Context(), reward)
+		if err != nil {
+			if errors.Is(err, model.ErrRewardDataIsNotValid) {
+				http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(logger, writer, http.StatusCreated, reward)
+	}
+}
+
+func UpdateRewardHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPut {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		var reward model.Reward
+		if err := json.NewDecoder(request.Body).Decode(&reward); err != nil {
+			logger.Error("Invalid request payload", zap.Error(err))
+			http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		reward.ID = chi.URLParam(request, "id")
+
+		err := service.UpdateReward(request.Context(), reward)
+		if err != nil {
+			if errors.Is(err, model.ErrRewardDataIsNotValid) {
+				http.Error(writer, "Invalid request payload", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrRewardWasNotFound) {
+				http.Error(writer, "Reward was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+func DeleteRewardHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodDelete {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		err := service.DeleteReward(request.Context(), chi.URLParam(request, "id"))
+		if err != nil {
+			if errors.Is(err, model.ErrRewardDataIsNotValid) {
+				http.Error(writer, "Reward id is not filled", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrRewardWasNotFound) {
+				http.Error(writer, "Reward was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+func FindAllRewardsHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		rewards, err := service.FindAllRewards(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrRewardsWasNotFound) {
+				http.Error(writer, "Rewards was not found", http.StatusNoContent)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(logger, writer, http.StatusOK, rewards)
+	}
+}
+
+func FindAvailableRewardsHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		rewards, err := service.FindAvailableRewards(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrRewardsWasNotFound) {
+				http.Error(writer, "Rewards was not found", http.StatusNoContent)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(logger, writer, http.StatusOK, rewards)
+	}
+}
+
+func RedeemRewardHandler(logger *zap.Logger, service rewardService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		redemption, err := service.Redeem(request.Context(), chi.URLParam(request, "id"))
+		if err != nil {
+			if errors.Is(err, model.ErrRewardDataIsNotValid) {
+				http.Error(writer, "Reward id is not filled", http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrRewardWasNotFound) {
+				http.Error(writer, "Reward was not found", http.StatusNotFound)
+				return
+			}
+
+			if errors.Is(err, model.ErrRewardIsNotAvailable) {
+				http.Error(writer, "Reward is not available", http.StatusConflict)
+				return
+			}
+
+			if errors.Is(err, model.ErrUserBalanceLessThanSumToWithdraw) {
+				http.Error(writer, "Balance less than reward price", http.StatusPaymentRequired)
+				return
+			}
+
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(logger, writer, http.StatusOK, &redemption)
+	}
+}
+
+func writeJSON(logger *zap.Logger, writer http.ResponseWriter, status int, value any) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		logger.Error("Error during marshal response.", zap.Error(err))
+		http.Error(writer, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if _, err = writer.Write(bytes); err != nil {
+		logger.Error("Error write response.", zap.Error(err))
+	}
+}
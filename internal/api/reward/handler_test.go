@@ -0,0 +1,297 @@
+package reward
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockRewardService struct {
+	CreateRewardFunc         func(ctx context.Context, reward model.Reward) (model.Reward, error)
+	UpdateRewardFunc         func(ctx context.Context, reward model.Reward) error
+	DeleteRewardFunc         func(ctx context.Context, rewardID string) error
+	FindAllRewardsFunc       func(ctx context.Context) ([]model.Reward, error)
+	FindAvailableRewardsFunc func(ctx context.Context) ([]model.Reward, error)
+	RedeemFunc               func(ctx context.Context, rewardID string) (model.Redemption, error)
+}
+
+func (m *mockRewardService) CreateReward(ctx context.Context, reward model.Reward) (model.Reward, error) {
+	return m.CreateRewardFunc(ctx, reward)
+}
+
+func (m *mockRewardService) UpdateReward(ctx context.Context, reward model.Reward) error {
+	return m.UpdateRewardFunc(ctx, reward)
+}
+
+func (m *mockRewardService) DeleteReward(ctx context.Context, rewardID string) error {
+	return m.DeleteRewardFunc(ctx, rewardID)
+}
+
+func (m *mockRewardService) FindAllRewards(ctx context.Context) ([]model.Reward, error) {
+	return m.FindAllRewardsFunc(ctx)
+}
+
+func (m *mockRewardService) FindAvailableRewards(ctx context.Context) ([]model.Reward, error) {
+	return m.FindAvailableRewardsFunc(ctx)
+}
+
+func (m *mockRewardService) Redeem(ctx context.Context, rewardID string) (model.Redemption, error) {
+	return m.RedeemFunc(ctx, rewardID)
+}
+
+func withURLParam(request *http.Request, key, value string) *http.Request {
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add(key, value)
+	return request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routeContext))
+}
+
+func TestCreateRewardHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		service        rewardService
+		expectedStatus int
+	}{
+		{
+			name:   "Successful create",
+			method: http.MethodPost,
+			body:   `{"name":"Coffee","price":100,"stock":10}`,
+			service: &mockRewardService{
+				CreateRewardFunc: func(ctx context.Context, reward model.Reward) (model.Reward, error) {
+					assert.Equal(t, "Coffee", reward.Name)
+					reward.ID = "1"
+					return reward, nil
+				},
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Invalid method",
+			method:         http.MethodGet,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid payload",
+			method:         http.MethodPost,
+			body:           `{`,
+			service:        nil,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Reward is not valid",
+			method: http.MethodPost,
+			body:   `{"name":"","price":100,"stock":10}`,
+			service: &mockRewardService{
+				CreateRewardFunc: func(ctx context.Context, reward model.Reward) (model.Reward, error) {
+					return model.Reward{}, model.ErrRewardDataIsNotValid
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/admin/rewards", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler := CreateRewardHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestDeleteRewardHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		service        rewardService
+		expectedStatus int
+	}{
+		{
+			name: "Successful delete",
+			service: &mockRewardService{
+				DeleteRewardFunc: func(ctx context.Context, rewardID string) error {
+					assert.Equal(t, "1", rewardID)
+					return nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Reward not found",
+			service: &mockRewardService{
+				DeleteRewardFunc: func(ctx context.Context, rewardID string) error {
+					return model.ErrRewardWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withURLParam(httptest.NewRequest(http.MethodDelete, "/api/admin/rewards/1", nil), "id", "1")
+			rec := httptest.NewRecorder()
+
+			handler := DeleteRewardHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestFindAvailableRewardsHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name           string
+		service        rewardService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful return rewards",
+			service: &mockRewardService{
+				FindAvailableRewardsFunc: func(ctx context.Context) ([]model.Reward, error) {
+					return []model.Reward{{ID: "1", Name: "Coffee", Description: "Latte", Price: 100, Stock: 10}}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":"1","name":"Coffee","description":"Latte","price":100,"stock":10,"archived":false}]`,
+		},
+		{
+			name: "Rewards not found",
+			service: &mockRewardService{
+				FindAvailableRewardsFunc: func(ctx context.Context) ([]model.Reward, error) {
+					return nil, model.ErrRewardsWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/user/rewards", nil)
+			rec := httptest.NewRecorder()
+
+			handler := FindAvailableRewardsHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestRedeemRewardHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		service        rewardService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful redeem",
+			service: &mockRewardService{
+				RedeemFunc: func(ctx context.Context, rewardID string) (model.Redemption, error) {
+					return model.Redemption{ID: "r1", RewardID: rewardID, Code: "ABC", Sum: 100, CreateDate: createDate}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"r1","reward_id":"1","code":"ABC","sum":100,"processed_at":"2024-08-01T10:00:00Z"}`,
+		},
+		{
+			name: "Reward not found",
+			service: &mockRewardService{
+				RedeemFunc: func(ctx context.Context, rewardID string) (model.Redemption, error) {
+					return model.Redemption{}, model.ErrRewardWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Reward out of stock",
+			service: &mockRewardService{
+				RedeemFunc: func(ctx context.Context, rewardID string) (model.Redemption, error) {
+					return model.Redemption{}, model.ErrRewardIsNotAvailable
+				},
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Insufficient balance",
+			service: &mockRewardService{
+				RedeemFunc: func(ctx context.Context, rewardID string) (model.Redemption, error) {
+					return model.Redemption{}, model.ErrUserBalanceLessThanSumToWithdraw
+				},
+			},
+			expectedStatus: http.StatusPaymentRequired,
+		},
+		{
+			name: "Internal server error",
+			service: &mockRewardService{
+				RedeemFunc: func(ctx context.Context, rewardID string) (model.Redemption, error) {
+					return model.Redemption{}, errors.New("general error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withURLParam(httptest.NewRequest(http.MethodPost, "/api/user/rewards/1/redeem", nil), "id", "1")
+			rec := httptest.NewRecorder()
+
+			handler := RedeemRewardHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
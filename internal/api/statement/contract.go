@@ -0,0 +1,12 @@
+package statement
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type statementService interface {
+	FindStatements(ctx context.Context) ([]model.Statement, error)
+
+	FindStatementDocument(ctx context.Context, statementID string, format string) ([]byte, error)
+}
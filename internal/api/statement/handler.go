@@ -0,0 +1,82 @@
+package statement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+var documentContentTypes = map[string]string{
+	"pdf": "application/pdf",
+	"csv": "text/csv",
+}
+
+func FindStatementsHandler(logger *zap.Logger, service statementService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		statements, err := service.FindStatements(request.Context())
+		if err != nil {
+			if errors.Is(err, model.ErrStatementsWasNotFound) {
+				http.Error(writer, "Statements was not found", http.StatusNoContent)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(statements)
+		if err != nil {
+			logger.Error("Error during marshal statements.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		if _, err = writer.Write(bytes); err != nil {
+			logger.Error("Error write statements.", zap.Error(err))
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func DownloadStatementHandler(logger *zap.Logger, service statementService) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			http.Error(writer, fmt.Sprintf("Method '%s' is not allowed", request.Method), http.StatusBadRequest)
+			return
+		}
+
+		statementID := chi.URLParam(request, "id")
+		format := chi.URLParam(request, "format")
+		contentType, ok := documentContentTypes[format]
+		if !ok {
+			http.Error(writer, "Statement was not found", http.StatusNotFound)
+			return
+		}
+
+		document, err := service.FindStatementDocument(request.Context(), statementID, format)
+		if err != nil {
+			if errors.Is(err, model.ErrStatementWasNotFound) || errors.Is(err, model.ErrDocumentWasNotFound) {
+				http.Error(writer, "Statement was not found", http.StatusNotFound)
+				return
+			}
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", contentType)
+		writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s.%s"`, statementID, format))
+		if _, err = writer.Write(document); err != nil {
+			logger.Error("Error write statement document.", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockStatementService struct {
+	FindStatementsFunc        func(ctx context.Context) ([]model.Statement, error)
+	FindStatementDocumentFunc func(ctx context.Context, statementID string, format string) ([]byte, error)
+}
+
+func (m *mockStatementService) FindStatements(ctx context.Context) ([]model.Statement, error) {
+	return m.FindStatementsFunc(ctx)
+}
+
+func (m *mockStatementService) FindStatementDocument(ctx context.Context, statementID string, format string) ([]byte, error) {
+	return m.FindStatementDocumentFunc(ctx, statementID, format)
+}
+
+func TestFindStatementsHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	periodStart := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		service        statementService
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Successful return statements",
+			service: &mockStatementService{
+				FindStatementsFunc: func(ctx context.Context) ([]model.Statement, error) {
+					return []model.Statement{{ID: "1", PeriodStart: periodStart, PeriodEnd: periodStart.AddDate(0, 1, 0),
+						OpeningBalance: 100, Accrued: 50, Withdrawn: 30, ClosingBalance: 120, CreateDate: periodStart.AddDate(0, 1, 0)}}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"id":"1","period_start":"2024-08-01T00:00:00Z","period_end":"2024-09-01T00:00:00Z","opening_balance":100,` +
+				`"accrued":50,"withdrawn":30,"closing_balance":120,"pdf_url":"/api/user/statements/1/pdf",` +
+				`"csv_url":"/api/user/statements/1/csv","created_at":"2024-09-01T00:00:00Z"}]`,
+		},
+		{
+			name: "Statements not found",
+			service: &mockStatementService{
+				FindStatementsFunc: func(ctx context.Context) ([]model.Statement, error) {
+					return nil, model.ErrStatementsWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Internal server error",
+			service: &mockStatementService{
+				FindStatementsFunc: func(ctx context.Context) ([]model.Statement, error) {
+					return nil, errors.New("general error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/user/statements", nil)
+			rec := httptest.NewRecorder()
+
+			handler := FindStatementsHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestDownloadStatementHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	tests := []struct {
+		name                string
+		format              string
+		service             statementService
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			name:   "Successful download PDF",
+			format: "pdf",
+			service: &mockStatementService{
+				FindStatementDocumentFunc: func(ctx context.Context, statementID string, format string) ([]byte, error) {
+					assert.Equal(t, "1", statementID)
+					assert.Equal(t, "pdf", format)
+					return []byte("%PDF-1.4"), nil
+				},
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/pdf",
+		},
+		{
+			name:           "Unsupported format",
+			format:         "xml",
+			service:        nil,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Statement not found",
+			format: "csv",
+			service: &mockStatementService{
+				FindStatementDocumentFunc: func(ctx context.Context, statementID string, format string) ([]byte, error) {
+					return nil, model.ErrStatementWasNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Internal server error",
+			format: "csv",
+			service: &mockStatementService{
+				FindStatementDocumentFunc: func(ctx context.Context, statementID string, format string) ([]byte, error) {
+					return nil, errors.New("general error")
+				},
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/user/statements/1/"+tt.format, nil)
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", "1")
+			routeContext.URLParams.Add("format", tt.format)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeContext))
+			rec := httptest.NewRecorder()
+
+			handler := DownloadStatementHandler(logger, tt.service)
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, res.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -7,4 +7,6 @@ import (
 
 type withdrawalService interface {
 	FindAllWithdrawals(ctx context.Context) ([]model.Withdrawal, error)
+
+	FindWithdrawalsPage(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error)
 }
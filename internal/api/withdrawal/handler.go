@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/desepticon55/gofemart/internal/api"
 	"github.com/desepticon55/gofemart/internal/model"
 	"go.uber.org/zap"
 	"net/http"
@@ -16,12 +17,30 @@ func FindAllWithdrawalsHandler(logger *zap.Logger, service withdrawalService) ht
 			return
 		}
 
-		withdrawals, err := service.FindAllWithdrawals(request.Context())
+		page, paginated, err := api.ParsePageRequest(request, nil)
+		if err != nil {
+			http.Error(writer, "Invalid pagination parameters", http.StatusBadRequest)
+			return
+		}
+
+		var withdrawals []model.Withdrawal
+		if paginated {
+			var result model.Page[model.Withdrawal]
+			result, err = service.FindWithdrawalsPage(request.Context(), page)
+			withdrawals = result.Items
+			api.SetNextPageHeaders(writer, request, result.NextCursor)
+		} else {
+			withdrawals, err = service.FindAllWithdrawals(request.Context())
+		}
 		if err != nil {
 			if errors.Is(err, model.ErrWithdrawalsWasNotFound) {
 				http.Error(writer, "Order number is not filled", http.StatusNoContent)
 				return
 			}
+			if errors.Is(err, model.ErrPageRequestIsNotValid) {
+				http.Error(writer, "Invalid pagination parameters", http.StatusBadRequest)
+				return
+			}
 			http.Error(writer, "Internal server error", http.StatusInternalServerError)
 			return
 		}
@@ -10,16 +10,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type mockWithdrawalService struct {
-	FindAllWithdrawalsFunc func(ctx context.Context) ([]model.Withdrawal, error)
+	FindAllWithdrawalsFunc  func(ctx context.Context) ([]model.Withdrawal, error)
+	FindWithdrawalsPageFunc func(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error)
 }
 
 func (m *mockWithdrawalService) FindAllWithdrawals(ctx context.Context) ([]model.Withdrawal, error) {
 	return m.FindAllWithdrawalsFunc(ctx)
 }
 
+func (m *mockWithdrawalService) FindWithdrawalsPage(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+	return m.FindWithdrawalsPageFunc(ctx, page)
+}
+
 func TestFindAllWithdrawalsHandler(t *testing.T) {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -37,8 +43,8 @@ func TestFindAllWithdrawalsHandler(t *testing.T) {
 			service: &mockWithdrawalService{
 				FindAllWithdrawalsFunc: func(ctx context.Context) ([]model.Withdrawal, error) {
 					return []model.Withdrawal{
-						{ID: "1", Sum: 100.0, Username: "testUser", OrderNumber: "12345"},
-						{ID: "2", Sum: 50.0, Username: "testUser", OrderNumber: "67432"},
+						{ID: "1", Sum: 100.0, UserID: "testUser", OrderNumber: "12345"},
+						{ID: "2", Sum: 50.0, UserID: "testUser", OrderNumber: "67432"},
 					}, nil
 				},
 			},
@@ -95,3 +101,67 @@ func TestFindAllWithdrawalsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestFindAllWithdrawalsHandler_Paginated(t *testing.T) {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	t.Run("should return page with next link", func(t *testing.T) {
+		service := &mockWithdrawalService{
+			FindWithdrawalsPageFunc: func(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+				assert.Equal(t, 1, page.Limit)
+				assert.True(t, page.Desc)
+				return model.Page[model.Withdrawal]{
+					Items:      []model.Withdrawal{{ID: "1", Sum: 100.0, OrderNumber: "12345"}},
+					NextCursor: "next",
+				}, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/withdrawals?limit=1&sort=desc", nil)
+		rec := httptest.NewRecorder()
+
+		FindAllWithdrawalsHandler(logger, service).ServeHTTP(rec, req)
+
+		res := rec.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "next", res.Header.Get("X-Next-Cursor"))
+		assert.Equal(t, `</withdrawals?cursor=next&limit=1&sort=desc>; rel="next"`, res.Header.Get("Link"))
+
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"order":"12345", "processed_at":"0001-01-01T00:00:00Z", "sum":100}]`, string(body))
+	})
+
+	t.Run("should return bad request for status filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/withdrawals?status=NEW", nil)
+		rec := httptest.NewRecorder()
+
+		FindAllWithdrawalsHandler(logger, &mockWithdrawalService{}).ServeHTTP(rec, req)
+
+		res := rec.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+	t.Run("should return bad request for invalid cursor", func(t *testing.T) {
+		service := &mockWithdrawalService{
+			FindWithdrawalsPageFunc: func(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+				return model.Page[model.Withdrawal]{}, model.ErrPageRequestIsNotValid
+			},
+		}
+
+		cursor := model.PageCursor{CreateDate: time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC), Key: "not-uuid"}
+		req := httptest.NewRequest(http.MethodGet, "/withdrawals?cursor="+cursor.Encode(), nil)
+		rec := httptest.NewRecorder()
+
+		FindAllWithdrawalsHandler(logger, service).ServeHTTP(rec, req)
+
+		res := rec.Result()
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
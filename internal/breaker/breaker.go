@@ -0,0 +1,144 @@
+package breaker
+
+import (
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+type Settings struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+}
+
+type CircuitBreaker struct {
+	logger    *zap.Logger
+	metrics   metrics.Metrics
+	name      string
+	settings  Settings
+	now       func() time.Time
+	mu        sync.Mutex
+	state     State
+	failures  int
+	trials    int
+	successes int
+	openDate  time.Time
+}
+
+func NewCircuitBreaker(logger *zap.Logger, metrics metrics.Metrics, name string, settings Settings) *CircuitBreaker {
+	b := &CircuitBreaker{logger: logger, metrics: metrics, name: name, settings: settings, now: time.Now}
+	metrics.SetCircuitBreakerState(name, int(Closed))
+	return b
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return b.state
+}
+
+func (b *CircuitBreaker) RetryIn() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	if b.state != Open {
+		return 0
+	}
+	return b.openDate.Add(b.settings.OpenTimeout).Sub(b.now())
+}
+
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case Open:
+		return model.ErrCircuitIsOpen
+	case HalfOpen:
+		if b.trials >= b.settings.HalfOpenRequests {
+			return model.ErrCircuitIsOpen
+		}
+		b.trials++
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		b.failures = 0
+	case HalfOpen:
+		b.successes++
+		if b.successes >= b.settings.HalfOpenRequests {
+			b.setState(Closed)
+		}
+	}
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.setState(Open)
+		}
+	case HalfOpen:
+		b.setState(Open)
+	}
+}
+
+func (b *CircuitBreaker) refresh() {
+	if b.state == Open && !b.now().Before(b.openDate.Add(b.settings.OpenTimeout)) {
+		b.setState(HalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(state State) {
+	b.logger.Warn("Circuit breaker state changed", zap.String("name", b.name),
+		zap.Stringer("from", b.state), zap.Stringer("to", state))
+	b.state = state
+	b.failures = 0
+	b.trials = 0
+	b.successes = 0
+	if state == Open {
+		b.openDate = b.now()
+	}
+	b.metrics.SetCircuitBreakerState(b.name, int(state))
+}
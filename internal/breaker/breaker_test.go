@@ -0,0 +1,100 @@
+package breaker
+
+import (
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(t *testing.T, now *time.Time) *CircuitBreaker {
+	b := NewCircuitBreaker(zaptest.NewLogger(t), metrics.NewNoopMetrics(), "accrual",
+		Settings{FailureThreshold: 2, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("should open after consecutive failures", func(t *testing.T) {
+		now := time.Now()
+		b := newTestBreaker(t, &now)
+
+		b.Failure()
+		b.Success()
+		b.Failure()
+		assert.Equal(t, Closed, b.State(), "success should reset failures")
+
+		b.Failure()
+		assert.Equal(t, Open, b.State())
+		assert.ErrorIs(t, b.Allow(), model.ErrCircuitIsOpen)
+		assert.Equal(t, time.Minute, b.RetryIn())
+	})
+
+	t.Run("should close after successful trial", func(t *testing.T) {
+		now := time.Now()
+		b := newTestBreaker(t, &now)
+		b.Failure()
+		b.Failure()
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, HalfOpen, b.State())
+		assert.Equal(t, time.Duration(0), b.RetryIn())
+		assert.NoError(t, b.Allow())
+		assert.ErrorIs(t, b.Allow(), model.ErrCircuitIsOpen, "only one trial request should be allowed")
+
+		b.Success()
+		assert.Equal(t, Closed, b.State())
+		assert.NoError(t, b.Allow())
+	})
+
+	t.Run("should open again after failed trial", func(t *testing.T) {
+		now := time.Now()
+		b := newTestBreaker(t, &now)
+		b.Failure()
+		b.Failure()
+
+		now = now.Add(time.Minute)
+		assert.NoError(t, b.Allow())
+		b.Failure()
+		assert.Equal(t, Open, b.State())
+		assert.Equal(t, time.Minute, b.RetryIn())
+	})
+}
+
+func TestClient(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	b := newTestBreaker(t, &now)
+	client := NewClient(http.DefaultClient, b)
+	get := func() (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		return client.Do(req)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := get()
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, Open, b.State())
+
+	_, err := get()
+	assert.ErrorIs(t, err, model.ErrCircuitIsOpen)
+
+	status = http.StatusNoContent
+	now = now.Add(time.Minute)
+	resp, err := get()
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, Closed, b.State())
+}
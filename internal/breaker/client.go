@@ -0,0 +1,28 @@
+package breaker
+
+import (
+	"net/http"
+)
+
+type Client struct {
+	next    doer
+	breaker *CircuitBreaker
+}
+
+func NewClient(next doer, breaker *CircuitBreaker) *Client {
+	return &Client{next: next, breaker: breaker}
+}
+
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		c.breaker.Failure()
+	} else {
+		c.breaker.Success()
+	}
+	return resp, err
+}
@@ -0,0 +1,9 @@
+package breaker
+
+import (
+	"net/http"
+)
+
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+type fieldsKey struct{}
+
+type RequestFields struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+func New(level string, encoding string) (*zap.Logger, error) {
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("error during parse log level: %w", err)
+	}
+
+	productionConfig := zap.NewProductionConfig()
+	productionConfig.Encoding = encoding
+	productionConfig.Level = atomicLevel
+	productionConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	sampling := productionConfig.Sampling
+	productionConfig.Sampling = nil
+	return productionConfig.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(NewRedactingCore(core), time.Second, sampling.Initial, sampling.Thereafter)
+	}))
+}
+
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+func WithRequestFields(ctx context.Context) (context.Context, *RequestFields) {
+	fields := &RequestFields{}
+	return context.WithValue(ctx, fieldsKey{}, fields), fields
+}
+
+func AddRequestFields(ctx context.Context, fields ...zap.Field) {
+	if holder, ok := ctx.Value(fieldsKey{}).(*RequestFields); ok {
+		holder.mu.Lock()
+		defer holder.mu.Unlock()
+		holder.fields = append(holder.fields, fields...)
+	}
+}
+
+func (f *RequestFields) Fields() []zap.Field {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]zap.Field(nil), f.fields...)
+}
+
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	logger, ok := ctx.Value(contextKey{}).(*zap.Logger)
+	if !ok {
+		return fallback
+	}
+
+	// chi matches the route only after the request logger was injected
+	if routeContext := chi.RouteContext(ctx); routeContext != nil && routeContext.RoutePattern() != "" {
+		return logger.With(zap.String("route", routeContext.RoutePattern()))
+	}
+	return logger
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	fallback := zap.NewNop()
+	assert.Same(t, fallback, FromContext(context.Background(), fallback))
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	routeContext := chi.NewRouteContext()
+	routeContext.RoutePatterns = []string{"/api/user/orders/{number}"}
+	ctx := context.WithValue(context.Background(), chi.RouteCtxKey, routeContext)
+	ctx = WithLogger(ctx, zap.New(core).With(zap.String("requestID", "1")))
+
+	FromContext(ctx, fallback).Info("Order was found")
+
+	assert.Equal(t, map[string]interface{}{"requestID": "1", "route": "/api/user/orders/{number}"}, logs.All()[0].ContextMap())
+}
+
+func TestNew(t *testing.T) {
+	logger, err := New("warn", "json")
+	assert.NoError(t, err)
+	assert.False(t, logger.Core().Enabled(zapcore.InfoLevel))
+
+	_, err = New("verbose", "json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var secretKeys = map[string]struct{}{
+	"authorization": {},
+	"token":         {},
+	"jwt":           {},
+	"password":      {},
+	"secret":        {},
+}
+
+var orderNumberKeys = map[string]struct{}{
+	"order":        {},
+	"ordernumber":  {},
+	"order_number": {},
+	"number":       {},
+}
+
+var (
+	bearerPattern      = regexp.MustCompile(`(?i)bearer\s+\S+`)
+	jwtPattern         = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]*`)
+	credentialsPattern = regexp.MustCompile(`(://[^:/@\s]+):[^@\s]+@`)
+	passwordPattern    = regexp.MustCompile(`(?i)(password=)('[^']*'|\S+)`)
+	orderPathPattern   = regexp.MustCompile(`(/orders/)(\d+)`)
+)
+
+type redactingCore struct {
+	zapcore.Core
+}
+
+func NewRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func Redact(value string) string {
+	value = bearerPattern.ReplaceAllString(value, "Bearer "+redacted)
+	value = jwtPattern.ReplaceAllString(value, redacted)
+	value = credentialsPattern.ReplaceAllString(value, "$1:"+redacted+"@")
+	value = passwordPattern.ReplaceAllString(value, "${1}"+redacted)
+	return orderPathPattern.ReplaceAllStringFunc(value, func(path string) string {
+		match := orderPathPattern.FindStringSubmatch(path)
+		return match[1] + MaskOrderNumber(match[2])
+	})
+}
+
+func MaskOrderNumber(orderNumber string) string {
+	const visible = 4
+	if len(orderNumber) <= visible {
+		return strings.Repeat("*", len(orderNumber))
+	}
+	return strings.Repeat("*", len(orderNumber)-visible) + orderNumber[len(orderNumber)-visible:]
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		result[i] = redactField(field)
+	}
+	return result
+}
+
+func redactField(field zapcore.Field) zapcore.Field {
+	key := strings.ToLower(field.Key)
+	if _, ok := secretKeys[key]; ok {
+		return zap.String(field.Key, redacted)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		if _, ok := orderNumberKeys[key]; ok {
+			return zap.String(field.Key, MaskOrderNumber(field.String))
+		}
+		field.String = Redact(field.String)
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if message := Redact(err.Error()); message != err.Error() {
+				return zap.String(field.Key, message)
+			}
+		}
+	}
+	return field
+}
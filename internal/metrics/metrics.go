@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type Metrics interface {
+	ObserveHTTPRequest(route string, method string, status int, duration time.Duration)
+
+	ObserveAccrualRequest(status int, duration time.Duration)
+
+	AddOrdersFetched(shard string, count int)
+
+	AddOrdersDeadLettered(count int)
+
+	SetOrderQueueDepth(status string, count int)
+
+	SetCircuitBreakerState(name string, state int)
+
+	AddPointsAccrued(sum float64)
+
+	AddPointsWithdrawn(sum float64)
+
+	RegisterPool(pool *pgxpool.Pool)
+}
+
+type NoopMetrics struct{}
+
+func NewNoopMetrics() *NoopMetrics {
+	return &NoopMetrics{}
+}
+
+func (m *NoopMetrics) ObserveHTTPRequest(route string, method string, status int, duration time.Duration) {
+}
+
+func (m *NoopMetrics) ObserveAccrualRequest(status int, duration time.Duration) {}
+
+func (m *NoopMetrics) AddOrdersFetched(shard string, count int) {}
+
+func (m *NoopMetrics) AddOrdersDeadLettered(count int) {}
+
+func (m *NoopMetrics) SetOrderQueueDepth(status string, count int) {}
+
+func (m *NoopMetrics) SetCircuitBreakerState(name string, state int) {}
+
+func (m *NoopMetrics) AddPointsAccrued(sum float64) {}
+
+func (m *NoopMetrics) AddPointsWithdrawn(sum float64) {}
+
+func (m *NoopMetrics) RegisterPool(pool *pgxpool.Pool) {}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "gophermart"
+
+type PrometheusMetrics struct {
+	registry           *prometheus.Registry
+	httpRequests       *prometheus.CounterVec
+	httpDuration       *prometheus.HistogramVec
+	accrualRequests    *prometheus.CounterVec
+	accrualDuration    prometheus.Histogram
+	accrualRateLimited prometheus.Counter
+	ordersFetched      *prometheus.CounterVec
+	ordersDeadLettered prometheus.Counter
+	orderQueueDepth    *prometheus.GaugeVec
+	circuitState       *prometheus.GaugeVec
+	pointsAccrued      prometheus.Counter
+	pointsWithdrawn    prometheus.Counter
+}
+
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "http", Name: "requests_total",
+			Help: "Number of HTTP requests by route pattern, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+			Help: "HTTP request latency by route pattern, method and status.", Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		accrualRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "accrual", Name: "requests_total",
+			Help: "Number of accrual system requests by response status.",
+		}, []string{"status"}),
+		accrualDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "accrual", Name: "request_duration_seconds",
+			Help: "Accrual system request latency.", Buckets: prometheus.DefBuckets,
+		}),
+		accrualRateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "accrual", Name: "rate_limited_total",
+			Help: "Number of 429 responses from accrual system.",
+		}),
+		ordersFetched: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "order_worker", Name: "orders_fetched_total",
+			Help: "Number of orders fetched for processing by shard.",
+		}, []string{"shard"}),
+		ordersDeadLettered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "order_worker", Name: "orders_dead_lettered_total",
+			Help: "Number of orders moved to dead-letter state after exhausting retry attempts.",
+		}),
+		orderQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "order_worker", Name: "queue_depth",
+			Help: "Number of orders waiting for accrual by status.",
+		}, []string{"status"}),
+		circuitState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "circuit_breaker_state",
+			Help: "Circuit breaker state: 0 - closed, 1 - half-open, 2 - open.",
+		}, []string{"name"}),
+		pointsAccrued: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "points_accrued_total",
+			Help: "Sum of loyalty points accrued to users.",
+		}),
+		pointsWithdrawn: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "points_withdrawn_total",
+			Help: "Sum of loyalty points withdrawn by users.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration,
+		m.accrualRequests, m.accrualDuration, m.accrualRateLimited,
+		m.ordersFetched, m.ordersDeadLettered, m.orderQueueDepth, m.circuitState,
+		m.pointsAccrued, m.pointsWithdrawn,
+	)
+	return m
+}
+
+func (m *PrometheusMetrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *PrometheusMetrics) ObserveHTTPRequest(route string, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(route, method, code).Inc()
+	m.httpDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) ObserveAccrualRequest(status int, duration time.Duration) {
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	m.accrualRequests.WithLabelValues(code).Inc()
+	m.accrualDuration.Observe(duration.Seconds())
+	if status == http.StatusTooManyRequests {
+		m.accrualRateLimited.Inc()
+	}
+}
+
+func (m *PrometheusMetrics) AddOrdersFetched(shard string, count int) {
+	m.ordersFetched.WithLabelValues(shard).Add(float64(count))
+}
+
+func (m *PrometheusMetrics) AddOrdersDeadLettered(count int) {
+	m.ordersDeadLettered.Add(float64(count))
+}
+
+func (m *PrometheusMetrics) SetOrderQueueDepth(status string, count int) {
+	m.orderQueueDepth.WithLabelValues(status).Set(float64(count))
+}
+
+func (m *PrometheusMetrics) SetCircuitBreakerState(name string, state int) {
+	m.circuitState.WithLabelValues(name).Set(float64(state))
+}
+
+func (m *PrometheusMetrics) AddPointsAccrued(sum float64) {
+	if sum > 0 {
+		m.pointsAccrued.Add(sum)
+	}
+}
+
+func (m *PrometheusMetrics) AddPointsWithdrawn(sum float64) {
+	if sum > 0 {
+		m.pointsWithdrawn.Add(sum)
+	}
+}
+
+func (m *PrometheusMetrics) RegisterPool(pool *pgxpool.Pool) {
+	m.registry.MustRegister(newPoolCollector(pool))
+}
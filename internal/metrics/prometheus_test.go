@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Run("should count http requests by route, method and status", func(t *testing.T) {
+		m := NewPrometheusMetrics()
+
+		m.ObserveHTTPRequest("/api/user/orders/{number}", http.MethodGet, http.StatusOK, time.Millisecond)
+		m.ObserveHTTPRequest("/api/user/orders/{number}", http.MethodGet, http.StatusOK, time.Millisecond)
+		m.ObserveHTTPRequest("/api/user/orders/{number}", http.MethodGet, http.StatusNotFound, time.Millisecond)
+
+		assert.Equal(t, 2., testutil.ToFloat64(m.httpRequests.WithLabelValues("/api/user/orders/{number}", http.MethodGet, "200")))
+		assert.Equal(t, 1., testutil.ToFloat64(m.httpRequests.WithLabelValues("/api/user/orders/{number}", http.MethodGet, "404")))
+	})
+
+	t.Run("should count accrual responses and rate limits", func(t *testing.T) {
+		m := NewPrometheusMetrics()
+
+		m.ObserveAccrualRequest(http.StatusOK, time.Millisecond)
+		m.ObserveAccrualRequest(http.StatusTooManyRequests, time.Millisecond)
+		m.ObserveAccrualRequest(0, time.Millisecond)
+
+		assert.Equal(t, 1., testutil.ToFloat64(m.accrualRequests.WithLabelValues("200")))
+		assert.Equal(t, 1., testutil.ToFloat64(m.accrualRequests.WithLabelValues("error")))
+		assert.Equal(t, 1., testutil.ToFloat64(m.accrualRateLimited))
+	})
+
+	t.Run("should track business counters and queue depth", func(t *testing.T) {
+		m := NewPrometheusMetrics()
+
+		m.AddPointsAccrued(100)
+		m.AddPointsAccrued(0)
+		m.AddPointsWithdrawn(30.5)
+		m.AddOrdersFetched("5", 3)
+		m.SetOrderQueueDepth("NEW", 7)
+		m.SetOrderQueueDepth("NEW", 5)
+
+		assert.Equal(t, 100., testutil.ToFloat64(m.pointsAccrued))
+		assert.Equal(t, 30.5, testutil.ToFloat64(m.pointsWithdrawn))
+		assert.Equal(t, 3., testutil.ToFloat64(m.ordersFetched.WithLabelValues("5")))
+		assert.Equal(t, 5., testutil.ToFloat64(m.orderQueueDepth.WithLabelValues("NEW")))
+	})
+}
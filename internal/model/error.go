@@ -6,12 +6,45 @@ var (
 	ErrUserDataIsNotValid                = errors.New("user data is not valid")
 	ErrUserAlreadyExists                 = errors.New("user already exists")
 	ErrOrdersWasNotFound                 = errors.New("orders to current user was not found")
+	ErrOrderWasNotFound                  = errors.New("order was not found")
 	ErrOrderNumberIsNotFilled            = errors.New("order number is not filled")
+	ErrOrderBatchIsTooLarge              = errors.New("order batch is too large")
 	ErrOrderNumberIsNotValid             = errors.New("order number is not valid")
 	ErrOrderNumberHasUploadedOtherUser   = errors.New("order number has uploaded from other user")
 	ErrOrderNumberHasUploadedCurrentUser = errors.New("order number has uploaded early")
+	ErrOrderLeaseIsLost                  = errors.New("order lease is lost")
+	ErrDeadLetterOrderWasNotFound        = errors.New("dead-lettered order was not found")
+	ErrDeadLetterOrdersWasNotFound       = errors.New("dead-lettered orders was not found")
+	ErrRateLimitWasNotFound              = errors.New("rate limit was not found")
+	ErrCircuitIsOpen                     = errors.New("circuit breaker is open")
+	ErrAccrualIsUnavailable              = errors.New("accrual system is unavailable")
+	ErrRateLimiterIsUnavailable          = errors.New("rate limiter is unavailable")
+	ErrOrderIsAlreadyProcessed           = errors.New("order is already processed")
+	ErrAccrualStatusIsNotValid           = errors.New("accrual status is not valid")
 	ErrUserBalanceLessThanSumToWithdraw  = errors.New("user balance less than sum to withdraw")
 	ErrUserBalanceHasChanged             = errors.New("user balance has changed in other transaction")
+	ErrUserBalanceWasNotFound            = errors.New("user balance was not found")
 	ErrOrderNumberOrSumIsNotFilled       = errors.New("order number or sum is not filled")
 	ErrWithdrawalsWasNotFound            = errors.New("withdrawals to current user was not found")
+	ErrRewardDataIsNotValid              = errors.New("reward data is not valid")
+	ErrRewardWasNotFound                 = errors.New("reward was not found")
+	ErrRewardsWasNotFound                = errors.New("rewards was not found")
+	ErrRewardIsNotAvailable              = errors.New("reward is out of stock or not valid now")
+	ErrPageRequestIsNotValid             = errors.New("page request is not valid")
+	ErrHoldWasNotFound                   = errors.New("hold was not found")
+	ErrHoldIsNotAuthorized               = errors.New("hold was already captured, voided or expired")
+	ErrExportRequestIsNotValid           = errors.New("export request is not valid")
+	ErrStatementWasNotFound              = errors.New("statement was not found")
+	ErrStatementsWasNotFound             = errors.New("statements to current user was not found")
+	ErrDocumentWasNotFound               = errors.New("document was not found")
+	ErrUserWasNotFound                   = errors.New("user was not found")
+	ErrBalanceSettlementIsNotValid       = errors.New("balance settlement is not valid")
+	ErrAccountDeletionIsAlreadyRequested = errors.New("account deletion is already requested")
+	ErrAccountDeletionWasNotFound        = errors.New("account deletion was not requested or grace period is over")
+	ErrIdentifierIsNotValid              = errors.New("identifier is not valid")
+	ErrIdentifierAlreadyExists           = errors.New("identifier already exists")
+	ErrIdentifierWasNotFound             = errors.New("identifier was not found")
+	ErrIdentifiersWasNotFound            = errors.New("identifiers to current user was not found")
+	ErrVerificationCodeIsNotValid        = errors.New("verification code is not valid")
+	ErrVerificationCodeIsExpired         = errors.New("verification code is expired")
 )
@@ -13,34 +13,102 @@ const (
 	ProcessedOrderStatus  = "PROCESSED"
 )
 
+const (
+	AcceptedUploadResult          = "accepted"
+	DuplicateOwnUploadResult      = "duplicate-own"
+	ConflictOtherUserUploadResult = "conflict-other-user"
+	InvalidUploadResult           = "invalid"
+)
+
+const (
+	OrderTransactionType      = "order"
+	AccrualTransactionType    = "accrual"
+	WithdrawalTransactionType = "withdrawal"
+)
+
+const (
+	AuthorizedHoldStatus = "AUTHORIZED"
+	CapturedHoldStatus   = "CAPTURED"
+	VoidedHoldStatus     = "VOIDED"
+	ExpiredHoldStatus    = "EXPIRED"
+)
+
+const (
+	ForfeitBalanceSettlement = "FORFEIT"
+	PayoutBalanceSettlement  = "PAYOUT"
+	PendingPayoutStatus      = "PENDING"
+)
+
+const (
+	EmailIdentifierType = "EMAIL"
+	PhoneIdentifierType = "PHONE"
+)
+
 type Claims struct {
+	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	jwt.RegisteredClaims
 }
 
 type User struct {
+	ID       string `json:"-"`
 	Username string `json:"login"`
 	Password string `json:"password"`
 }
 
 type Balance struct {
-	Username string
+	UserID   string
 	Balance  float64
+	Reserved float64
 	Version  int64
 }
 
+func (e Balance) Spendable() float64 {
+	return e.Balance - e.Reserved
+}
+
+type Hold struct {
+	ID             string
+	UserID         string
+	OrderNumber    string
+	Sum            float64
+	Status         string
+	CreateDate     time.Time
+	LastModifyDate time.Time
+	ExpireDate     time.Time
+}
+
+func (e *Hold) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID          string  `json:"id"`
+		OrderNumber string  `json:"order"`
+		Sum         float64 `json:"sum"`
+		Status      string  `json:"status"`
+		CreateDate  string  `json:"created_at"`
+		ExpireDate  string  `json:"expires_at"`
+	}{
+		ID:          e.ID,
+		OrderNumber: e.OrderNumber,
+		Sum:         e.Sum,
+		Status:      e.Status,
+		CreateDate:  e.CreateDate.Format(time.RFC3339),
+		ExpireDate:  e.ExpireDate.Format(time.RFC3339),
+	})
+}
+
 type BalanceStats struct {
-	Username  string  `json:"-"`
+	UserID    string  `json:"-"`
 	Balance   float64 `json:"current"`
 	Withdrawn float64 `json:"withdrawn"`
 }
 
 type Withdrawal struct {
 	ID          string
-	Username    string
+	UserID      string
 	OrderNumber string
 	Sum         float64
 	CreateDate  time.Time
+	RewardID    string
 }
 
 func (e *Withdrawal) MarshalJSON() ([]byte, error) {
@@ -48,10 +116,63 @@ func (e *Withdrawal) MarshalJSON() ([]byte, error) {
 		OrderNumber string  `json:"order"`
 		Sum         float64 `json:"sum"`
 		CreateDate  string  `json:"processed_at"`
+		RewardID    string  `json:"reward_id,omitempty"`
 	}{
 		OrderNumber: e.OrderNumber,
 		CreateDate:  e.CreateDate.Format(time.RFC3339),
 		Sum:         e.Sum,
+		RewardID:    e.RewardID,
+	})
+}
+
+type Reward struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       float64    `json:"price"`
+	Stock       int64      `json:"stock"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidTo     *time.Time `json:"valid_to,omitempty"`
+	Archived    bool       `json:"archived"`
+	CreateDate  time.Time  `json:"-"`
+	Version     int64      `json:"-"`
+}
+
+func (e Reward) IsAvailable(now time.Time) bool {
+	if e.Archived || e.Stock <= 0 {
+		return false
+	}
+	if e.ValidFrom != nil && now.Before(*e.ValidFrom) {
+		return false
+	}
+	if e.ValidTo != nil && !now.Before(*e.ValidTo) {
+		return false
+	}
+	return true
+}
+
+type Redemption struct {
+	ID         string
+	RewardID   string
+	UserID     string
+	Code       string
+	Sum        float64
+	CreateDate time.Time
+}
+
+func (e *Redemption) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID         string  `json:"id"`
+		RewardID   string  `json:"reward_id"`
+		Code       string  `json:"code"`
+		Sum        float64 `json:"sum"`
+		CreateDate string  `json:"processed_at"`
+	}{
+		ID:         e.ID,
+		RewardID:   e.RewardID,
+		Code:       e.Code,
+		Sum:        e.Sum,
+		CreateDate: e.CreateDate.Format(time.RFC3339),
 	})
 }
 
@@ -60,11 +181,12 @@ type Order struct {
 	CreateDate     time.Time
 	LastModifyDate time.Time
 	Status         string
-	Username       string
+	UserID         string
 	Accrual        float64
 	KeyHash        int64
 	KeyHashModule  int64
 	Version        int64
+	AttemptCount   int
 }
 
 func (e *Order) MarshalJSON() ([]byte, error) {
@@ -80,3 +202,240 @@ func (e *Order) MarshalJSON() ([]byte, error) {
 		Accrual:     e.Accrual,
 	})
 }
+
+type OrderAccrual struct {
+	Order   Order
+	Status  string
+	Accrual float64
+}
+
+type AccrualCallback struct {
+	OrderNumber string  `json:"order"`
+	Status      string  `json:"status"`
+	Accrual     float64 `json:"accrual"`
+}
+
+type DeadLetterOrder struct {
+	OrderNumber    string
+	UserID         string
+	Status         string
+	AttemptCount   int
+	LastError      string
+	DeadLetterDate time.Time
+}
+
+func (e *DeadLetterOrder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		OrderNumber    string `json:"number"`
+		UserID         string `json:"user_id"`
+		Status         string `json:"status"`
+		AttemptCount   int    `json:"attempt_count"`
+		LastError      string `json:"last_error"`
+		DeadLetterDate string `json:"dead_lettered_at"`
+	}{
+		OrderNumber:    e.OrderNumber,
+		UserID:         e.UserID,
+		Status:         e.Status,
+		AttemptCount:   e.AttemptCount,
+		LastError:      e.LastError,
+		DeadLetterDate: e.DeadLetterDate.Format(time.RFC3339),
+	})
+}
+
+type OrderStatusTransition struct {
+	Status     string
+	Accrual    *float64
+	CreateDate time.Time
+}
+
+func (e *OrderStatusTransition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Status     string   `json:"status"`
+		Accrual    *float64 `json:"accrual,omitempty"`
+		CreateDate string   `json:"changed_at"`
+	}{
+		Status:     e.Status,
+		Accrual:    e.Accrual,
+		CreateDate: e.CreateDate.Format(time.RFC3339),
+	})
+}
+
+type OrderWithHistory struct {
+	Order   Order
+	History []OrderStatusTransition
+}
+
+func (e *OrderWithHistory) MarshalJSON() ([]byte, error) {
+	history := make([]*OrderStatusTransition, 0, len(e.History))
+	for i := range e.History {
+		history = append(history, &e.History[i])
+	}
+
+	return json.Marshal(&struct {
+		OrderNumber string                   `json:"number"`
+		CreateDate  string                   `json:"uploaded_at"`
+		Status      string                   `json:"status"`
+		Accrual     float64                  `json:"accrual"`
+		History     []*OrderStatusTransition `json:"history"`
+	}{
+		OrderNumber: e.Order.OrderNumber,
+		CreateDate:  e.Order.CreateDate.Format(time.RFC3339),
+		Status:      e.Order.Status,
+		Accrual:     e.Order.Accrual,
+		History:     history,
+	})
+}
+
+type OrderUploadResult struct {
+	OrderNumber string `json:"number"`
+	Result      string `json:"result"`
+}
+
+type Transaction struct {
+	Type        string
+	OrderNumber string
+	Status      string
+	Amount      float64
+	CreateDate  time.Time
+}
+
+func (e *Transaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Type        string  `json:"type"`
+		OrderNumber string  `json:"order"`
+		Status      string  `json:"status,omitempty"`
+		Amount      float64 `json:"amount"`
+		CreateDate  string  `json:"processed_at"`
+	}{
+		Type:        e.Type,
+		OrderNumber: e.OrderNumber,
+		Status:      e.Status,
+		Amount:      e.Amount,
+		CreateDate:  e.CreateDate.Format(time.RFC3339),
+	})
+}
+
+type Statement struct {
+	ID             string
+	UserID         string
+	Username       string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	OpeningBalance float64
+	Accrued        float64
+	Withdrawn      float64
+	ClosingBalance float64
+	PdfKey         string
+	CsvKey         string
+	CreateDate     time.Time
+}
+
+func (e *Statement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID             string  `json:"id"`
+		PeriodStart    string  `json:"period_start"`
+		PeriodEnd      string  `json:"period_end"`
+		OpeningBalance float64 `json:"opening_balance"`
+		Accrued        float64 `json:"accrued"`
+		Withdrawn      float64 `json:"withdrawn"`
+		ClosingBalance float64 `json:"closing_balance"`
+		PdfURL         string  `json:"pdf_url"`
+		CsvURL         string  `json:"csv_url"`
+		CreateDate     string  `json:"created_at"`
+	}{
+		ID:             e.ID,
+		PeriodStart:    e.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:      e.PeriodEnd.Format(time.RFC3339),
+		OpeningBalance: e.OpeningBalance,
+		Accrued:        e.Accrued,
+		Withdrawn:      e.Withdrawn,
+		ClosingBalance: e.ClosingBalance,
+		PdfURL:         "/api/user/statements/" + e.ID + "/pdf",
+		CsvURL:         "/api/user/statements/" + e.ID + "/csv",
+		CreateDate:     e.CreateDate.Format(time.RFC3339),
+	})
+}
+
+type AccountDeletion struct {
+	UserID      string
+	Settlement  string
+	PayoutID    string
+	DeleteAfter time.Time
+}
+
+func (e *AccountDeletion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		DeleteAfter string `json:"delete_after"`
+		Settlement  string `json:"balance_settlement"`
+		PayoutID    string `json:"payout_id,omitempty"`
+	}{
+		DeleteAfter: e.DeleteAfter.Format(time.RFC3339),
+		Settlement:  e.Settlement,
+		PayoutID:    e.PayoutID,
+	})
+}
+
+type UserProfile struct {
+	ID          string     `json:"id"`
+	Username    string     `json:"login"`
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+}
+
+type UserData struct {
+	Profile     UserProfile
+	Balance     Balance
+	Orders      []OrderWithHistory
+	Withdrawals []Withdrawal
+	Holds       []Hold
+	Redemptions []Redemption
+	Statements  []Statement
+	Identifiers []UserIdentifier
+}
+
+type UserIdentifier struct {
+	ID             string
+	UserID         string
+	Type           string
+	Value          string
+	Verified       bool
+	CodeHash       string
+	CodeExpireDate time.Time
+	AttemptCount   int
+	CreateDate     time.Time
+}
+
+func (e *UserIdentifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID         string `json:"id"`
+		Type       string `json:"type"`
+		Value      string `json:"value"`
+		Verified   bool   `json:"verified"`
+		CreateDate string `json:"created_at"`
+	}{
+		ID:         e.ID,
+		Type:       e.Type,
+		Value:      e.Value,
+		Verified:   e.Verified,
+		CreateDate: e.CreateDate.Format(time.RFC3339),
+	})
+}
+
+type WorkerStatus struct {
+	Name          string     `json:"name"`
+	Running       bool       `json:"running"`
+	Shards        []int      `json:"shards,omitempty"`
+	LastPollDate  *time.Time `json:"last_poll_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorDate *time.Time `json:"last_error_at,omitempty"`
+}
+
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+type SystemStatus struct {
+	Readiness
+	Workers  []WorkerStatus    `json:"workers"`
+	Circuits map[string]string `json:"circuits,omitempty"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 1000
+)
+
+type PageRequest struct {
+	Limit    int
+	Cursor   *PageCursor
+	Statuses []string
+	From     *time.Time
+	To       *time.Time
+	Desc     bool
+}
+
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+type PageCursor struct {
+	CreateDate time.Time
+	Key        string
+}
+
+func (c PageCursor) Encode() string {
+	raw := c.CreateDate.UTC().Format(time.RFC3339Nano) + "|" + c.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodePageCursor(value string) (PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return PageCursor{}, ErrPageRequestIsNotValid
+	}
+
+	createDate, key, found := strings.Cut(string(raw), "|")
+	if !found || key == "" {
+		return PageCursor{}, ErrPageRequestIsNotValid
+	}
+
+	date, err := time.Parse(time.RFC3339Nano, createDate)
+	if err != nil {
+		return PageCursor{}, ErrPageRequestIsNotValid
+	}
+
+	return PageCursor{CreateDate: date, Key: key}, nil
+}
@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+)
+
+type LogNotifier struct {
+	logger *zap.Logger
+}
+
+func NewLogNotifier(logger *zap.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) SendVerificationCode(ctx context.Context, identifier model.UserIdentifier, code string) error {
+	n.logger.Info("Verification code",
+		zap.String("type", identifier.Type),
+		zap.String("value", identifier.Value),
+		zap.String("code", code))
+	return nil
+}
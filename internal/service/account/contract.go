@@ -0,0 +1,15 @@
+package account
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"time"
+)
+
+type accountRepository interface {
+	RequestDeletion(ctx context.Context, deletion model.AccountDeletion) error
+
+	CancelDeletion(ctx context.Context, userName string, now time.Time) error
+
+	FindUserData(ctx context.Context, userName string) (model.UserData, error)
+}
@@ -0,0 +1,80 @@
+package account
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type AccountService struct {
+	logger            *zap.Logger
+	accountRepository accountRepository
+}
+
+func NewAccountService(l *zap.Logger, r accountRepository) *AccountService {
+	return &AccountService{logger: l, accountRepository: r}
+}
+
+func (s *AccountService) RequestDeletion(ctx context.Context, settlement string) (model.AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.RequestDeletion")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	deletion := model.AccountDeletion{
+		UserID:      currentUserID,
+		Settlement:  strings.ToUpper(settlement),
+		DeleteAfter: time.Now().Add(service.AccountDeletionGracePeriod),
+	}
+
+	switch deletion.Settlement {
+	case "", model.ForfeitBalanceSettlement:
+		deletion.Settlement = model.ForfeitBalanceSettlement
+	case model.PayoutBalanceSettlement:
+		payoutID, err := uuid.NewRandom()
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error("Error during generate UUID", zap.Error(err))
+			return model.AccountDeletion{}, err
+		}
+		deletion.PayoutID = payoutID.String()
+	default:
+		return model.AccountDeletion{}, model.ErrBalanceSettlementIsNotValid
+	}
+
+	if err := s.accountRepository.RequestDeletion(ctx, deletion); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during request account deletion", zap.String("userID", currentUserID), zap.Error(err))
+		return model.AccountDeletion{}, err
+	}
+
+	return deletion, nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "AccountService.CancelDeletion")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	if err := s.accountRepository.CancelDeletion(ctx, currentUserID, time.Now()); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during cancel account deletion", zap.String("userID", currentUserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *AccountService) ExportData(ctx context.Context) (model.UserData, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.ExportData")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	data, err := s.accountRepository.FindUserData(ctx, currentUserID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find user data", zap.String("userID", currentUserID), zap.Error(err))
+		return model.UserData{}, err
+	}
+	return data, nil
+}
@@ -0,0 +1,123 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) RequestDeletion(ctx context.Context, deletion model.AccountDeletion) error {
+	args := m.Called(ctx, deletion)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) CancelDeletion(ctx context.Context, userName string, now time.Time) error {
+	args := m.Called(ctx, userName, now)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) FindUserData(ctx context.Context, userName string) (model.UserData, error) {
+	args := m.Called(ctx, userName)
+	return args.Get(0).(model.UserData), args.Error(1)
+}
+
+func TestAccountService_RequestDeletion(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return error if settlement is not valid", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		_, err := accountService.RequestDeletion(ctx, "donate")
+		assert.Equal(t, model.ErrBalanceSettlementIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "RequestDeletion", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should forfeit balance by default", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		mockRepo.On("RequestDeletion", ctx, mock.AnythingOfType("model.AccountDeletion")).Return(nil)
+
+		before := time.Now()
+		deletion, err := accountService.RequestDeletion(ctx, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "testUser", deletion.UserID)
+		assert.Equal(t, model.ForfeitBalanceSettlement, deletion.Settlement)
+		assert.Empty(t, deletion.PayoutID)
+		assert.False(t, deletion.DeleteAfter.Before(before.Add(service.AccountDeletionGracePeriod)))
+	})
+
+	t.Run("should generate payout reference", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		mockRepo.On("RequestDeletion", ctx, mock.AnythingOfType("model.AccountDeletion")).Return(nil)
+
+		deletion, err := accountService.RequestDeletion(ctx, "payout")
+		assert.NoError(t, err)
+		assert.Equal(t, model.PayoutBalanceSettlement, deletion.Settlement)
+		assert.NotEmpty(t, deletion.PayoutID)
+	})
+
+	t.Run("should return error if deletion is already requested", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		mockRepo.On("RequestDeletion", ctx, mock.AnythingOfType("model.AccountDeletion")).Return(model.ErrAccountDeletionIsAlreadyRequested)
+
+		_, err := accountService.RequestDeletion(ctx, "forfeit")
+		assert.Equal(t, model.ErrAccountDeletionIsAlreadyRequested, err)
+	})
+}
+
+func TestAccountService_CancelDeletion(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	mockRepo := new(MockAccountRepository)
+	accountService := NewAccountService(logger, mockRepo)
+
+	mockRepo.On("CancelDeletion", ctx, "testUser", mock.AnythingOfType("time.Time")).Return(model.ErrAccountDeletionWasNotFound)
+
+	err := accountService.CancelDeletion(ctx)
+	assert.Equal(t, model.ErrAccountDeletionWasNotFound, err)
+}
+
+func TestAccountService_ExportData(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return user data", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		data := model.UserData{Profile: model.UserProfile{Username: "testUser"}}
+		mockRepo.On("FindUserData", ctx, "testUser").Return(data, nil)
+
+		result, err := accountService.ExportData(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+	})
+
+	t.Run("should return error if repository return error", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		accountService := NewAccountService(logger, mockRepo)
+
+		mockRepo.On("FindUserData", ctx, "testUser").Return(model.UserData{}, errors.New("db error"))
+
+		_, err := accountService.ExportData(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+package accountworker
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"time"
+)
+
+type accountRepository interface {
+	FindAccountsToDelete(ctx context.Context, now time.Time, limit int) ([]model.AccountDeletion, error)
+
+	DeleteAccount(ctx context.Context, deletion model.AccountDeletion, anonymousName string, now time.Time) ([]string, error)
+}
+
+type documentStore interface {
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,74 @@
+package accountworker
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	batchSize = 100
+)
+
+type Worker struct {
+	logger            *zap.Logger
+	accountRepository accountRepository
+	documentStore     documentStore
+	interval          time.Duration
+}
+
+func NewWorker(logger *zap.Logger, repository accountRepository, store documentStore, interval time.Duration) *Worker {
+	return &Worker{
+		logger:            logger,
+		accountRepository: repository,
+		documentStore:     store,
+		interval:          interval,
+	}
+}
+
+func (w *Worker) DeleteAccounts(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.deleteAccounts(ctx, time.Now())
+		}
+	}
+}
+
+func (w *Worker) deleteAccounts(ctx context.Context, now time.Time) {
+	deletions, err := w.accountRepository.FindAccountsToDelete(ctx, now, batchSize)
+	if err != nil {
+		w.logger.Error("Error during fetch accounts to delete", zap.Error(err))
+		return
+	}
+
+	for _, deletion := range deletions {
+		anonymousID, err := uuid.NewRandom()
+		if err != nil {
+			w.logger.Error("Error during generate UUID", zap.Error(err))
+			return
+		}
+
+		documentKeys, err := w.accountRepository.DeleteAccount(ctx, deletion, "deleted-"+anonymousID.String(), now)
+		if err != nil {
+			if !errors.Is(err, model.ErrAccountDeletionWasNotFound) {
+				w.logger.Error("Error during delete account", zap.String("userID", deletion.UserID), zap.Error(err))
+			}
+			continue
+		}
+
+		for _, key := range documentKeys {
+			if err := w.documentStore.Delete(ctx, key); err != nil {
+				w.logger.Error("Error during delete document", zap.String("key", key), zap.Error(err))
+			}
+		}
+		w.logger.Info("Account deleted", zap.String("settlement", deletion.Settlement))
+	}
+}
@@ -0,0 +1,76 @@
+package accountworker
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) FindAccountsToDelete(ctx context.Context, now time.Time, limit int) ([]model.AccountDeletion, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]model.AccountDeletion), args.Error(1)
+}
+
+func (m *MockAccountRepository) DeleteAccount(ctx context.Context, deletion model.AccountDeletion, anonymousName string, now time.Time) ([]string, error) {
+	args := m.Called(ctx, deletion, anonymousName, now)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockDocumentStore struct {
+	mock.Mock
+}
+
+func (m *MockDocumentStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func TestWorker_deleteAccounts(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	now := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should delete accounts and their documents", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		mockStore := new(MockDocumentStore)
+
+		first := model.AccountDeletion{UserID: "first", Settlement: model.ForfeitBalanceSettlement}
+		second := model.AccountDeletion{UserID: "second", Settlement: model.PayoutBalanceSettlement, PayoutID: "1"}
+		mockRepo.On("FindAccountsToDelete", ctx, now, batchSize).Return([]model.AccountDeletion{first, second}, nil)
+		mockRepo.On("DeleteAccount", ctx, first, mock.AnythingOfType("string"), now).Return([]string{"statements/1.pdf", "statements/1.csv"}, nil).
+			Run(func(args mock.Arguments) {
+				assert.True(t, strings.HasPrefix(args.String(2), "deleted-"))
+			})
+		mockRepo.On("DeleteAccount", ctx, second, mock.AnythingOfType("string"), now).Return([]string(nil), errors.New("db error"))
+		mockStore.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+		worker := NewWorker(logger, mockRepo, mockStore, time.Hour)
+		worker.deleteAccounts(ctx, now)
+
+		mockRepo.AssertNumberOfCalls(t, "DeleteAccount", 2)
+		mockStore.AssertCalled(t, "Delete", ctx, "statements/1.pdf")
+		mockStore.AssertCalled(t, "Delete", ctx, "statements/1.csv")
+	})
+
+	t.Run("should not delete anything if fetch return error", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		mockStore := new(MockDocumentStore)
+
+		mockRepo.On("FindAccountsToDelete", ctx, now, batchSize).Return([]model.AccountDeletion{}, errors.New("db error"))
+
+		worker := NewWorker(logger, mockRepo, mockStore, time.Hour)
+		worker.deleteAccounts(ctx, now)
+
+		mockRepo.AssertNotCalled(t, "DeleteAccount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
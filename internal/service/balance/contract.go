@@ -3,6 +3,7 @@ package balance
 import (
 	"context"
 	"github.com/desepticon55/gofemart/internal/model"
+	"time"
 )
 
 type balanceRepository interface {
@@ -11,4 +12,12 @@ type balanceRepository interface {
 	FindBalanceStats(ctx context.Context, userName string) (model.BalanceStats, error)
 
 	Withdraw(ctx context.Context, balance model.Balance, sum float64, orderNumber string) error
+
+	Authorize(ctx context.Context, balance model.Balance, sum float64, orderNumber string, ttl time.Duration) (model.Hold, error)
+
+	FindHold(ctx context.Context, holdID string) (model.Hold, error)
+
+	Capture(ctx context.Context, hold model.Hold) error
+
+	Void(ctx context.Context, hold model.Hold, status string) error
 }
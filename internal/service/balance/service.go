@@ -2,56 +2,157 @@ package balance
 
 import (
 	"context"
-	"fmt"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type BalanceService struct {
 	logger            *zap.Logger
 	balanceRepository balanceRepository
+	metrics           metrics.Metrics
 }
 
-func NewBalanceService(l *zap.Logger, r balanceRepository) *BalanceService {
-	return &BalanceService{logger: l, balanceRepository: r}
+func NewBalanceService(l *zap.Logger, r balanceRepository, m metrics.Metrics) *BalanceService {
+	return &BalanceService{logger: l, balanceRepository: r, metrics: m}
 }
 
 func (s *BalanceService) FindBalanceStats(ctx context.Context) (model.BalanceStats, error) {
-	currentUserName := fmt.Sprintf("%v", ctx.Value(service.UserNameContextKey))
-	balance, err := s.balanceRepository.FindBalanceStats(ctx, currentUserName)
+	ctx, span := tracing.Start(ctx, "BalanceService.FindBalanceStats")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	balance, err := s.balanceRepository.FindBalanceStats(ctx, currentUserID)
 	if err != nil {
-		s.logger.Error("Error during fetch balance", zap.String("userName", currentUserName), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during fetch balance", zap.String("userID", currentUserID), zap.Error(err))
 		return model.BalanceStats{}, err
 	}
 	return balance, nil
 }
 
 func (s *BalanceService) Withdraw(ctx context.Context, orderNumber string, sum float64) error {
+	ctx, span := tracing.Start(ctx, "BalanceService.Withdraw")
+	defer span.End()
+
 	if orderNumber == "" || sum == 0 {
 		return model.ErrOrderNumberOrSumIsNotFilled
 	}
 
-	currentUserName := fmt.Sprintf("%v", ctx.Value(service.UserNameContextKey))
+	currentUserID := service.CurrentUserID(ctx)
 	if !service.IsValidOrderNumber(orderNumber) {
 		return model.ErrOrderNumberIsNotValid
 	}
 
-	balance, err := s.balanceRepository.FindBalance(ctx, currentUserName)
+	balance, err := s.balanceRepository.FindBalance(ctx, currentUserID)
 	if err != nil {
-		s.logger.Error("Error during fetch balance", zap.String("userName", currentUserName), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during fetch balance", zap.String("userID", currentUserID), zap.Error(err))
 		return err
 	}
 
-	if balance.Balance < sum {
+	if balance.Spendable() < sum {
 		return model.ErrUserBalanceLessThanSumToWithdraw
 	}
 
 	err = s.balanceRepository.Withdraw(ctx, balance, sum, orderNumber)
 	if err != nil {
-		s.logger.Error("Error during withdraw", zap.String("userName", currentUserName), zap.String("orderNumber", orderNumber), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during withdraw", zap.String("userID", currentUserID), zap.String("orderNumber", orderNumber), zap.Error(err))
 		return err
 	}
 
+	s.metrics.AddPointsWithdrawn(sum)
 	return nil
 }
+
+func (s *BalanceService) Authorize(ctx context.Context, orderNumber string, sum float64) (model.Hold, error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.Authorize")
+	defer span.End()
+
+	if orderNumber == "" || sum <= 0 {
+		return model.Hold{}, model.ErrOrderNumberOrSumIsNotFilled
+	}
+
+	if !service.IsValidOrderNumber(orderNumber) {
+		return model.Hold{}, model.ErrOrderNumberIsNotValid
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	balance, err := s.balanceRepository.FindBalance(ctx, currentUserID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during fetch balance", zap.String("userID", currentUserID), zap.Error(err))
+		return model.Hold{}, err
+	}
+
+	if balance.Spendable() < sum {
+		return model.Hold{}, model.ErrUserBalanceLessThanSumToWithdraw
+	}
+
+	hold, err := s.balanceRepository.Authorize(ctx, balance, sum, orderNumber, service.HoldTTL)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during authorize", zap.String("userID", currentUserID), zap.String("orderNumber", orderNumber), zap.Error(err))
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (s *BalanceService) Capture(ctx context.Context, holdID string) error {
+	ctx, span := tracing.Start(ctx, "BalanceService.Capture")
+	defer span.End()
+
+	hold, err := s.findCurrentUserHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.balanceRepository.Capture(ctx, hold); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during capture", zap.String("holdID", holdID), zap.Error(err))
+		return err
+	}
+
+	s.metrics.AddPointsWithdrawn(hold.Sum)
+	return nil
+}
+
+func (s *BalanceService) Void(ctx context.Context, holdID string) error {
+	ctx, span := tracing.Start(ctx, "BalanceService.Void")
+	defer span.End()
+
+	hold, err := s.findCurrentUserHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.balanceRepository.Void(ctx, hold, model.VoidedHoldStatus); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during void", zap.String("holdID", holdID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (s *BalanceService) findCurrentUserHold(ctx context.Context, holdID string) (model.Hold, error) {
+	if _, err := uuid.Parse(holdID); err != nil {
+		return model.Hold{}, model.ErrHoldWasNotFound
+	}
+
+	hold, err := s.balanceRepository.FindHold(ctx, holdID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find hold", zap.String("holdID", holdID), zap.Error(err))
+		return model.Hold{}, err
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	if hold.UserID != currentUserID {
+		return model.Hold{}, model.ErrHoldWasNotFound
+	}
+
+	if hold.Status != model.AuthorizedHoldStatus {
+		return model.Hold{}, model.ErrHoldIsNotAuthorized
+	}
+
+	return hold, nil
+}
@@ -3,12 +3,14 @@ package balance
 import (
 	"context"
 	"errors"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
 	service2 "github.com/desepticon55/gofemart/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
 	"testing"
+	"time"
 )
 
 type MockBalanceRepository struct {
@@ -30,15 +32,36 @@ func (m *MockBalanceRepository) Withdraw(ctx context.Context, balance model.Bala
 	return args.Error(0)
 }
 
+func (m *MockBalanceRepository) Authorize(ctx context.Context, balance model.Balance, sum float64, orderNumber string, ttl time.Duration) (model.Hold, error) {
+	args := m.Called(ctx, balance, sum, orderNumber, ttl)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockBalanceRepository) FindHold(ctx context.Context, holdID string) (model.Hold, error) {
+	args := m.Called(ctx, holdID)
+	return args.Get(0).(model.Hold), args.Error(1)
+}
+
+func (m *MockBalanceRepository) Capture(ctx context.Context, hold model.Hold) error {
+	args := m.Called(ctx, hold)
+	return args.Error(0)
+}
+
+func (m *MockBalanceRepository) Void(ctx context.Context, hold model.Hold, status string) error {
+	args := m.Called(ctx, hold, status)
+	return args.Error(0)
+}
+
 func TestBalanceService_FindBalanceStats(t *testing.T) {
 	t.Run("should return error if fetch balance return error", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
 		mockRepo.On("FindBalanceStats", ctx, "testUser").Return(model.BalanceStats{}, errors.New("db error"))
@@ -52,14 +75,15 @@ func TestBalanceService_FindBalanceStats(t *testing.T) {
 	t.Run("should return balance stats successfully", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
-		expectedStats := model.BalanceStats{Username: "testUser", Balance: 1000, Withdrawn: 500}
+		expectedStats := model.BalanceStats{UserID: "testUser", Balance: 1000, Withdrawn: 500}
 		mockRepo.On("FindBalanceStats", ctx, "testUser").Return(expectedStats, nil)
 
 		stats, err := service.FindBalanceStats(ctx)
@@ -72,11 +96,12 @@ func TestBalanceService_Withdraw(t *testing.T) {
 	t.Run("should return error if order number is empty or sum is zero", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
 		err := service.Withdraw(ctx, "", 0)
@@ -87,11 +112,12 @@ func TestBalanceService_Withdraw(t *testing.T) {
 	t.Run("should return error if order number is invalid", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
 		orderNumber := "invalid"
@@ -104,11 +130,12 @@ func TestBalanceService_Withdraw(t *testing.T) {
 	t.Run("should return error if fetch balance return error", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
 		orderNumber := "12345678903"
@@ -122,15 +149,16 @@ func TestBalanceService_Withdraw(t *testing.T) {
 	t.Run("should return error if balance is less than sum to withdraw", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
 		orderNumber := "12345678903"
-		mockRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{Username: "testUser", Balance: 50}, nil)
+		mockRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{UserID: "testUser", Balance: 50}, nil)
 
 		err := service.Withdraw(ctx, orderNumber, 100)
 		assert.Error(t, err)
@@ -140,17 +168,154 @@ func TestBalanceService_Withdraw(t *testing.T) {
 	t.Run("should successfully withdraw", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockBalanceRepository)
-		ctx := context.WithValue(context.Background(), service2.UserNameContextKey, "testUser")
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
 
 		service := &BalanceService{
 			logger:            logger,
 			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
 		}
 
-		mockRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{Username: "testUser", Balance: 200}, nil)
-		mockRepo.On("Withdraw", ctx, model.Balance{Username: "testUser", Balance: 200}, 100.0, "12345678903").Return(nil)
+		mockRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{UserID: "testUser", Balance: 200}, nil)
+		mockRepo.On("Withdraw", ctx, model.Balance{UserID: "testUser", Balance: 200}, 100.0, "12345678903").Return(nil)
 
 		err := service.Withdraw(ctx, "12345678903", 100.0)
 		assert.NoError(t, err)
 	})
 }
+
+func TestBalanceService_Authorize(t *testing.T) {
+	t.Run("should return error if spendable balance is less than sum", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		mockRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{UserID: "testUser", Balance: 200, Reserved: 150}, nil)
+
+		_, err := service.Authorize(ctx, "12345678903", 100)
+		assert.Error(t, err)
+		assert.Equal(t, model.ErrUserBalanceLessThanSumToWithdraw, err)
+	})
+
+	t.Run("should successfully authorize", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		balance := model.Balance{UserID: "testUser", Balance: 200, Reserved: 50}
+		expectedHold := model.Hold{ID: "1", UserID: "testUser", OrderNumber: "12345678903", Sum: 100, Status: model.AuthorizedHoldStatus}
+		mockRepo.On("FindBalance", ctx, "testUser").Return(balance, nil)
+		mockRepo.On("Authorize", ctx, balance, 100.0, "12345678903", service2.HoldTTL).Return(expectedHold, nil)
+
+		hold, err := service.Authorize(ctx, "12345678903", 100)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedHold, hold)
+	})
+}
+
+const testHoldID = "5c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+
+func TestBalanceService_Capture(t *testing.T) {
+	t.Run("should return error if hold id is not valid", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		err := service.Capture(ctx, "not-a-uuid")
+		assert.Equal(t, model.ErrHoldWasNotFound, err)
+		mockRepo.AssertNotCalled(t, "FindHold", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if hold belongs to other user", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		mockRepo.On("FindHold", ctx, testHoldID).Return(model.Hold{ID: testHoldID, UserID: "otherUser", Status: model.AuthorizedHoldStatus}, nil)
+
+		err := service.Capture(ctx, testHoldID)
+		assert.Equal(t, model.ErrHoldWasNotFound, err)
+		mockRepo.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if hold was voided", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		mockRepo.On("FindHold", ctx, testHoldID).Return(model.Hold{ID: testHoldID, UserID: "testUser", Status: model.VoidedHoldStatus}, nil)
+
+		err := service.Capture(ctx, testHoldID)
+		assert.Equal(t, model.ErrHoldIsNotAuthorized, err)
+	})
+
+	t.Run("should successfully capture", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		hold := model.Hold{ID: testHoldID, UserID: "testUser", Sum: 100, Status: model.AuthorizedHoldStatus}
+		mockRepo.On("FindHold", ctx, testHoldID).Return(hold, nil)
+		mockRepo.On("Capture", ctx, hold).Return(nil)
+
+		err := service.Capture(ctx, testHoldID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestBalanceService_Void(t *testing.T) {
+	t.Run("should successfully void", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockBalanceRepository)
+		ctx := service2.WithPrincipal(context.Background(), service2.Principal{UserID: "testUser", Username: "testUser"})
+
+		service := &BalanceService{
+			logger:            logger,
+			balanceRepository: mockRepo,
+			metrics:           metrics.NewNoopMetrics(),
+		}
+
+		hold := model.Hold{ID: testHoldID, UserID: "testUser", Sum: 100, Status: model.AuthorizedHoldStatus}
+		mockRepo.On("FindHold", ctx, testHoldID).Return(hold, nil)
+		mockRepo.On("Void", ctx, hold, model.VoidedHoldStatus).Return(nil)
+
+		err := service.Void(ctx, testHoldID)
+		assert.NoError(t, err)
+	})
+}
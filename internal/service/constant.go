@@ -1,8 +1,17 @@
 package service
 
+import "time"
+
 type ContextKey string
 
 const (
-	UserNameContextKey ContextKey = "userName"
-	Module             int        = 256
+	Module                  int = 256
+	MaxOrderBatchSize       int = 1000
+	MaxVerificationAttempts int = 5
+)
+
+const (
+	HoldTTL                    = 15 * time.Minute
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	VerificationCodeTTL        = 15 * time.Minute
 )
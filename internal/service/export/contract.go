@@ -0,0 +1,11 @@
+package export
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"time"
+)
+
+type exportRepository interface {
+	StreamTransactions(ctx context.Context, userName string, from, to *time.Time, consumer func(model.Transaction) error) error
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ExportService struct {
+	logger           *zap.Logger
+	exportRepository exportRepository
+}
+
+func NewExportService(l *zap.Logger, r exportRepository) *ExportService {
+	return &ExportService{logger: l, exportRepository: r}
+}
+
+func (s *ExportService) ExportTransactions(ctx context.Context, from, to *time.Time, consumer func(model.Transaction) error) error {
+	ctx, span := tracing.Start(ctx, "ExportService.ExportTransactions")
+	defer span.End()
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return model.ErrExportRequestIsNotValid
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	err := s.exportRepository.StreamTransactions(ctx, currentUserID, from, to, consumer)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during export transactions", zap.String("userID", currentUserID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) StreamTransactions(ctx context.Context, userName string, from, to *time.Time, consumer func(model.Transaction) error) error {
+	args := m.Called(ctx, userName, from, to, consumer)
+	return args.Error(0)
+}
+
+func TestExportService_ExportTransactions(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return error if period is not valid", func(t *testing.T) {
+		mockRepo := new(MockExportRepository)
+		exportService := &ExportService{logger: logger, exportRepository: mockRepo}
+
+		from := time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+		err := exportService.ExportTransactions(ctx, &from, &to, func(model.Transaction) error { return nil })
+		assert.Equal(t, model.ErrExportRequestIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "StreamTransactions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should stream transactions of current user", func(t *testing.T) {
+		mockRepo := new(MockExportRepository)
+		exportService := &ExportService{logger: logger, exportRepository: mockRepo}
+
+		transaction := model.Transaction{Type: model.WithdrawalTransactionType, OrderNumber: "12345678903", Amount: 10}
+		mockRepo.On("StreamTransactions", ctx, "testUser", (*time.Time)(nil), (*time.Time)(nil), mock.Anything).
+			Return(nil).
+			Run(func(args mock.Arguments) {
+				consumer := args.Get(4).(func(model.Transaction) error)
+				assert.NoError(t, consumer(transaction))
+			})
+
+		var result []model.Transaction
+		err := exportService.ExportTransactions(ctx, nil, nil, func(transaction model.Transaction) error {
+			result = append(result, transaction)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Transaction{transaction}, result)
+	})
+
+	t.Run("should return error if repository return error", func(t *testing.T) {
+		mockRepo := new(MockExportRepository)
+		exportService := &ExportService{logger: logger, exportRepository: mockRepo}
+
+		mockRepo.On("StreamTransactions", ctx, "testUser", (*time.Time)(nil), (*time.Time)(nil), mock.Anything).Return(errors.New("db error"))
+
+		err := exportService.ExportTransactions(ctx, nil, nil, func(model.Transaction) error { return nil })
+		assert.Error(t, err)
+		assert.Equal(t, "db error", err.Error())
+	})
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type AccrualProbe struct {
+	client    *http.Client
+	address   string
+	ttl       time.Duration
+	mu        sync.Mutex
+	checkDate time.Time
+	lastErr   error
+}
+
+func NewAccrualProbe(client *http.Client, address string, ttl time.Duration) *AccrualProbe {
+	return &AccrualProbe{client: client, address: address, ttl: ttl}
+}
+
+func (p *AccrualProbe) Check(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.checkDate.IsZero() && time.Since(p.checkDate) < p.ttl {
+		return p.lastErr
+	}
+
+	p.lastErr = p.check(ctx)
+	p.checkDate = time.Now()
+	return p.lastErr
+}
+
+func (p *AccrualProbe) check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address, nil)
+	if err != nil {
+		return fmt.Errorf("error during create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("accrual system is unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("accrual system responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/breaker"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type healthRepository interface {
+	Ping(ctx context.Context) error
+
+	FindMigrationVersion(ctx context.Context) (int64, error)
+}
+
+type accrualProbe interface {
+	Check(ctx context.Context) error
+}
+
+type circuitBreaker interface {
+	Name() string
+
+	State() breaker.State
+}
+
+type workerStatuses interface {
+	Workers() []model.WorkerStatus
+}
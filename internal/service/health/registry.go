@@ -0,0 +1,82 @@
+package health
+
+import (
+	"github.com/desepticon55/gofemart/internal/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+type WorkerRegistry struct {
+	mu      sync.RWMutex
+	workers map[string]*model.WorkerStatus
+}
+
+func NewWorkerRegistry() *WorkerRegistry {
+	return &WorkerRegistry{workers: make(map[string]*model.WorkerStatus)}
+}
+
+func (r *WorkerRegistry) Register(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers[name] = &model.WorkerStatus{Name: name}
+}
+
+func (r *WorkerRegistry) Started(name string) {
+	r.update(name, func(status *model.WorkerStatus) {
+		status.Running = true
+	})
+}
+
+func (r *WorkerRegistry) Stopped(name string) {
+	r.update(name, func(status *model.WorkerStatus) {
+		status.Running = false
+	})
+}
+
+func (r *WorkerRegistry) ReportPoll(name string, err error) {
+	r.update(name, func(status *model.WorkerStatus) {
+		now := time.Now()
+		status.LastPollDate = &now
+		if err != nil {
+			status.LastError = err.Error()
+			status.LastErrorDate = &now
+		}
+	})
+}
+
+func (r *WorkerRegistry) ReportError(name string, err error) {
+	r.update(name, func(status *model.WorkerStatus) {
+		now := time.Now()
+		status.LastError = err.Error()
+		status.LastErrorDate = &now
+	})
+}
+
+func (r *WorkerRegistry) ReportShards(name string, shards []int) {
+	r.update(name, func(status *model.WorkerStatus) {
+		status.Shards = shards
+	})
+}
+
+func (r *WorkerRegistry) Workers() []model.WorkerStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workers := make([]model.WorkerStatus, 0, len(r.workers))
+	for _, status := range r.workers {
+		workers = append(workers, *status)
+	}
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].Name < workers[j].Name
+	})
+	return workers
+}
+
+func (r *WorkerRegistry) update(name string, fn func(status *model.WorkerStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if status, ok := r.workers[name]; ok {
+		fn(status)
+	}
+}
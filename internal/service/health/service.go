@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	okCheck            = "ok"
+	checkTimeout       = 2 * time.Second
+	workerStallTimeout = 5 * time.Minute
+)
+
+type HealthService struct {
+	logger           *zap.Logger
+	healthRepository healthRepository
+	accrualProbe     accrualProbe
+	workers          workerStatuses
+	circuits         []circuitBreaker
+	migrationVersion int64
+	shuttingDown     atomic.Bool
+}
+
+func NewHealthService(l *zap.Logger, r healthRepository, p accrualProbe, w workerStatuses, migrationVersion int64, c ...circuitBreaker) *HealthService {
+	return &HealthService{logger: l, healthRepository: r, accrualProbe: p, workers: w, migrationVersion: migrationVersion, circuits: c}
+}
+
+func (s *HealthService) Shutdown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *HealthService) Ready(ctx context.Context) model.Readiness {
+	if s.shuttingDown.Load() {
+		return model.Readiness{Ready: false, Checks: map[string]string{"shutdown": "in progress"}}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	checks := map[string]string{
+		"database":   s.checkDatabase(ctx),
+		"migrations": s.checkMigrations(ctx),
+		"accrual":    s.checkAccrual(ctx),
+		"workers":    s.checkWorkers(),
+	}
+
+	ready := true
+	for name, check := range checks {
+		if check != okCheck {
+			s.logger.Warn("Readiness check failed", zap.String("check", name), zap.String("reason", check))
+			ready = false
+		}
+	}
+	return model.Readiness{Ready: ready, Checks: checks}
+}
+
+func (s *HealthService) Status(ctx context.Context) model.SystemStatus {
+	status := model.SystemStatus{Readiness: s.Ready(ctx), Workers: s.workers.Workers()}
+	if len(s.circuits) > 0 {
+		status.Circuits = make(map[string]string, len(s.circuits))
+		for _, circuit := range s.circuits {
+			status.Circuits[circuit.Name()] = circuit.State().String()
+		}
+	}
+	return status
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) string {
+	if err := s.healthRepository.Ping(ctx); err != nil {
+		return err.Error()
+	}
+	return okCheck
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) string {
+	version, err := s.healthRepository.FindMigrationVersion(ctx)
+	if err != nil {
+		return err.Error()
+	}
+	if version < s.migrationVersion {
+		return fmt.Sprintf("applied version %d, expected %d", version, s.migrationVersion)
+	}
+	return okCheck
+}
+
+func (s *HealthService) checkAccrual(ctx context.Context) string {
+	if err := s.accrualProbe.Check(ctx); err != nil {
+		return err.Error()
+	}
+	return okCheck
+}
+
+func (s *HealthService) checkWorkers() string {
+	for _, worker := range s.workers.Workers() {
+		if !worker.Running {
+			return fmt.Sprintf("worker %s is not running", worker.Name)
+		}
+		if worker.LastPollDate != nil && time.Since(*worker.LastPollDate) > workerStallTimeout {
+			return fmt.Sprintf("worker %s has not polled since %s", worker.Name, worker.LastPollDate.Format(time.RFC3339))
+		}
+	}
+	return okCheck
+}
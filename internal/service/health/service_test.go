@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/breaker"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockHealthRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockHealthRepository) FindMigrationVersion(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockAccrualProbe struct {
+	mock.Mock
+}
+
+func (m *MockAccrualProbe) Check(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should be ready if all checks passed", func(t *testing.T) {
+		mockRepo := new(MockHealthRepository)
+		mockRepo.On("Ping", mock.Anything).Return(nil)
+		mockRepo.On("FindMigrationVersion", mock.Anything).Return(int64(17), nil)
+		mockProbe := new(MockAccrualProbe)
+		mockProbe.On("Check", mock.Anything).Return(nil)
+		registry := NewWorkerRegistry()
+		registry.Register("gophermart-1")
+		registry.Started("gophermart-1")
+
+		readiness := NewHealthService(logger, mockRepo, mockProbe, registry, 17).Ready(context.Background())
+
+		assert.True(t, readiness.Ready)
+		assert.Equal(t, map[string]string{"database": "ok", "migrations": "ok", "accrual": "ok", "workers": "ok"}, readiness.Checks)
+	})
+
+	t.Run("should not be ready if dependencies are not available", func(t *testing.T) {
+		mockRepo := new(MockHealthRepository)
+		mockRepo.On("Ping", mock.Anything).Return(errors.New("connection refused"))
+		mockRepo.On("FindMigrationVersion", mock.Anything).Return(int64(16), nil)
+		mockProbe := new(MockAccrualProbe)
+		mockProbe.On("Check", mock.Anything).Return(errors.New("accrual system is unreachable"))
+		registry := NewWorkerRegistry()
+		registry.Register("gophermart-1")
+
+		readiness := NewHealthService(logger, mockRepo, mockProbe, registry, 17).Ready(context.Background())
+
+		assert.False(t, readiness.Ready)
+		assert.Equal(t, "connection refused", readiness.Checks["database"])
+		assert.Equal(t, "applied version 16, expected 17", readiness.Checks["migrations"])
+		assert.Equal(t, "accrual system is unreachable", readiness.Checks["accrual"])
+		assert.Equal(t, "worker gophermart-1 is not running", readiness.Checks["workers"])
+	})
+
+	t.Run("should not be ready during shutdown", func(t *testing.T) {
+		mockRepo := new(MockHealthRepository)
+		service := NewHealthService(logger, mockRepo, new(MockAccrualProbe), NewWorkerRegistry(), 17)
+		service.Shutdown()
+
+		readiness := service.Ready(context.Background())
+
+		assert.False(t, readiness.Ready)
+		mockRepo.AssertNotCalled(t, "Ping", mock.Anything)
+	})
+}
+
+func TestHealthService_Status(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	mockRepo := new(MockHealthRepository)
+	mockRepo.On("Ping", mock.Anything).Return(nil)
+	mockRepo.On("FindMigrationVersion", mock.Anything).Return(int64(17), nil)
+	mockProbe := new(MockAccrualProbe)
+	mockProbe.On("Check", mock.Anything).Return(nil)
+	circuit := breaker.NewCircuitBreaker(logger, metrics.NewNoopMetrics(), "accrual",
+		breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	circuit.Failure()
+
+	status := NewHealthService(logger, mockRepo, mockProbe, NewWorkerRegistry(), 17, circuit).Status(context.Background())
+
+	assert.Equal(t, map[string]string{"accrual": "open"}, status.Circuits)
+}
+
+func TestWorkerRegistry(t *testing.T) {
+	registry := NewWorkerRegistry()
+	registry.Register("gophermart-2")
+	registry.Register("gophermart-1")
+	registry.Started("gophermart-1")
+	registry.ReportPoll("gophermart-1", nil)
+	registry.ReportError("gophermart-1", errors.New("error during send event"))
+	registry.ReportPoll("unknown", nil)
+
+	workers := registry.Workers()
+
+	assert.Equal(t, 2, len(workers))
+	assert.Equal(t, "gophermart-1", workers[0].Name)
+	assert.True(t, workers[0].Running)
+	assert.NotNil(t, workers[0].LastPollDate)
+	assert.Equal(t, "error during send event", workers[0].LastError)
+	assert.False(t, workers[1].Running)
+	assert.Nil(t, workers[1].LastPollDate)
+}
+
+func TestAccrualProbe_Check(t *testing.T) {
+	var callCount int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	probe := NewAccrualProbe(server.Client(), server.URL, time.Hour)
+
+	assert.Error(t, probe.Check(context.Background()))
+	assert.Error(t, probe.Check(context.Background()))
+	assert.Equal(t, 1, callCount)
+}
@@ -0,0 +1,12 @@
+package holdworker
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type holdRepository interface {
+	FindExpiredHolds(ctx context.Context, limit int) ([]model.Hold, error)
+
+	Void(ctx context.Context, hold model.Hold, status string) error
+}
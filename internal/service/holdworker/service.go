@@ -0,0 +1,59 @@
+package holdworker
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	batchSize = 100
+)
+
+type Worker struct {
+	logger         *zap.Logger
+	holdRepository holdRepository
+	interval       time.Duration
+}
+
+func NewWorker(logger *zap.Logger, repository holdRepository, interval time.Duration) *Worker {
+	return &Worker{
+		logger:         logger,
+		holdRepository: repository,
+		interval:       interval,
+	}
+}
+
+func (w *Worker) ExpireHolds(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.expireHolds(ctx)
+		}
+	}
+}
+
+func (w *Worker) expireHolds(ctx context.Context) {
+	holds, err := w.holdRepository.FindExpiredHolds(ctx, batchSize)
+	if err != nil {
+		w.logger.Error("Error during fetch expired holds", zap.Error(err))
+		return
+	}
+
+	for _, hold := range holds {
+		err := w.holdRepository.Void(ctx, hold, model.ExpiredHoldStatus)
+		if err != nil {
+			if !errors.Is(err, model.ErrHoldIsNotAuthorized) {
+				w.logger.Error("Error during expire hold", zap.String("holdID", hold.ID), zap.Error(err))
+			}
+			continue
+		}
+		w.logger.Debug("Hold expired", zap.String("holdID", hold.ID), zap.String("userID", hold.UserID))
+	}
+}
@@ -0,0 +1,61 @@
+package holdworker
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockHoldRepository) FindExpiredHolds(ctx context.Context, limit int) ([]model.Hold, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) Void(ctx context.Context, hold model.Hold, status string) error {
+	args := m.Called(ctx, hold, status)
+	return args.Error(0)
+}
+
+func TestWorker_ExpireHolds(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should expire found holds", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		mockRepo := new(MockHoldRepository)
+
+		first := model.Hold{ID: "1", UserID: "testUser", Sum: 10}
+		second := model.Hold{ID: "2", UserID: "testUser", Sum: 20}
+		mockRepo.On("FindExpiredHolds", ctx, batchSize).Return([]model.Hold{first, second}, nil).Once()
+		mockRepo.On("FindExpiredHolds", ctx, batchSize).Return([]model.Hold{}, nil)
+		mockRepo.On("Void", ctx, first, model.ExpiredHoldStatus).Return(nil)
+		mockRepo.On("Void", ctx, second, model.ExpiredHoldStatus).Return(model.ErrHoldIsNotAuthorized)
+
+		worker := NewWorker(logger, mockRepo, 10*time.Millisecond)
+		worker.ExpireHolds(ctx)
+
+		mockRepo.AssertCalled(t, "Void", ctx, first, model.ExpiredHoldStatus)
+		mockRepo.AssertCalled(t, "Void", ctx, second, model.ExpiredHoldStatus)
+	})
+
+	t.Run("should continue if fetch holds return error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		mockRepo := new(MockHoldRepository)
+
+		mockRepo.On("FindExpiredHolds", ctx, batchSize).Return([]model.Hold{}, errors.New("db error"))
+
+		worker := NewWorker(logger, mockRepo, 10*time.Millisecond)
+		worker.ExpireHolds(ctx)
+
+		mockRepo.AssertNotCalled(t, "Void", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,9 @@
+package intake
+
+import (
+	"context"
+)
+
+type orderRepository interface {
+	ApplyOrderAccrual(ctx context.Context, orderNumber string, status string, accrual float64) error
+}
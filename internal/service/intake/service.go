@@ -0,0 +1,49 @@
+package intake
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"go.uber.org/zap"
+)
+
+type IntakeService struct {
+	logger          *zap.Logger
+	orderRepository orderRepository
+	metrics         metrics.Metrics
+}
+
+func NewIntakeService(l *zap.Logger, r orderRepository, m metrics.Metrics) *IntakeService {
+	return &IntakeService{logger: l, orderRepository: r, metrics: m}
+}
+
+func (s *IntakeService) ApplyAccrual(ctx context.Context, callback model.AccrualCallback) error {
+	ctx, span := tracing.Start(ctx, "IntakeService.ApplyAccrual")
+	defer span.End()
+
+	if !service.IsValidOrderNumber(callback.OrderNumber) {
+		return model.ErrOrderNumberIsNotValid
+	}
+
+	status, err := accrual.OrderStatus(callback.Status)
+	if err != nil || callback.Accrual < 0 {
+		return model.ErrAccrualStatusIsNotValid
+	}
+
+	if err := s.orderRepository.ApplyOrderAccrual(ctx, callback.OrderNumber, status, callback.Accrual); err != nil {
+		if !errors.Is(err, model.ErrOrderWasNotFound) && !errors.Is(err, model.ErrOrderIsAlreadyProcessed) {
+			logging.FromContext(ctx, s.logger).Error("Error during apply accrual", zap.String("orderNumber", callback.OrderNumber), zap.Error(err))
+		}
+		return err
+	}
+
+	if status == model.ProcessedOrderStatus {
+		s.metrics.AddPointsAccrued(callback.Accrual)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package intake
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) ApplyOrderAccrual(ctx context.Context, orderNumber string, status string, accrual float64) error {
+	args := m.Called(ctx, orderNumber, status, accrual)
+	return args.Error(0)
+}
+
+func TestIntakeService_ApplyAccrual(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return error if order number is invalid", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		intakeService := NewIntakeService(logger, mockRepo, metrics.NewNoopMetrics())
+
+		err := intakeService.ApplyAccrual(context.Background(), model.AccrualCallback{OrderNumber: "12345", Status: "PROCESSED"})
+
+		assert.Equal(t, model.ErrOrderNumberIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "ApplyOrderAccrual", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if status is unknown", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		intakeService := NewIntakeService(logger, mockRepo, metrics.NewNoopMetrics())
+
+		err := intakeService.ApplyAccrual(context.Background(), model.AccrualCallback{OrderNumber: "12345678903", Status: "COMPLETED"})
+
+		assert.Equal(t, model.ErrAccrualStatusIsNotValid, err)
+	})
+
+	t.Run("should apply mapped status", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		mockRepo.On("ApplyOrderAccrual", ctx, "12345678903", model.ProcessingOrderStatus, 0.0).Return(nil)
+		intakeService := NewIntakeService(logger, mockRepo, metrics.NewNoopMetrics())
+
+		err := intakeService.ApplyAccrual(ctx, model.AccrualCallback{OrderNumber: "12345678903", Status: "REGISTERED"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if order is already processed", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		mockRepo.On("ApplyOrderAccrual", ctx, "12345678903", model.ProcessedOrderStatus, 500.0).Return(model.ErrOrderIsAlreadyProcessed)
+		intakeService := NewIntakeService(logger, mockRepo, metrics.NewNoopMetrics())
+
+		err := intakeService.ApplyAccrual(ctx, model.AccrualCallback{OrderNumber: "12345678903", Status: "PROCESSED", Accrual: 500})
+
+		assert.Equal(t, model.ErrOrderIsAlreadyProcessed, err)
+	})
+}
@@ -0,0 +1,16 @@
+package membership
+
+import (
+	"context"
+	"time"
+)
+
+type membershipRepository interface {
+	Heartbeat(ctx context.Context, workerID string) error
+
+	FindActiveMembers(ctx context.Context, ttl time.Duration) ([]string, error)
+
+	DeleteExpiredMembers(ctx context.Context, ttl time.Duration) error
+
+	DeleteMember(ctx context.Context, workerID string) error
+}
@@ -0,0 +1,135 @@
+package membership
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const leaveTimeout = 5 * time.Second
+
+type Membership struct {
+	logger               *zap.Logger
+	membershipRepository membershipRepository
+	workerID             string
+	module               int
+	interval             time.Duration
+	ttl                  time.Duration
+	mu                   sync.RWMutex
+	members              []string
+	shards               []int
+}
+
+func NewMembership(logger *zap.Logger, repository membershipRepository, workerID string, module int, interval, ttl time.Duration) *Membership {
+	return &Membership{
+		logger:               logger,
+		membershipRepository: repository,
+		workerID:             workerID,
+		module:               module,
+		interval:             interval,
+		ttl:                  ttl,
+	}
+}
+
+func (m *Membership) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			m.leave()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Membership) Shards() []int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.shards
+}
+
+func (m *Membership) refresh(ctx context.Context) {
+	if err := m.membershipRepository.Heartbeat(ctx, m.workerID); err != nil {
+		m.logger.Error("Error during send heartbeat", zap.String("workerID", m.workerID), zap.Error(err))
+		return
+	}
+
+	if err := m.membershipRepository.DeleteExpiredMembers(ctx, m.ttl); err != nil {
+		m.logger.Error("Error during delete expired members", zap.Error(err))
+	}
+
+	members, err := m.membershipRepository.FindActiveMembers(ctx, m.ttl)
+	if err != nil {
+		m.logger.Error("Error during find active members", zap.Error(err))
+		return
+	}
+
+	shards := AssignShards(members, m.workerID, m.module)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Equal(m.members, members) {
+		m.logger.Info("Worker membership changed",
+			zap.String("workerID", m.workerID), zap.Strings("members", members), zap.Int("shards", len(shards)))
+	}
+	m.members = members
+	m.shards = shards
+}
+
+func (m *Membership) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), leaveTimeout)
+	defer cancel()
+
+	if err := m.membershipRepository.DeleteMember(ctx, m.workerID); err != nil {
+		m.logger.Error("Error during leave membership", zap.String("workerID", m.workerID), zap.Error(err))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members = nil
+	m.shards = nil
+}
+
+func AssignShards(members []string, workerID string, module int) []int {
+	var shards []int
+	for shard := 0; shard < module; shard++ {
+		var owner string
+		var maxWeight uint64
+		for _, member := range members {
+			if w := weight(member, shard); owner == "" || w > maxWeight || (w == maxWeight && member < owner) {
+				owner = member
+				maxWeight = w
+			}
+		}
+
+		if owner == workerID {
+			shards = append(shards, shard)
+		}
+	}
+	return shards
+}
+
+func weight(member string, shard int) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(member))
+	_, _ = hash.Write([]byte{'/'})
+	_, _ = hash.Write([]byte(strconv.Itoa(shard)))
+
+	// fnv alone spreads similar keys poorly, the splitmix64 finalizer fixes that
+	x := hash.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,108 @@
+package membership
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockMembershipRepository struct {
+	mock.Mock
+}
+
+func (m *MockMembershipRepository) Heartbeat(ctx context.Context, workerID string) error {
+	args := m.Called(ctx, workerID)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) FindActiveMembers(ctx context.Context, ttl time.Duration) ([]string, error) {
+	args := m.Called(ctx, ttl)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMembershipRepository) DeleteExpiredMembers(ctx context.Context, ttl time.Duration) error {
+	args := m.Called(ctx, ttl)
+	return args.Error(0)
+}
+
+func (m *MockMembershipRepository) DeleteMember(ctx context.Context, workerID string) error {
+	args := m.Called(ctx, workerID)
+	return args.Error(0)
+}
+
+func TestAssignShards(t *testing.T) {
+	const module = 256
+	members := []string{"replica-a-0", "replica-a-1", "replica-b-0", "replica-b-1"}
+
+	owners := make(map[int]string)
+	for _, member := range members {
+		shards := AssignShards(members, member, module)
+		assert.Greater(t, len(shards), module/len(members)/2, "shards should be spread evenly")
+		for _, shard := range shards {
+			_, assigned := owners[shard]
+			assert.False(t, assigned, "shard %d is assigned twice", shard)
+			owners[shard] = member
+		}
+	}
+	assert.Equal(t, module, len(owners))
+
+	remaining := members[:3]
+	for _, member := range remaining {
+		for _, shard := range AssignShards(remaining, member, module) {
+			if owners[shard] != "replica-b-1" {
+				assert.Equal(t, owners[shard], member, "shard %d should stay with its owner", shard)
+			}
+		}
+	}
+
+	assert.Empty(t, AssignShards(members, "unknown", module))
+}
+
+func TestMembership_Run(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should own all shards when alone and leave on stop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo := new(MockMembershipRepository)
+		mockRepo.On("Heartbeat", ctx, "worker-1").Return(nil)
+		mockRepo.On("DeleteExpiredMembers", ctx, time.Minute).Return(nil)
+		mockRepo.On("FindActiveMembers", ctx, time.Minute).Return([]string{"worker-1"}, nil)
+		mockRepo.On("DeleteMember", mock.Anything, "worker-1").Return(nil)
+
+		membership := NewMembership(logger, mockRepo, "worker-1", 8, time.Hour, time.Minute)
+		done := make(chan struct{})
+		go func() {
+			membership.Run(ctx)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return len(membership.Shards()) == 8
+		}, time.Second, 10*time.Millisecond)
+
+		cancel()
+		<-done
+		assert.Empty(t, membership.Shards())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should keep shards if heartbeat failed", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockMembershipRepository)
+		mockRepo.On("Heartbeat", ctx, "worker-1").Return(nil).Once()
+		mockRepo.On("DeleteExpiredMembers", ctx, time.Minute).Return(nil)
+		mockRepo.On("FindActiveMembers", ctx, time.Minute).Return([]string{"worker-1", "worker-2"}, nil)
+		mockRepo.On("Heartbeat", ctx, "worker-1").Return(errors.New("connection refused"))
+
+		membership := NewMembership(logger, mockRepo, "worker-1", 8, time.Hour, time.Minute)
+		membership.refresh(ctx)
+		shards := membership.Shards()
+		membership.refresh(ctx)
+
+		assert.Equal(t, shards, membership.Shards())
+	})
+}
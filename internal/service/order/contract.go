@@ -12,5 +12,17 @@ type orderRepository interface {
 
 	CreateOrder(ctx context.Context, order model.Order) error
 
+	CreateOrders(ctx context.Context, orders []model.Order) ([]string, error)
+
+	FindOrderOwners(ctx context.Context, orderNumbers []string) (map[string]string, error)
+
 	FindAllOrders(ctx context.Context, userName string) ([]model.Order, error)
+
+	FindOrdersPage(ctx context.Context, userName string, page model.PageRequest) (model.Page[model.Order], error)
+
+	FindOrderHistory(ctx context.Context, orderNumber string) ([]model.OrderStatusTransition, error)
+
+	FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error)
+
+	ReplayDeadLetterOrder(ctx context.Context, orderNumber string) error
 }
@@ -2,9 +2,11 @@ package order
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/logging"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
 	"go.uber.org/zap"
 	"math"
 	"time"
@@ -20,45 +22,39 @@ func NewOrderService(l *zap.Logger, r orderRepository) *OrderService {
 }
 
 func (s *OrderService) UploadOrder(ctx context.Context, orderNumber string) error {
+	ctx, span := tracing.Start(ctx, "OrderService.UploadOrder")
+	defer span.End()
+
 	if orderNumber == "" {
-		s.logger.Error("Order number is not filled")
+		logging.FromContext(ctx, s.logger).Error("Order number is not filled")
 		return model.ErrOrderNumberIsNotFilled
 	}
 
 	if !service.IsValidOrderNumber(orderNumber) {
-		s.logger.Error("Order number is not valid", zap.String("orderNumber", orderNumber))
+		logging.FromContext(ctx, s.logger).Error("Order number is not valid", zap.String("orderNumber", orderNumber))
 		return model.ErrOrderNumberIsNotValid
 	}
 
 	exist, err := s.orderRepository.ExistOrder(ctx, orderNumber)
 	if err != nil {
-		s.logger.Error("Error during check exist order", zap.String("orderNumber", orderNumber), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during check exist order", zap.String("orderNumber", orderNumber), zap.Error(err))
 		return err
 	}
 
-	currentUserName := fmt.Sprintf("%v", ctx.Value(service.UserNameContextKey))
+	currentUserID := service.CurrentUserID(ctx)
 	if !exist {
-		keyHash := int64(math.Abs(float64(service.HashCode(orderNumber))))
-		err := s.orderRepository.CreateOrder(ctx, model.Order{
-			OrderNumber:    orderNumber,
-			Username:       currentUserName,
-			CreateDate:     time.Now(),
-			LastModifyDate: time.Now(),
-			Status:         model.NewOrderStatus,
-			KeyHash:        keyHash,
-			KeyHashModule:  keyHash % int64(service.Module),
-		})
+		err := s.orderRepository.CreateOrder(ctx, newOrder(orderNumber, currentUserID, time.Now()))
 		if err != nil {
-			s.logger.Error("Error during create order", zap.String("orderNumber", orderNumber), zap.Error(err))
+			logging.FromContext(ctx, s.logger).Error("Error during create order", zap.String("orderNumber", orderNumber), zap.Error(err))
 			return err
 		}
 	} else {
 		order, err := s.orderRepository.FindOrder(ctx, orderNumber)
 		if err != nil {
-			s.logger.Error("Error during find order", zap.String("orderNumber", orderNumber), zap.Error(err))
+			logging.FromContext(ctx, s.logger).Error("Error during find order", zap.String("orderNumber", orderNumber), zap.Error(err))
 			return err
 		}
-		if currentUserName == order.Username {
+		if currentUserID == order.UserID {
 			return model.ErrOrderNumberHasUploadedCurrentUser
 		} else {
 			return model.ErrOrderNumberHasUploadedOtherUser
@@ -69,10 +65,13 @@ func (s *OrderService) UploadOrder(ctx context.Context, orderNumber string) erro
 }
 
 func (s *OrderService) FindAllOrders(ctx context.Context) ([]model.Order, error) {
-	currentUserName := fmt.Sprintf("%v", ctx.Value(service.UserNameContextKey))
-	orders, err := s.orderRepository.FindAllOrders(ctx, currentUserName)
+	ctx, span := tracing.Start(ctx, "OrderService.FindAllOrders")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	orders, err := s.orderRepository.FindAllOrders(ctx, currentUserID)
 	if err != nil {
-		s.logger.Error("Error during find orders", zap.String("userName", currentUserName), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during find orders", zap.String("userID", currentUserID), zap.Error(err))
 		return nil, err
 	}
 
@@ -82,3 +81,173 @@ func (s *OrderService) FindAllOrders(ctx context.Context) ([]model.Order, error)
 
 	return orders, nil
 }
+
+func (s *OrderService) FindOrdersPage(ctx context.Context, page model.PageRequest) (model.Page[model.Order], error) {
+	ctx, span := tracing.Start(ctx, "OrderService.FindOrdersPage")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	result, err := s.orderRepository.FindOrdersPage(ctx, currentUserID, page)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find orders page", zap.String("userID", currentUserID), zap.Error(err))
+		return model.Page[model.Order]{}, err
+	}
+
+	if len(result.Items) == 0 {
+		return model.Page[model.Order]{}, model.ErrOrdersWasNotFound
+	}
+
+	return result, nil
+}
+
+func (s *OrderService) FindOrder(ctx context.Context, orderNumber string) (model.OrderWithHistory, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.FindOrder")
+	defer span.End()
+
+	if !service.IsValidOrderNumber(orderNumber) {
+		return model.OrderWithHistory{}, model.ErrOrderNumberIsNotValid
+	}
+
+	order, err := s.orderRepository.FindOrder(ctx, orderNumber)
+	if err != nil {
+		if !errors.Is(err, model.ErrOrderWasNotFound) {
+			logging.FromContext(ctx, s.logger).Error("Error during find order", zap.String("orderNumber", orderNumber), zap.Error(err))
+		}
+		return model.OrderWithHistory{}, err
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	if order.UserID != currentUserID {
+		return model.OrderWithHistory{}, model.ErrOrderWasNotFound
+	}
+
+	history, err := s.orderRepository.FindOrderHistory(ctx, orderNumber)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find order history", zap.String("orderNumber", orderNumber), zap.Error(err))
+		return model.OrderWithHistory{}, err
+	}
+
+	return model.OrderWithHistory{Order: order, History: history}, nil
+}
+
+func (s *OrderService) FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.FindDeadLetterOrders")
+	defer span.End()
+
+	orders, err := s.orderRepository.FindDeadLetterOrders(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find dead-lettered orders", zap.Error(err))
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, model.ErrDeadLetterOrdersWasNotFound
+	}
+
+	return orders, nil
+}
+
+func (s *OrderService) ReplayOrder(ctx context.Context, orderNumber string) error {
+	ctx, span := tracing.Start(ctx, "OrderService.ReplayOrder")
+	defer span.End()
+
+	if !service.IsValidOrderNumber(orderNumber) {
+		return model.ErrOrderNumberIsNotValid
+	}
+
+	if err := s.orderRepository.ReplayDeadLetterOrder(ctx, orderNumber); err != nil {
+		if !errors.Is(err, model.ErrDeadLetterOrderWasNotFound) {
+			logging.FromContext(ctx, s.logger).Error("Error during replay order", zap.String("orderNumber", orderNumber), zap.Error(err))
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *OrderService) UploadOrders(ctx context.Context, orderNumbers []string) ([]model.OrderUploadResult, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UploadOrders")
+	defer span.End()
+
+	if len(orderNumbers) == 0 {
+		return nil, model.ErrOrderNumberIsNotFilled
+	}
+
+	if len(orderNumbers) > service.MaxOrderBatchSize {
+		return nil, model.ErrOrderBatchIsTooLarge
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	now := time.Now()
+	results := make([]model.OrderUploadResult, len(orderNumbers))
+	seen := make(map[string]bool, len(orderNumbers))
+	var orders []model.Order
+	var validNumbers []string
+	for i, orderNumber := range orderNumbers {
+		results[i].OrderNumber = orderNumber
+		if !service.IsValidOrderNumber(orderNumber) {
+			results[i].Result = model.InvalidUploadResult
+			continue
+		}
+
+		if seen[orderNumber] {
+			continue
+		}
+		seen[orderNumber] = true
+		orders = append(orders, newOrder(orderNumber, currentUserID, now))
+		validNumbers = append(validNumbers, orderNumber)
+	}
+
+	created, err := s.orderRepository.CreateOrders(ctx, orders)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during create orders", zap.String("userID", currentUserID), zap.Error(err))
+		return nil, err
+	}
+
+	accepted := make(map[string]bool, len(created))
+	for _, orderNumber := range created {
+		accepted[orderNumber] = true
+	}
+
+	owners := make(map[string]string)
+	if len(created) < len(validNumbers) {
+		owners, err = s.orderRepository.FindOrderOwners(ctx, validNumbers)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error("Error during find order owners", zap.String("userID", currentUserID), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	for i := range results {
+		if results[i].Result != "" {
+			continue
+		}
+
+		orderNumber := results[i].OrderNumber
+		switch {
+		case accepted[orderNumber]:
+			results[i].Result = model.AcceptedUploadResult
+			delete(accepted, orderNumber)
+			owners[orderNumber] = currentUserID
+		case owners[orderNumber] == currentUserID:
+			results[i].Result = model.DuplicateOwnUploadResult
+		default:
+			results[i].Result = model.ConflictOtherUserUploadResult
+		}
+	}
+
+	return results, nil
+}
+
+func newOrder(orderNumber, userName string, now time.Time) model.Order {
+	keyHash := int64(math.Abs(float64(service.HashCode(orderNumber))))
+	return model.Order{
+		OrderNumber:    orderNumber,
+		UserID:         userName,
+		CreateDate:     now,
+		LastModifyDate: now,
+		Status:         model.NewOrderStatus,
+		KeyHash:        keyHash,
+		KeyHashModule:  keyHash % int64(service.Module),
+	}
+}
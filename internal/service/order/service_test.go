@@ -37,12 +37,42 @@ func (m *MockOrderRepository) FindAllOrders(ctx context.Context, userName string
 	return args.Get(0).([]model.Order), args.Error(1)
 }
 
+func (m *MockOrderRepository) FindOrdersPage(ctx context.Context, userName string, page model.PageRequest) (model.Page[model.Order], error) {
+	args := m.Called(ctx, userName, page)
+	return args.Get(0).(model.Page[model.Order]), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindOrderHistory(ctx context.Context, orderNumber string) ([]model.OrderStatusTransition, error) {
+	args := m.Called(ctx, orderNumber)
+	return args.Get(0).([]model.OrderStatusTransition), args.Error(1)
+}
+
+func (m *MockOrderRepository) CreateOrders(ctx context.Context, orders []model.Order) ([]string, error) {
+	args := m.Called(ctx, orders)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindOrderOwners(ctx context.Context, orderNumbers []string) (map[string]string, error) {
+	args := m.Called(ctx, orderNumbers)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.DeadLetterOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) ReplayDeadLetterOrder(ctx context.Context, orderNumber string) error {
+	args := m.Called(ctx, orderNumber)
+	return args.Error(0)
+}
+
 func TestOrderService_UploadOrder(t *testing.T) {
 
 	t.Run("should return error if order number is empty", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -57,7 +87,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	t.Run("should return error if order number is invalid", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -73,7 +103,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	t.Run("should return error because check exist order return error", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -92,7 +122,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	t.Run("should create order", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -108,7 +138,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 			order := args.Get(1).(model.Order)
 
 			assert.Equal(t, orderNumber, order.OrderNumber)
-			assert.Equal(t, "testUser", order.Username)
+			assert.Equal(t, "testUser", order.UserID)
 			assert.Equal(t, model.NewOrderStatus, order.Status)
 			assert.Equal(t, keyHash, order.KeyHash)
 			assert.Equal(t, keyHash%int64(service.Module), order.KeyHashModule)
@@ -124,7 +154,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	t.Run("should return error if order exists to current user", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -134,7 +164,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 		orderNumber := "12345678903"
 		existingOrder := model.Order{
 			OrderNumber: orderNumber,
-			Username:    "testUser",
+			UserID:      "testUser",
 		}
 
 		mockRepo.On("ExistOrder", ctx, orderNumber).Return(true, nil)
@@ -148,7 +178,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	t.Run("should return error if order exists to another user", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -158,7 +188,7 @@ func TestOrderService_UploadOrder(t *testing.T) {
 		orderNumber := "12345678903"
 		existingOrder := model.Order{
 			OrderNumber: orderNumber,
-			Username:    "otherUser",
+			UserID:      "otherUser",
 		}
 
 		mockRepo.On("ExistOrder", ctx, orderNumber).Return(true, nil)
@@ -174,7 +204,7 @@ func TestOrderService_FindAllOrders(t *testing.T) {
 	t.Run("should return error if there is an error during find orders", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -192,7 +222,7 @@ func TestOrderService_FindAllOrders(t *testing.T) {
 	t.Run("should return error if no orders are found", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -210,7 +240,7 @@ func TestOrderService_FindAllOrders(t *testing.T) {
 	t.Run("should return orders successfully", func(t *testing.T) {
 		logger := zaptest.NewLogger(t)
 		mockRepo := new(MockOrderRepository)
-		ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 
 		orderService := &OrderService{
 			logger:          logger,
@@ -228,3 +258,229 @@ func TestOrderService_FindAllOrders(t *testing.T) {
 		assert.Equal(t, orders, resultOrders)
 	})
 }
+
+func TestOrderService_FindOrdersPage(t *testing.T) {
+	t.Run("should return error if page is empty", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		page := model.PageRequest{Limit: 10}
+		mockRepo.On("FindOrdersPage", ctx, "testUser", page).Return(model.Page[model.Order]{}, nil)
+
+		_, err := orderService.FindOrdersPage(ctx, page)
+		assert.Equal(t, model.ErrOrdersWasNotFound, err)
+	})
+
+	t.Run("should return page successfully", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		page := model.PageRequest{Limit: 1, Statuses: []string{model.NewOrderStatus}}
+		expected := model.Page[model.Order]{Items: []model.Order{{OrderNumber: "12345"}}, NextCursor: "next"}
+		mockRepo.On("FindOrdersPage", ctx, "testUser", page).Return(expected, nil)
+
+		result, err := orderService.FindOrdersPage(ctx, page)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+}
+
+func TestOrderService_FindOrder(t *testing.T) {
+	t.Run("should return error if order number is invalid", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		_, err := orderService.FindOrder(ctx, "invalid")
+		assert.Equal(t, model.ErrOrderNumberIsNotValid, err)
+	})
+
+	t.Run("should return error if order belongs to another user", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		mockRepo.On("FindOrder", ctx, "12345678903").Return(model.Order{OrderNumber: "12345678903", UserID: "otherUser"}, nil)
+
+		_, err := orderService.FindOrder(ctx, "12345678903")
+		assert.Equal(t, model.ErrOrderWasNotFound, err)
+		mockRepo.AssertNotCalled(t, "FindOrderHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return order with history", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		order := model.Order{OrderNumber: "12345678903", UserID: "testUser", Status: model.ProcessingOrderStatus}
+		history := []model.OrderStatusTransition{{Status: model.NewOrderStatus}, {Status: model.ProcessingOrderStatus}}
+		mockRepo.On("FindOrder", ctx, "12345678903").Return(order, nil)
+		mockRepo.On("FindOrderHistory", ctx, "12345678903").Return(history, nil)
+
+		result, err := orderService.FindOrder(ctx, "12345678903")
+		assert.NoError(t, err)
+		assert.Equal(t, model.OrderWithHistory{Order: order, History: history}, result)
+	})
+}
+
+func TestOrderService_ReplayOrder(t *testing.T) {
+	t.Run("should return error if order number is invalid", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := context.Background()
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		err := orderService.ReplayOrder(ctx, "invalid")
+		assert.Equal(t, model.ErrOrderNumberIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "ReplayDeadLetterOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if order is not dead-lettered", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := context.Background()
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		mockRepo.On("ReplayDeadLetterOrder", ctx, "12345678903").Return(model.ErrDeadLetterOrderWasNotFound)
+
+		err := orderService.ReplayOrder(ctx, "12345678903")
+		assert.Equal(t, model.ErrDeadLetterOrderWasNotFound, err)
+	})
+
+	t.Run("should replay dead-lettered order", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := context.Background()
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		mockRepo.On("ReplayDeadLetterOrder", ctx, "12345678903").Return(nil)
+
+		err := orderService.ReplayOrder(ctx, "12345678903")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestOrderService_UploadOrders(t *testing.T) {
+	t.Run("should return error if batch is empty", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		_, err := orderService.UploadOrders(ctx, nil)
+		assert.Equal(t, model.ErrOrderNumberIsNotFilled, err)
+	})
+
+	t.Run("should return error if batch is too large", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		_, err := orderService.UploadOrders(ctx, make([]string, service.MaxOrderBatchSize+1))
+		assert.Equal(t, model.ErrOrderBatchIsTooLarge, err)
+	})
+
+	t.Run("should return result per order", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		orderNumbers := []string{"12345678903", "invalid", "79927398713", "4561261212345467", "12345678903"}
+		validNumbers := []string{"12345678903", "79927398713", "4561261212345467"}
+		mockRepo.On("CreateOrders", ctx, mock.AnythingOfType("[]model.Order")).Return([]string{"12345678903"}, nil).Run(func(args mock.Arguments) {
+			orders := args.Get(1).([]model.Order)
+			assert.Equal(t, 3, len(orders))
+			for i, order := range orders {
+				assert.Equal(t, validNumbers[i], order.OrderNumber)
+				assert.Equal(t, "testUser", order.UserID)
+				assert.Equal(t, model.NewOrderStatus, order.Status)
+			}
+		})
+		mockRepo.On("FindOrderOwners", ctx, validNumbers).Return(map[string]string{
+			"12345678903":      "testUser",
+			"79927398713":      "testUser",
+			"4561261212345467": "otherUser",
+		}, nil)
+
+		results, err := orderService.UploadOrders(ctx, orderNumbers)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.OrderUploadResult{
+			{OrderNumber: "12345678903", Result: model.AcceptedUploadResult},
+			{OrderNumber: "invalid", Result: model.InvalidUploadResult},
+			{OrderNumber: "79927398713", Result: model.DuplicateOwnUploadResult},
+			{OrderNumber: "4561261212345467", Result: model.ConflictOtherUserUploadResult},
+			{OrderNumber: "12345678903", Result: model.DuplicateOwnUploadResult},
+		}, results)
+	})
+
+	t.Run("should return error if create orders return error", func(t *testing.T) {
+		logger := zaptest.NewLogger(t)
+		mockRepo := new(MockOrderRepository)
+		ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+
+		orderService := &OrderService{
+			logger:          logger,
+			orderRepository: mockRepo,
+		}
+
+		mockRepo.On("CreateOrders", ctx, mock.AnythingOfType("[]model.Order")).Return([]string{}, errors.New("db error"))
+
+		_, err := orderService.UploadOrders(ctx, []string{"12345678903"})
+		assert.Error(t, err)
+		assert.Equal(t, "db error", err.Error())
+	})
+}
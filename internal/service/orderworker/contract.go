@@ -3,10 +3,51 @@ package orderworker
 import (
 	"context"
 	"github.com/desepticon55/gofemart/internal/model"
+	"time"
 )
 
 type orderRepository interface {
-	FindOrdersToProcess(ctx context.Context, from int, to int) ([]model.Order, error)
+	ClaimOrders(ctx context.Context, workerID string, shards []int, limit int, leaseTTL time.Duration, pollDelay time.Duration) ([]model.Order, error)
 
-	ChangeOrderStatus(ctx context.Context, order model.Order, status string, accrual float64) error
+	AckOrder(ctx context.Context, workerID string, order model.Order, status string, accrual float64) error
+
+	AckOrders(ctx context.Context, workerID string, accruals []model.OrderAccrual) (map[string]error, error)
+
+	ReleaseOrder(ctx context.Context, workerID string, order model.Order) error
+
+	RetryOrder(ctx context.Context, workerID string, order model.Order, lastError string, nextAttempt time.Time, deadLetter bool) error
+}
+
+type queueRepository interface {
+	CountOrdersToProcess(ctx context.Context) (map[string]int, error)
+}
+
+type statusRegistry interface {
+	Register(name string)
+
+	Started(name string)
+
+	Stopped(name string)
+
+	ReportPoll(name string, err error)
+
+	ReportError(name string, err error)
+
+	ReportShards(name string, shards []int)
+}
+
+type accrualLimiter interface {
+	Wait(ctx context.Context) error
+
+	Pause(ctx context.Context, duration time.Duration) error
+
+	Adjust(ctx context.Context, requestsPerMinute int) error
+}
+
+type accrualCircuit interface {
+	RetryIn() time.Duration
+}
+
+type shardAssignment interface {
+	Shards() []int
 }
@@ -0,0 +1,47 @@
+package orderworker
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/metrics"
+	"github.com/desepticon55/gofemart/internal/model"
+	"go.uber.org/zap"
+	"time"
+)
+
+type QueueMonitor struct {
+	logger     *zap.Logger
+	repository queueRepository
+	metrics    metrics.Metrics
+	interval   time.Duration
+}
+
+func NewQueueMonitor(logger *zap.Logger, repository queueRepository, metrics metrics.Metrics, interval time.Duration) *QueueMonitor {
+	return &QueueMonitor{logger: logger, repository: repository, metrics: metrics, interval: interval}
+}
+
+func (m *QueueMonitor) ReportQueueDepth(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.reportQueueDepth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *QueueMonitor) reportQueueDepth(ctx context.Context) {
+	counts, err := m.repository.CountOrdersToProcess(ctx)
+	if err != nil {
+		m.logger.Error("Error during count orders to process", zap.Error(err))
+		return
+	}
+
+	for _, status := range []string{model.NewOrderStatus, model.ProcessingOrderStatus} {
+		m.metrics.SetOrderQueueDepth(status, counts[status])
+	}
+}
@@ -2,117 +2,433 @@ package orderworker
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
-	"github.com/gojek/heimdall/v7/httpclient"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
-	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	claimBatchSize = 50
+
+	SingleLookup     = "single"
+	BatchLookup      = "batch"
+	ConcurrentLookup = "concurrent"
+)
+
+type RetryPolicy struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+}
+
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+type LookupPolicy struct {
+	Mode        string
+	BatchSize   int
+	Concurrency int
+}
+
+type lookup struct {
+	order  model.Order
+	result accrual.Result
+	err    error
+}
+
 type Worker struct {
-	from            int
-	to              int
+	id              string
+	leaseTTL        time.Duration
+	pollDelay       time.Duration
+	retryPolicy     RetryPolicy
+	lookupPolicy    LookupPolicy
 	logger          *zap.Logger
-	httpClient      *httpclient.Client
-	limiter         *rate.Limiter
+	accrualClient   accrual.Client
+	limiter         accrualLimiter
+	circuit         accrualCircuit
 	orderRepository orderRepository
-}
+	metrics         metrics.Metrics
+	registry        statusRegistry
+	assignment      shardAssignment
 
-func NewWorker(logger *zap.Logger, repository orderRepository, client *httpclient.Client, from, to int) *Worker {
-	limiter := rate.NewLimiter(rate.Limit(10), 1)
+	batchIsNotSupported atomic.Bool
+}
 
-	logger.Debug("Make worker", zap.Int("from", from), zap.Int("to", to))
+func NewWorker(logger *zap.Logger, repository orderRepository, client accrual.Client, limiter accrualLimiter, circuit accrualCircuit, metrics metrics.Metrics, registry statusRegistry, assignment shardAssignment, id string, leaseTTL time.Duration, pollDelay time.Duration, retryPolicy RetryPolicy, lookupPolicy LookupPolicy) *Worker {
+	logger.Debug("Make worker", zap.String("workerID", id))
+	registry.Register(id)
 	return &Worker{
-		from:            from,
-		to:              to,
-		httpClient:      client,
+		id:              id,
+		leaseTTL:        leaseTTL,
+		pollDelay:       pollDelay,
+		retryPolicy:     retryPolicy,
+		lookupPolicy:    lookupPolicy,
+		accrualClient:   client,
 		logger:          logger,
 		orderRepository: repository,
 		limiter:         limiter,
+		circuit:         circuit,
+		metrics:         metrics,
+		registry:        registry,
+		assignment:      assignment,
 	}
 }
 
-func (w *Worker) ProcessOrders(ctx context.Context, accrualAddress string) {
+func (w *Worker) ProcessOrders(ctx context.Context) {
 	const retryDelay = 5 * time.Second
+	w.registry.Started(w.id)
+	defer w.registry.Stopped(w.id)
+
 	for {
-		orders, err := w.orderRepository.FindOrdersToProcess(ctx, w.from, w.to)
-		if err != nil {
-			w.logger.Error("Error during fetch orders to process", zap.Error(err))
-			time.Sleep(retryDelay)
+		if wait := w.circuit.RetryIn(); wait > 0 {
+			w.logger.Debug("Accrual circuit is open, pause claiming orders", zap.Duration("wait", wait))
+			if !sleep(ctx, wait) {
+				return
+			}
 			continue
 		}
 
-		for _, order := range orders {
-			if err := w.limiter.Wait(ctx); err != nil {
-				w.logger.Error("Error during wait rate limiter", zap.Error(err))
-				time.Sleep(retryDelay)
-				continue
+		shards := w.assignment.Shards()
+		w.registry.ReportShards(w.id, shards)
+		if len(shards) == 0 {
+			if !sleep(ctx, 1*time.Second) {
+				return
 			}
+			continue
+		}
 
-			if err := w.processOrder(ctx, accrualAddress, order); err != nil {
-				w.logger.Error("Error during process order", zap.Error(err))
+		orders, err := w.orderRepository.ClaimOrders(ctx, w.id, shards, claimBatchSize, w.leaseTTL, w.pollDelay)
+		w.registry.ReportPoll(w.id, err)
+		if err != nil {
+			w.logger.Error("Error during claim orders to process", zap.Error(err))
+			if !sleep(ctx, retryDelay) {
+				return
 			}
+			continue
+		}
+		w.reportFetched(orders)
+
+		switch w.lookupPolicy.Mode {
+		case BatchLookup:
+			w.processBatches(ctx, orders)
+		case ConcurrentLookup:
+			w.processConcurrently(ctx, orders)
+		default:
+			w.processSerially(ctx, orders)
+		}
+		if !sleep(ctx, 1*time.Second) {
+			return
 		}
-		time.Sleep(1 * time.Second)
-		continue
 	}
 }
 
-func (w *Worker) processOrder(ctx context.Context, accrualAddress string, order model.Order) error {
-	url := fmt.Sprintf("%s/api/orders/%s", accrualAddress, order.OrderNumber)
-	w.logger.Debug("Accrual address prepared", zap.String("address", url))
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("error during create request: %w", err)
+func (w *Worker) processSerially(ctx context.Context, orders []model.Order) {
+	for i, order := range orders {
+		if w.circuit.RetryIn() > 0 {
+			w.releaseOrders(ctx, orders[i:])
+			return
+		}
+
+		if err := w.limiter.Wait(ctx); err != nil {
+			w.logger.Error("Error during wait rate limiter", zap.Error(err))
+			w.releaseOrders(ctx, orders[i:])
+			return
+		}
+
+		if err := w.processOrder(ctx, order); err != nil {
+			w.failOrder(ctx, order, err)
+		}
+	}
+}
+
+func (w *Worker) processBatches(ctx context.Context, orders []model.Order) {
+	for start := 0; start < len(orders); start += w.lookupPolicy.BatchSize {
+		if w.circuit.RetryIn() > 0 {
+			w.releaseOrders(ctx, orders[start:])
+			return
+		}
+		w.applyLookups(ctx, w.lookupBatch(ctx, orders[start:min(start+w.lookupPolicy.BatchSize, len(orders))]))
 	}
+}
+
+func (w *Worker) processConcurrently(ctx context.Context, orders []model.Order) {
+	lookups := make([]lookup, len(orders))
+	semaphore := make(chan struct{}, w.lookupPolicy.Concurrency)
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		lookups[i].order = order
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(l *lookup) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			l.result, l.err = w.lookupOrder(ctx, l.order)
+		}(&lookups[i])
+	}
+	wg.Wait()
+
+	w.applyLookups(ctx, lookups)
+}
+
+func (w *Worker) reportFetched(orders []model.Order) {
+	counts := make(map[int64]int)
+	for _, order := range orders {
+		counts[order.KeyHashModule]++
+	}
+	for shard, count := range counts {
+		w.metrics.AddOrdersFetched(strconv.FormatInt(shard, 10), count)
+	}
+}
 
-	resp, err := w.httpClient.Do(req)
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (w *Worker) processOrder(ctx context.Context, order model.Order) (err error) {
+	ctx, span := tracing.Start(ctx, "Worker.processOrder", attribute.String("order", order.OrderNumber))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	result, err := w.findOrder(ctx, order.OrderNumber)
+	if errors.Is(err, accrual.ErrNotRegistered) {
+		w.logger.Debug("Order is not registered in accrual system yet", zap.String("orderNumber", order.OrderNumber))
+		w.retryOrder(ctx, order, err.Error())
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("error during send event: %w", err)
+		return err
+	}
+
+	w.logger.Debug(
+		"Received accrual response",
+		zap.String("order", result.OrderNumber),
+		zap.String("status", result.Status),
+		zap.Float64("accrual", result.Accrual))
+
+	if err := w.orderRepository.AckOrder(ctx, w.id, order, result.Status, result.Accrual); err != nil {
+		return fmt.Errorf("error during chage order: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var accrual struct {
-		Order   string  `json:"order"`
-		Status  string  `json:"status"`
-		Accrual float64 `json:"accrual"`
+	if result.Status == model.ProcessedOrderStatus {
+		w.metrics.AddPointsAccrued(result.Accrual)
 	}
+	return nil
+}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		retryAfter := resp.Header.Get("Retry-After")
-		if retryAfter != "" {
-			retryDelay, err := strconv.Atoi(retryAfter)
-			if err != nil {
-				retryDelay = 5
-			}
-			w.logger.Debug(fmt.Sprintf("Received 429, retrying after %d seconds", retryDelay))
-			time.Sleep(time.Duration(retryDelay) * time.Second)
-			return w.processOrder(ctx, accrualAddress, order)
-		}
+func (w *Worker) lookupOrder(ctx context.Context, order model.Order) (result accrual.Result, err error) {
+	ctx, span := tracing.Start(ctx, "Worker.lookupOrder", attribute.String("order", order.OrderNumber))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	if w.circuit.RetryIn() > 0 {
+		return accrual.Result{}, model.ErrAccrualIsUnavailable
+	}
+	if err := w.limiter.Wait(ctx); err != nil {
+		return accrual.Result{}, fmt.Errorf("%w: error during wait rate limiter: %w", model.ErrRateLimiterIsUnavailable, err)
+	}
+	return w.findOrder(ctx, order.OrderNumber)
+}
+
+func (w *Worker) lookupBatch(ctx context.Context, orders []model.Order) []lookup {
+	ctx, span := tracing.Start(ctx, "Worker.lookupBatch", attribute.Int("orders", len(orders)))
+	defer span.End()
+
+	if w.batchIsNotSupported.Load() {
+		return w.lookupEach(ctx, orders)
+	}
+
+	orderNumbers := make([]string, len(orders))
+	for i, order := range orders {
+		orderNumbers[i] = order.OrderNumber
 	}
 
-	if resp.StatusCode == http.StatusOK {
-		err = json.NewDecoder(resp.Body).Decode(&accrual)
+	results, err := w.findOrders(ctx, orderNumbers)
+	if errors.Is(err, accrual.ErrBatchIsNotSupported) {
+		w.logger.Warn("Accrual system does not support batch lookup, look up orders one by one")
+		w.batchIsNotSupported.Store(true)
+		return w.lookupEach(ctx, orders)
+	}
+
+	lookups := make([]lookup, len(orders))
+	for i, order := range orders {
+		lookups[i].order = order
 		if err != nil {
-			return fmt.Errorf("error during decode response: %w", err)
+			lookups[i].err = err
+			continue
 		}
-		w.logger.Debug(
-			"Received accrual response",
-			zap.String("order", accrual.Order),
-			zap.String("status", accrual.Status),
-			zap.Float64("accrual", accrual.Accrual))
 
-		err := w.orderRepository.ChangeOrderStatus(ctx, order, accrual.Status, accrual.Accrual)
-		if err != nil {
-			return fmt.Errorf("error during chage order: %w", err)
+		result, ok := results[order.OrderNumber]
+		if !ok {
+			lookups[i].err = accrual.ErrNotRegistered
+			continue
+		}
+		lookups[i].result = result
+	}
+	return lookups
+}
+
+func (w *Worker) lookupEach(ctx context.Context, orders []model.Order) []lookup {
+	lookups := make([]lookup, len(orders))
+	for i, order := range orders {
+		lookups[i].order = order
+		lookups[i].result, lookups[i].err = w.lookupOrder(ctx, order)
+	}
+	return lookups
+}
+
+func (w *Worker) findOrder(ctx context.Context, orderNumber string) (accrual.Result, error) {
+	result, err := w.accrualClient.FindOrder(ctx, orderNumber)
+	var rateLimited *accrual.ErrRateLimited
+	for errors.As(err, &rateLimited) {
+		if err := w.throttle(ctx, rateLimited); err != nil {
+			return accrual.Result{}, fmt.Errorf("%w: error during throttle accrual requests: %w", model.ErrRateLimiterIsUnavailable, err)
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return accrual.Result{}, fmt.Errorf("%w: error during wait rate limiter: %w", model.ErrRateLimiterIsUnavailable, err)
+		}
+		result, err = w.accrualClient.FindOrder(ctx, orderNumber)
+	}
+	return result, err
+}
+
+func (w *Worker) findOrders(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error) {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("%w: error during wait rate limiter: %w", model.ErrRateLimiterIsUnavailable, err)
+	}
+
+	results, err := w.accrualClient.FindOrders(ctx, orderNumbers)
+	var rateLimited *accrual.ErrRateLimited
+	for errors.As(err, &rateLimited) {
+		if err := w.throttle(ctx, rateLimited); err != nil {
+			return nil, fmt.Errorf("%w: error during throttle accrual requests: %w", model.ErrRateLimiterIsUnavailable, err)
+		}
+		if err := w.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("%w: error during wait rate limiter: %w", model.ErrRateLimiterIsUnavailable, err)
+		}
+		results, err = w.accrualClient.FindOrders(ctx, orderNumbers)
+	}
+	return results, err
+}
+
+func (w *Worker) applyLookups(ctx context.Context, lookups []lookup) {
+	accruals := make([]model.OrderAccrual, 0, len(lookups))
+	for _, l := range lookups {
+		switch {
+		case l.err == nil:
+			w.logger.Debug(
+				"Received accrual response",
+				zap.String("order", l.result.OrderNumber),
+				zap.String("status", l.result.Status),
+				zap.Float64("accrual", l.result.Accrual))
+			accruals = append(accruals, model.OrderAccrual{Order: l.order, Status: l.result.Status, Accrual: l.result.Accrual})
+		case errors.Is(l.err, accrual.ErrNotRegistered):
+			w.logger.Debug("Order is not registered in accrual system yet", zap.String("orderNumber", l.order.OrderNumber))
+			w.retryOrder(ctx, l.order, l.err.Error())
+		default:
+			w.failOrder(ctx, l.order, l.err)
+		}
+	}
+	if len(accruals) == 0 {
+		return
+	}
+
+	failed, err := w.orderRepository.AckOrders(ctx, w.id, accruals)
+	if err != nil {
+		w.logger.Error("Error during change orders", zap.Error(err))
+		w.registry.ReportError(w.id, err)
+		orders := make([]model.Order, len(accruals))
+		for i, a := range accruals {
+			orders[i] = a.Order
 		}
-	} else {
-		w.logger.Debug("Received unsupported status to process", zap.String("Accrual response status", resp.Status))
+		w.releaseOrders(ctx, orders)
+		return
 	}
 
+	for _, a := range accruals {
+		if orderErr, ok := failed[a.Order.OrderNumber]; ok {
+			w.failOrder(ctx, a.Order, fmt.Errorf("error during change order: %w", orderErr))
+			continue
+		}
+
+		if a.Status == model.ProcessedOrderStatus {
+			w.metrics.AddPointsAccrued(a.Accrual)
+		}
+	}
+}
+
+func (w *Worker) failOrder(ctx context.Context, order model.Order, err error) {
+	w.logger.Error("Error during process order", zap.Error(err))
+	w.registry.ReportError(w.id, err)
+	switch {
+	case errors.Is(err, model.ErrOrderLeaseIsLost):
+		// the order is already claimed by another worker
+	case errors.Is(err, model.ErrAccrualIsUnavailable), errors.Is(err, model.ErrRateLimiterIsUnavailable), ctx.Err() != nil:
+		w.releaseOrders(ctx, []model.Order{order})
+	default:
+		w.retryOrder(ctx, order, err.Error())
+	}
+}
+
+func (w *Worker) throttle(ctx context.Context, rateLimited *accrual.ErrRateLimited) error {
+	w.logger.Debug("Received 429, pause accrual requests", zap.Duration("retryAfter", rateLimited.RetryAfter))
+	if err := w.limiter.Pause(ctx, rateLimited.RetryAfter); err != nil {
+		return err
+	}
+
+	if rateLimited.RequestsPerMinute > 0 {
+		return w.limiter.Adjust(ctx, rateLimited.RequestsPerMinute)
+	}
 	return nil
 }
+
+func (w *Worker) releaseOrders(ctx context.Context, orders []model.Order) {
+	for _, order := range orders {
+		if err := w.orderRepository.ReleaseOrder(ctx, w.id, order); err != nil {
+			w.logger.Error("Error during release order", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+		}
+	}
+}
+
+func (w *Worker) retryOrder(ctx context.Context, order model.Order, lastError string) {
+	deadLetter := order.AttemptCount+1 >= w.retryPolicy.MaxAttempts
+	nextAttempt := time.Now().Add(w.retryPolicy.Delay(order.AttemptCount))
+	if err := w.orderRepository.RetryOrder(ctx, w.id, order, lastError, nextAttempt, deadLetter); err != nil {
+		w.logger.Error("Error during schedule order retry", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+		return
+	}
+
+	if deadLetter {
+		w.logger.Warn("Order moved to dead-letter state", zap.String("orderNumber", order.OrderNumber),
+			zap.Int("attempts", order.AttemptCount+1), zap.String("lastError", lastError))
+		w.metrics.AddOrdersDeadLettered(1)
+	}
+}
@@ -2,14 +2,17 @@ package orderworker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/accrual"
+	"github.com/desepticon55/gofemart/internal/accrual/accrualtest"
+	"github.com/desepticon55/gofemart/internal/metrics"
 	"github.com/desepticon55/gofemart/internal/model"
-	"github.com/gojek/heimdall/v7/httpclient"
+	"github.com/desepticon55/gofemart/internal/service/health"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
-	"golang.org/x/time/rate"
-	"net/http"
-	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -18,102 +21,513 @@ type MockOrderRepository struct {
 	mock.Mock
 }
 
-func (m *MockOrderRepository) FindOrdersToProcess(ctx context.Context, from, to int) ([]model.Order, error) {
-	args := m.Called(ctx, from, to)
+func (m *MockOrderRepository) ClaimOrders(ctx context.Context, workerID string, shards []int, limit int, leaseTTL time.Duration, pollDelay time.Duration) ([]model.Order, error) {
+	args := m.Called(ctx, workerID, shards, limit, leaseTTL, pollDelay)
 	return args.Get(0).([]model.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) ChangeOrderStatus(ctx context.Context, order model.Order, status string, accrual float64) error {
-	args := m.Called(ctx, order, status, accrual)
+func (m *MockOrderRepository) AckOrder(ctx context.Context, workerID string, order model.Order, status string, accrual float64) error {
+	args := m.Called(ctx, workerID, order, status, accrual)
 	return args.Error(0)
 }
 
+func (m *MockOrderRepository) AckOrders(ctx context.Context, workerID string, accruals []model.OrderAccrual) (map[string]error, error) {
+	args := m.Called(ctx, workerID, accruals)
+	return args.Get(0).(map[string]error), args.Error(1)
+}
+
+func (m *MockOrderRepository) ReleaseOrder(ctx context.Context, workerID string, order model.Order) error {
+	args := m.Called(ctx, workerID, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) RetryOrder(ctx context.Context, workerID string, order model.Order, lastError string, nextAttempt time.Time, deadLetter bool) error {
+	args := m.Called(ctx, workerID, order, lastError, nextAttempt, deadLetter)
+	return args.Error(0)
+}
+
+type recordingLimiter struct {
+	pauses            []time.Duration
+	requestsPerMinute []int
+}
+
+func (l *recordingLimiter) Wait(ctx context.Context) error {
+	return nil
+}
+
+func (l *recordingLimiter) Pause(ctx context.Context, duration time.Duration) error {
+	l.pauses = append(l.pauses, duration)
+	return nil
+}
+
+func (l *recordingLimiter) Adjust(ctx context.Context, requestsPerMinute int) error {
+	l.requestsPerMinute = append(l.requestsPerMinute, requestsPerMinute)
+	return nil
+}
+
+type failingLimiter struct {
+	recordingLimiter
+}
+
+func (l *failingLimiter) Wait(ctx context.Context) error {
+	return errors.New("rate limit table is locked")
+}
+
+type blockingLimiter struct {
+	recordingLimiter
+}
+
+func (l *blockingLimiter) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type closedCircuit struct{}
+
+func (closedCircuit) RetryIn() time.Duration {
+	return 0
+}
+
+type openCircuit struct{}
+
+func (openCircuit) RetryIn() time.Duration {
+	return time.Hour
+}
+
+type staticAssignment []int
+
+func (a staticAssignment) Shards() []int {
+	return a
+}
+
+type stubAccrualClient struct {
+	FindOrderFunc  func(ctx context.Context, orderNumber string) (accrual.Result, error)
+	FindOrdersFunc func(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error)
+}
+
+func (c *stubAccrualClient) FindOrder(ctx context.Context, orderNumber string) (accrual.Result, error) {
+	return c.FindOrderFunc(ctx, orderNumber)
+}
+
+func (c *stubAccrualClient) FindOrders(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error) {
+	return c.FindOrdersFunc(ctx, orderNumbers)
+}
+
+func newTestWorker(t *testing.T, repository orderRepository, client accrual.Client, limiter accrualLimiter) *Worker {
+	return &Worker{
+		id:              "worker-1",
+		leaseTTL:        time.Minute,
+		retryPolicy:     RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: time.Minute, MaxAttempts: 3},
+		lookupPolicy:    LookupPolicy{Mode: SingleLookup, BatchSize: 2, Concurrency: 2},
+		logger:          zaptest.NewLogger(t),
+		accrualClient:   client,
+		limiter:         limiter,
+		circuit:         closedCircuit{},
+		orderRepository: repository,
+		metrics:         metrics.NewNoopMetrics(),
+		registry:        health.NewWorkerRegistry(),
+		assignment:      staticAssignment{0, 1},
+	}
+}
+
 func TestWorker_ProcessOrders(t *testing.T) {
-	logger := zaptest.NewLogger(t)
-	limiter := rate.NewLimiter(10, 1)
+	t.Run("should successfully process order if accrual is calculated", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				assert.Equal(t, "12345", orderNumber)
+				return accrual.Result{OrderNumber: orderNumber, Status: model.ProcessedOrderStatus, Accrual: 100}, nil
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+
+		order := model.Order{OrderNumber: "12345"}
+		mockRepo.On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{order}, nil).Once().On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{}, nil)
+		mockRepo.On("AckOrder", ctx, "worker-1", order, model.ProcessedOrderStatus, 100.0).Return(nil)
+
+		go worker.ProcessOrders(ctx)
+
+		<-ctx.Done()
+
+		mockRepo.AssertExpectations(t)
+	})
 
-	t.Run("should successfully processed order status if response status is 200", func(t *testing.T) {
+	t.Run("should successfully process order after rate limit", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 		defer cancel()
-		client := httpclient.NewClient(httpclient.WithHTTPTimeout(10 * time.Millisecond))
+		limiter := &recordingLimiter{}
 		mockRepo := new(MockOrderRepository)
-		dummyHandler := func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
 
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`{ "order": "12345", "status": "COMPLETED", "accrual": 100 }`))
+		var callCount atomic.Int32
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				if callCount.Add(1) == 1 {
+					return accrual.Result{}, &accrual.ErrRateLimited{RetryAfter: time.Minute, RequestsPerMinute: 30}
+				}
+				return accrual.Result{OrderNumber: orderNumber, Status: model.ProcessedOrderStatus, Accrual: 100}, nil
+			},
 		}
-		server := httptest.NewServer(http.HandlerFunc(dummyHandler))
-		defer server.Close()
+		worker := newTestWorker(t, mockRepo, client, limiter)
 
-		var worker = &Worker{
-			from:            0,
-			to:              10,
-			logger:          logger,
-			httpClient:      client,
-			limiter:         limiter,
-			orderRepository: mockRepo,
-		}
 		order := model.Order{OrderNumber: "12345"}
-		mockRepo.On("FindOrdersToProcess", ctx, 0, 10).Return([]model.Order{order}, nil).Once().On("FindOrdersToProcess", ctx, 0, 10).Return([]model.Order{}, nil)
-		mockRepo.On("ChangeOrderStatus", ctx, mock.AnythingOfType("model.Order"), "COMPLETED", 100.0).Return(nil).Run(func(args mock.Arguments) {
-			order := args.Get(1).(model.Order)
+		mockRepo.On("AckOrder", ctx, "worker-1", order, model.ProcessedOrderStatus, 100.0).Return(nil)
 
-			assert.Equal(t, "12345", order.OrderNumber)
-		})
+		err := worker.processOrder(ctx, order)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		assert.Equal(t, []time.Duration{time.Minute}, limiter.pauses)
+		assert.Equal(t, []int{30}, limiter.requestsPerMinute)
+	})
+
+	t.Run("should postpone order if accrual system responds with error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				return accrual.Result{}, errors.New("unexpected accrual response status: 500 Internal Server Error")
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+
+		order := model.Order{OrderNumber: "12345"}
+		mockRepo.On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{order}, nil).Once().On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{}, nil)
+		mockRepo.On("RetryOrder", ctx, "worker-1", order, "unexpected accrual response status: 500 Internal Server Error",
+			mock.AnythingOfType("time.Time"), false).Return(nil)
 
-		go worker.ProcessOrders(ctx, server.URL)
+		go worker.ProcessOrders(ctx)
 
 		<-ctx.Done()
 
-		mockRepo.AssertCalled(t, "ChangeOrderStatus", ctx, order, "COMPLETED", 100.0)
 		mockRepo.AssertExpectations(t)
 	})
+}
 
-	t.Run("should successfully process order status if response status is 200 after 429", func(t *testing.T) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+func TestWorker_ProcessOrder_AccrualMock(t *testing.T) {
+	ctx := context.Background()
+	config := accrualtest.DefaultConfig()
+	config.RegisterAll = false
+	server := accrualtest.NewServer(config)
+	defer server.Close()
+	server.Register("12345678903", accrualtest.Step{Status: accrual.RegisteredStatus}, accrualtest.Step{Status: accrual.ProcessedStatus, Accrual: 729.98})
 
-		var callCount int
-		dummyHandler := func(w http.ResponseWriter, r *http.Request) {
-			assert.Equal(t, http.MethodGet, r.Method)
-			callCount++
-			if callCount == 1 {
-				w.Header().Set("Retry-After", "1")
-				w.WriteHeader(http.StatusTooManyRequests)
-				return
-			}
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(`{ "order": "12345", "status": "COMPLETED", "accrual": 100 }`))
-		}
-		server := httptest.NewServer(http.HandlerFunc(dummyHandler))
+	mockRepo := new(MockOrderRepository)
+	client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+	worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+
+	order := model.Order{OrderNumber: "12345678903"}
+	unknownOrder := model.Order{OrderNumber: "79927398713"}
+	mockRepo.On("AckOrder", ctx, "worker-1", order, model.ProcessingOrderStatus, 0.0).Return(nil).Once()
+	mockRepo.On("AckOrder", ctx, "worker-1", order, model.ProcessedOrderStatus, 729.98).Return(nil).Once()
+	mockRepo.On("RetryOrder", ctx, "worker-1", unknownOrder, accrual.ErrNotRegistered.Error(), mock.AnythingOfType("time.Time"), false).Return(nil)
+
+	assert.NoError(t, worker.processOrder(ctx, order))
+	assert.NoError(t, worker.processOrder(ctx, order))
+	assert.NoError(t, worker.processOrder(ctx, unknownOrder))
+
+	mockRepo.AssertExpectations(t)
+	assert.Equal(t, 2, server.Requests("12345678903"))
+}
+
+func TestWorker_LookupModes(t *testing.T) {
+	t.Run("should look up orders in batches and save each batch in one transaction", func(t *testing.T) {
+		ctx := context.Background()
+		config := accrualtest.DefaultConfig()
+		config.RegisterAll = false
+		server := accrualtest.NewServer(config)
 		defer server.Close()
+		server.Register("12345678903", accrualtest.Step{Status: accrual.ProcessedStatus, Accrual: 100})
+		server.Register("4561261212345467", accrualtest.Step{Status: accrual.InvalidStatus})
 
-		client := httpclient.NewClient(httpclient.WithHTTPTimeout(10 * time.Millisecond))
 		mockRepo := new(MockOrderRepository)
+		client := accrual.NewHTTPClient(server.Client(), server.URL, metrics.NewNoopMetrics())
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+		worker.lookupPolicy.Mode = BatchLookup
+
+		processed := model.Order{OrderNumber: "12345678903"}
+		unknown := model.Order{OrderNumber: "79927398713"}
+		invalid := model.Order{OrderNumber: "4561261212345467"}
+		mockRepo.On("AckOrders", ctx, "worker-1", []model.OrderAccrual{{Order: processed, Status: model.ProcessedOrderStatus, Accrual: 100}}).Return(map[string]error{}, nil).Once()
+		mockRepo.On("AckOrders", ctx, "worker-1", []model.OrderAccrual{{Order: invalid, Status: model.InvalidOrderStatus}}).Return(map[string]error{}, nil).Once()
+		mockRepo.On("RetryOrder", ctx, "worker-1", unknown, accrual.ErrNotRegistered.Error(), mock.AnythingOfType("time.Time"), false).Return(nil)
+
+		worker.processBatches(ctx, []model.Order{processed, unknown, invalid})
+
+		mockRepo.AssertExpectations(t)
+	})
 
-		var worker = &Worker{
-			from:            0,
-			to:              10,
-			logger:          logger,
-			httpClient:      client,
-			limiter:         limiter,
-			orderRepository: mockRepo,
+	t.Run("should spend attempt of order failed in batch", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrdersFunc: func(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error) {
+				results := make(map[string]accrual.Result)
+				for _, orderNumber := range orderNumbers {
+					results[orderNumber] = accrual.Result{OrderNumber: orderNumber, Status: model.ProcessingOrderStatus}
+				}
+				return results, nil
+			},
 		}
-		order := model.Order{OrderNumber: "12345"}
-		mockRepo.On("FindOrdersToProcess", ctx, 0, 10).Return([]model.Order{order}, nil).Once().On("FindOrdersToProcess", ctx, 0, 10).Return([]model.Order{}, nil)
-		mockRepo.On("ChangeOrderStatus", ctx, order, "COMPLETED", 100.0).Return(nil)
-		mockRepo.On("ChangeOrderStatus", ctx, mock.AnythingOfType("model.Order"), "COMPLETED", 100.0).Return(nil).Run(func(args mock.Arguments) {
-			order := args.Get(1).(model.Order)
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+		worker.lookupPolicy.Mode = BatchLookup
+
+		lost := model.Order{OrderNumber: "12345"}
+		changed := model.Order{OrderNumber: "67890"}
+		mockRepo.On("AckOrders", ctx, "worker-1", mock.Anything).Return(map[string]error{
+			"12345": model.ErrOrderLeaseIsLost,
+			"67890": model.ErrUserBalanceWasNotFound,
+		}, nil)
+		mockRepo.On("RetryOrder", ctx, "worker-1", changed, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time"), false).Return(nil)
+
+		worker.processBatches(ctx, []model.Order{lost, changed})
 
-			assert.Equal(t, "12345", order.OrderNumber)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNumberOfCalls(t, "RetryOrder", 1)
+	})
+
+	t.Run("should look up orders one by one if batch lookup is not supported", func(t *testing.T) {
+		ctx := context.Background()
+		var batchRequests atomic.Int32
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrdersFunc: func(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error) {
+				batchRequests.Add(1)
+				return nil, accrual.ErrBatchIsNotSupported
+			},
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				return accrual.Result{OrderNumber: orderNumber, Status: model.ProcessedOrderStatus, Accrual: 10}, nil
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+		worker.lookupPolicy.Mode = BatchLookup
+
+		orders := []model.Order{{OrderNumber: "1"}, {OrderNumber: "2"}, {OrderNumber: "3"}}
+		mockRepo.On("AckOrders", ctx, "worker-1", mock.Anything).Return(map[string]error{}, nil).Twice()
+
+		worker.processBatches(ctx, orders)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RetryOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Equal(t, int32(1), batchRequests.Load())
+	})
+
+	t.Run("should look up orders concurrently and save them in one transaction", func(t *testing.T) {
+		ctx := context.Background()
+		var inFlight, maxInFlight atomic.Int32
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					seen := maxInFlight.Load()
+					if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				if orderNumber == "3" {
+					return accrual.Result{}, fmt.Errorf("%w: connection refused", model.ErrAccrualIsUnavailable)
+				}
+				return accrual.Result{OrderNumber: orderNumber, Status: model.ProcessedOrderStatus, Accrual: 10}, nil
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+		worker.lookupPolicy.Mode = ConcurrentLookup
+
+		orders := []model.Order{{OrderNumber: "1"}, {OrderNumber: "2"}, {OrderNumber: "3"}, {OrderNumber: "4"}}
+		mockRepo.On("AckOrders", ctx, "worker-1", []model.OrderAccrual{
+			{Order: orders[0], Status: model.ProcessedOrderStatus, Accrual: 10},
+			{Order: orders[1], Status: model.ProcessedOrderStatus, Accrual: 10},
+			{Order: orders[3], Status: model.ProcessedOrderStatus, Accrual: 10},
+		}).Return(map[string]error{}, nil).Once()
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[2]).Return(nil)
+
+		worker.processConcurrently(ctx, orders)
+
+		mockRepo.AssertExpectations(t)
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+}
+
+func TestWorker_RetryOrder(t *testing.T) {
+	t.Run("should postpone order if it is not registered in accrual system", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				return accrual.Result{}, accrual.ErrNotRegistered
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+
+		order := model.Order{OrderNumber: "12345", AttemptCount: 1}
+		mockRepo.On("RetryOrder", ctx, "worker-1", order, accrual.ErrNotRegistered.Error(),
+			mock.AnythingOfType("time.Time"), false).Return(nil).Run(func(args mock.Arguments) {
+			nextAttempt := args.Get(4).(time.Time)
+			assert.WithinDuration(t, time.Now().Add(20*time.Second), nextAttempt, time.Second)
 		})
 
-		go worker.ProcessOrders(ctx, server.URL)
+		err := worker.processOrder(ctx, order)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should move order to dead-letter state after last attempt", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		worker := newTestWorker(t, mockRepo, &stubAccrualClient{}, &recordingLimiter{})
+
+		order := model.Order{OrderNumber: "12345", AttemptCount: 2}
+		mockRepo.On("RetryOrder", ctx, "worker-1", order, "timeout", mock.AnythingOfType("time.Time"), true).Return(nil)
+
+		worker.retryOrder(ctx, order, "timeout")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWorker_AccrualIsUnavailable(t *testing.T) {
+	t.Run("should release order without spending attempt if accrual system is unreachable", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrderFunc: func(ctx context.Context, orderNumber string) (accrual.Result, error) {
+				return accrual.Result{}, fmt.Errorf("%w: connection refused", model.ErrAccrualIsUnavailable)
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+
+		order := model.Order{OrderNumber: "12345"}
+		mockRepo.On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{order}, nil).Once().On("ClaimOrders", ctx, "worker-1", []int{0, 1}, claimBatchSize, time.Minute, time.Duration(0)).Return([]model.Order{}, nil)
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", order).Return(nil)
+
+		go worker.ProcessOrders(ctx)
 
 		<-ctx.Done()
 
-		mockRepo.AssertCalled(t, "ChangeOrderStatus", ctx, order, "COMPLETED", 100.0)
+		mockRepo.AssertCalled(t, "ReleaseOrder", ctx, "worker-1", order)
+		mockRepo.AssertNotCalled(t, "RetryOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should release remaining orders if rate limiter fails", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		worker := newTestWorker(t, mockRepo, &stubAccrualClient{}, &failingLimiter{})
+
+		orders := []model.Order{{OrderNumber: "12345"}, {OrderNumber: "67890"}}
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[0]).Return(nil)
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[1]).Return(nil)
+
+		worker.processSerially(ctx, orders)
+
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("should release orders if context is canceled in batch and concurrent modes", func(t *testing.T) {
+		for _, mode := range []string{BatchLookup, ConcurrentLookup} {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			mockRepo := new(MockOrderRepository)
+			worker := newTestWorker(t, mockRepo, &stubAccrualClient{}, &blockingLimiter{})
+			worker.lookupPolicy.Mode = mode
+
+			orders := []model.Order{{OrderNumber: "12345"}, {OrderNumber: "67890"}}
+			mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[0]).Return(nil)
+			mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[1]).Return(nil)
+
+			if mode == BatchLookup {
+				worker.processBatches(ctx, orders)
+			} else {
+				worker.processConcurrently(ctx, orders)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockRepo.AssertNotCalled(t, "RetryOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should release orders without spending attempts if batch was not saved", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockOrderRepository)
+		client := &stubAccrualClient{
+			FindOrdersFunc: func(ctx context.Context, orderNumbers []string) (map[string]accrual.Result, error) {
+				results := make(map[string]accrual.Result)
+				for _, orderNumber := range orderNumbers {
+					results[orderNumber] = accrual.Result{OrderNumber: orderNumber, Status: model.ProcessingOrderStatus}
+				}
+				return results, nil
+			},
+		}
+		worker := newTestWorker(t, mockRepo, client, &recordingLimiter{})
+		worker.lookupPolicy.Mode = BatchLookup
+
+		orders := []model.Order{{OrderNumber: "12345"}, {OrderNumber: "67890"}}
+		mockRepo.On("AckOrders", ctx, "worker-1", mock.Anything).Return(map[string]error(nil), errors.New("connection refused"))
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[0]).Return(nil)
+		mockRepo.On("ReleaseOrder", ctx, "worker-1", orders[1]).Return(nil)
+
+		worker.processBatches(ctx, orders)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RetryOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not claim orders while circuit is open", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		mockRepo := new(MockOrderRepository)
+		worker := newTestWorker(t, mockRepo, &stubAccrualClient{}, &recordingLimiter{})
+		worker.circuit = openCircuit{}
+
+		worker.ProcessOrders(ctx)
+
+		mockRepo.AssertNotCalled(t, "ClaimOrders", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: time.Minute, MaxAttempts: 10}
+
+	assert.Equal(t, 10*time.Second, policy.Delay(0))
+	assert.Equal(t, 20*time.Second, policy.Delay(1))
+	assert.Equal(t, 40*time.Second, policy.Delay(2))
+	assert.Equal(t, time.Minute, policy.Delay(3))
+	assert.Equal(t, time.Minute, policy.Delay(100))
+}
+
+type MockQueueRepository struct {
+	mock.Mock
+}
+
+func (m *MockQueueRepository) CountOrdersToProcess(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+type queueDepthMetrics struct {
+	*metrics.NoopMetrics
+	depth map[string]int
+}
+
+func (m *queueDepthMetrics) SetOrderQueueDepth(status string, count int) {
+	m.depth[status] = count
+}
+
+func TestQueueMonitor_ReportQueueDepth(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockRepo := new(MockQueueRepository)
+	mockRepo.On("CountOrdersToProcess", ctx).Return(map[string]int{model.NewOrderStatus: 3}, nil)
+	recorder := &queueDepthMetrics{NoopMetrics: metrics.NewNoopMetrics(), depth: map[string]int{}}
+
+	monitor := NewQueueMonitor(logger, mockRepo, recorder, time.Hour)
+	monitor.reportQueueDepth(ctx)
+
+	assert.Equal(t, map[string]int{model.NewOrderStatus: 3, model.ProcessingOrderStatus: 0}, recorder.depth)
 }
@@ -0,0 +1,24 @@
+package service
+
+import "context"
+
+const principalContextKey ContextKey = "principal"
+
+type Principal struct {
+	UserID   string
+	Username string
+}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey).(Principal)
+	return principal, ok && principal.UserID != ""
+}
+
+func CurrentUserID(ctx context.Context) string {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.UserID
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, "", CurrentUserID(context.Background()))
+
+	ctx := WithPrincipal(context.Background(), Principal{UserID: "1", Username: "testUser"})
+	principal, ok := PrincipalFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Principal{UserID: "1", Username: "testUser"}, principal)
+	assert.Equal(t, "1", CurrentUserID(ctx))
+}
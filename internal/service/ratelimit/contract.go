@@ -0,0 +1,16 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+type rateLimitRepository interface {
+	InitRateLimit(ctx context.Context, name string, ratePerSecond float64, capacity float64) error
+
+	TakeToken(ctx context.Context, name string) (time.Duration, error)
+
+	PauseRateLimit(ctx context.Context, name string, duration time.Duration) error
+
+	ChangeRate(ctx context.Context, name string, ratePerSecond float64) error
+}
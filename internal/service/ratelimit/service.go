@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+const maxWaitStep = time.Second
+
+type SharedLimiter struct {
+	logger              *zap.Logger
+	rateLimitRepository rateLimitRepository
+	name                string
+	ratePerSecond       float64
+	burst               int
+}
+
+func NewSharedLimiter(logger *zap.Logger, repository rateLimitRepository, name string, ratePerSecond float64, burst int) *SharedLimiter {
+	return &SharedLimiter{
+		logger:              logger,
+		rateLimitRepository: repository,
+		name:                name,
+		ratePerSecond:       ratePerSecond,
+		burst:               burst,
+	}
+}
+
+func (l *SharedLimiter) Init(ctx context.Context) error {
+	return l.rateLimitRepository.InitRateLimit(ctx, l.name, l.ratePerSecond, float64(l.burst))
+}
+
+func (l *SharedLimiter) Wait(ctx context.Context) error {
+	for {
+		wait, err := l.rateLimitRepository.TakeToken(ctx, l.name)
+		if err != nil {
+			return err
+		}
+
+		if wait == 0 {
+			return nil
+		}
+
+		// capped, so a pause or a rate change made by another replica is noticed quickly
+		timer := time.NewTimer(min(wait, maxWaitStep))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *SharedLimiter) Pause(ctx context.Context, duration time.Duration) error {
+	l.logger.Info("Pause rate limit", zap.String("name", l.name), zap.Duration("duration", duration))
+	return l.rateLimitRepository.PauseRateLimit(ctx, l.name, duration)
+}
+
+func (l *SharedLimiter) Adjust(ctx context.Context, requestsPerMinute int) error {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+
+	ratePerSecond := min(l.ratePerSecond, float64(requestsPerMinute)/60)
+	l.logger.Info("Change rate limit", zap.String("name", l.name), zap.Float64("ratePerSecond", ratePerSecond))
+	return l.rateLimitRepository.ChangeRate(ctx, l.name, ratePerSecond)
+}
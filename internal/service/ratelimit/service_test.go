@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockRateLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitRepository) InitRateLimit(ctx context.Context, name string, ratePerSecond float64, capacity float64) error {
+	args := m.Called(ctx, name, ratePerSecond, capacity)
+	return args.Error(0)
+}
+
+func (m *MockRateLimitRepository) TakeToken(ctx context.Context, name string) (time.Duration, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockRateLimitRepository) PauseRateLimit(ctx context.Context, name string, duration time.Duration) error {
+	args := m.Called(ctx, name, duration)
+	return args.Error(0)
+}
+
+func (m *MockRateLimitRepository) ChangeRate(ctx context.Context, name string, ratePerSecond float64) error {
+	args := m.Called(ctx, name, ratePerSecond)
+	return args.Error(0)
+}
+
+func TestSharedLimiter_Wait(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should wait until token is taken", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockRateLimitRepository)
+		mockRepo.On("TakeToken", ctx, "accrual").Return(10*time.Millisecond, nil).Once()
+		mockRepo.On("TakeToken", ctx, "accrual").Return(time.Duration(0), nil).Once()
+
+		limiter := NewSharedLimiter(logger, mockRepo, "accrual", 10, 1)
+		assert.NoError(t, limiter.Wait(ctx))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		mockRepo := new(MockRateLimitRepository)
+		mockRepo.On("TakeToken", ctx, "accrual").Return(time.Minute, nil)
+
+		limiter := NewSharedLimiter(logger, mockRepo, "accrual", 10, 1)
+		assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("should return error if token is not taken", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockRateLimitRepository)
+		mockRepo.On("TakeToken", ctx, "accrual").Return(time.Duration(0), errors.New("db error"))
+
+		limiter := NewSharedLimiter(logger, mockRepo, "accrual", 10, 1)
+		assert.Error(t, limiter.Wait(ctx))
+	})
+}
+
+func TestSharedLimiter_Adjust(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should lower rate to announced limit", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockRateLimitRepository)
+		mockRepo.On("ChangeRate", ctx, "accrual", 0.5).Return(nil)
+
+		limiter := NewSharedLimiter(logger, mockRepo, "accrual", 10, 1)
+		assert.NoError(t, limiter.Adjust(ctx, 30))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not exceed configured rate", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(MockRateLimitRepository)
+		mockRepo.On("ChangeRate", ctx, "accrual", 10.0).Return(nil)
+
+		limiter := NewSharedLimiter(logger, mockRepo, "accrual", 10, 1)
+		assert.NoError(t, limiter.Adjust(ctx, 6000))
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,24 @@
+package reward
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type rewardRepository interface {
+	CreateReward(ctx context.Context, reward model.Reward) error
+
+	UpdateReward(ctx context.Context, reward model.Reward) error
+
+	ArchiveReward(ctx context.Context, rewardID string) error
+
+	FindReward(ctx context.Context, rewardID string) (model.Reward, error)
+
+	FindAllRewards(ctx context.Context) ([]model.Reward, error)
+
+	Redeem(ctx context.Context, reward model.Reward, balance model.Balance, code string) (model.Redemption, error)
+}
+
+type balanceRepository interface {
+	FindBalance(ctx context.Context, userName string) (model.Balance, error)
+}
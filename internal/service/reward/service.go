@@ -0,0 +1,193 @@
+package reward
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type RewardService struct {
+	logger            *zap.Logger
+	rewardRepository  rewardRepository
+	balanceRepository balanceRepository
+}
+
+func NewRewardService(l *zap.Logger, r rewardRepository, b balanceRepository) *RewardService {
+	return &RewardService{logger: l, rewardRepository: r, balanceRepository: b}
+}
+
+func (s *RewardService) CreateReward(ctx context.Context, reward model.Reward) (model.Reward, error) {
+	ctx, span := tracing.Start(ctx, "RewardService.CreateReward")
+	defer span.End()
+
+	if !isValidReward(reward) {
+		return model.Reward{}, model.ErrRewardDataIsNotValid
+	}
+
+	rewardID, err := uuid.NewRandom()
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during generate UUID", zap.Error(err))
+		return model.Reward{}, err
+	}
+	reward.ID = rewardID.String()
+	reward.Archived = false
+	reward.CreateDate = time.Now()
+
+	if err := s.rewardRepository.CreateReward(ctx, reward); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during create reward", zap.String("name", reward.Name), zap.Error(err))
+		return model.Reward{}, err
+	}
+	return reward, nil
+}
+
+func (s *RewardService) UpdateReward(ctx context.Context, reward model.Reward) error {
+	ctx, span := tracing.Start(ctx, "RewardService.UpdateReward")
+	defer span.End()
+
+	if reward.ID == "" || !isValidReward(reward) {
+		return model.ErrRewardDataIsNotValid
+	}
+
+	if _, err := uuid.Parse(reward.ID); err != nil {
+		return model.ErrRewardWasNotFound
+	}
+
+	if err := s.rewardRepository.UpdateReward(ctx, reward); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during update reward", zap.String("rewardID", reward.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *RewardService) DeleteReward(ctx context.Context, rewardID string) error {
+	ctx, span := tracing.Start(ctx, "RewardService.DeleteReward")
+	defer span.End()
+
+	if rewardID == "" {
+		return model.ErrRewardDataIsNotValid
+	}
+
+	if _, err := uuid.Parse(rewardID); err != nil {
+		return model.ErrRewardWasNotFound
+	}
+
+	if err := s.rewardRepository.ArchiveReward(ctx, rewardID); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during archive reward", zap.String("rewardID", rewardID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *RewardService) FindAllRewards(ctx context.Context) ([]model.Reward, error) {
+	ctx, span := tracing.Start(ctx, "RewardService.FindAllRewards")
+	defer span.End()
+
+	rewards, err := s.rewardRepository.FindAllRewards(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find rewards", zap.Error(err))
+		return nil, err
+	}
+
+	if len(rewards) == 0 {
+		return nil, model.ErrRewardsWasNotFound
+	}
+	return rewards, nil
+}
+
+func (s *RewardService) FindAvailableRewards(ctx context.Context) ([]model.Reward, error) {
+	ctx, span := tracing.Start(ctx, "RewardService.FindAvailableRewards")
+	defer span.End()
+
+	rewards, err := s.rewardRepository.FindAllRewards(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find rewards", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	var available []model.Reward
+	for _, reward := range rewards {
+		if reward.IsAvailable(now) {
+			available = append(available, reward)
+		}
+	}
+
+	if len(available) == 0 {
+		return nil, model.ErrRewardsWasNotFound
+	}
+	return available, nil
+}
+
+func (s *RewardService) Redeem(ctx context.Context, rewardID string) (model.Redemption, error) {
+	ctx, span := tracing.Start(ctx, "RewardService.Redeem")
+	defer span.End()
+
+	if rewardID == "" {
+		return model.Redemption{}, model.ErrRewardDataIsNotValid
+	}
+
+	if _, err := uuid.Parse(rewardID); err != nil {
+		return model.Redemption{}, model.ErrRewardWasNotFound
+	}
+
+	reward, err := s.rewardRepository.FindReward(ctx, rewardID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find reward", zap.String("rewardID", rewardID), zap.Error(err))
+		return model.Redemption{}, err
+	}
+
+	if !reward.IsAvailable(time.Now()) {
+		return model.Redemption{}, model.ErrRewardIsNotAvailable
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	balance, err := s.balanceRepository.FindBalance(ctx, currentUserID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during fetch balance", zap.String("userID", currentUserID), zap.Error(err))
+		return model.Redemption{}, err
+	}
+
+	if balance.Spendable() < reward.Price {
+		return model.Redemption{}, model.ErrUserBalanceLessThanSumToWithdraw
+	}
+
+	code, err := generateRedemptionCode()
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during generate redemption code", zap.Error(err))
+		return model.Redemption{}, err
+	}
+
+	redemption, err := s.rewardRepository.Redeem(ctx, reward, balance, code)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during redeem reward", zap.String("userID", currentUserID), zap.String("rewardID", rewardID), zap.Error(err))
+		return model.Redemption{}, err
+	}
+	return redemption, nil
+}
+
+func isValidReward(reward model.Reward) bool {
+	if strings.TrimSpace(reward.Name) == "" || reward.Price <= 0 || reward.Stock < 0 {
+		return false
+	}
+
+	if reward.ValidFrom != nil && reward.ValidTo != nil && !reward.ValidTo.After(*reward.ValidFrom) {
+		return false
+	}
+	return true
+}
+
+func generateRedemptionCode() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(bytes)), nil
+}
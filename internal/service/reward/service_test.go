@@ -0,0 +1,207 @@
+package reward
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockRewardRepository struct {
+	mock.Mock
+}
+
+func (m *MockRewardRepository) CreateReward(ctx context.Context, reward model.Reward) error {
+	args := m.Called(ctx, reward)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) UpdateReward(ctx context.Context, reward model.Reward) error {
+	args := m.Called(ctx, reward)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) ArchiveReward(ctx context.Context, rewardID string) error {
+	args := m.Called(ctx, rewardID)
+	return args.Error(0)
+}
+
+func (m *MockRewardRepository) FindReward(ctx context.Context, rewardID string) (model.Reward, error) {
+	args := m.Called(ctx, rewardID)
+	return args.Get(0).(model.Reward), args.Error(1)
+}
+
+func (m *MockRewardRepository) FindAllRewards(ctx context.Context) ([]model.Reward, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.Reward), args.Error(1)
+}
+
+func (m *MockRewardRepository) Redeem(ctx context.Context, reward model.Reward, balance model.Balance, code string) (model.Redemption, error) {
+	args := m.Called(ctx, reward, balance, code)
+	return args.Get(0).(model.Redemption), args.Error(1)
+}
+
+type MockBalanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceRepository) FindBalance(ctx context.Context, userName string) (model.Balance, error) {
+	args := m.Called(ctx, userName)
+	return args.Get(0).(model.Balance), args.Error(1)
+}
+
+func TestRewardService_CreateReward(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return error if reward is not valid", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo}
+
+		_, err := rewardService.CreateReward(ctx, model.Reward{Name: "Coffee", Price: 0, Stock: 10})
+		assert.Equal(t, model.ErrRewardDataIsNotValid, err)
+
+		from := time.Now()
+		to := from.Add(-time.Hour)
+		_, err = rewardService.CreateReward(ctx, model.Reward{Name: "Coffee", Price: 10, Stock: 10, ValidFrom: &from, ValidTo: &to})
+		assert.Equal(t, model.ErrRewardDataIsNotValid, err)
+
+		mockRepo.AssertNotCalled(t, "CreateReward", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should create reward", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo}
+
+		mockRepo.On("CreateReward", ctx, mock.AnythingOfType("model.Reward")).Return(nil)
+
+		reward, err := rewardService.CreateReward(ctx, model.Reward{Name: "Coffee", Price: 10, Stock: 10})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, reward.ID)
+		assert.Equal(t, "Coffee", reward.Name)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRewardService_FindAvailableRewards(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should filter unavailable rewards", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo}
+
+		expired := time.Now().Add(-time.Hour)
+		rewards := []model.Reward{
+			{ID: "1", Name: "Coffee", Price: 10, Stock: 5},
+			{ID: "2", Name: "Tea", Price: 10, Stock: 0},
+			{ID: "3", Name: "Cake", Price: 10, Stock: 5, ValidTo: &expired},
+			{ID: "4", Name: "Juice", Price: 10, Stock: 5, Archived: true},
+		}
+		mockRepo.On("FindAllRewards", ctx).Return(rewards, nil)
+
+		result, err := rewardService.FindAvailableRewards(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Reward{rewards[0]}, result)
+	})
+
+	t.Run("should return error if there are no available rewards", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo}
+
+		mockRepo.On("FindAllRewards", ctx).Return([]model.Reward{}, nil)
+
+		_, err := rewardService.FindAvailableRewards(ctx)
+		assert.Equal(t, model.ErrRewardsWasNotFound, err)
+	})
+}
+
+func TestRewardService_Redeem(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+	rewardID := "7f3a2d4c-1b5e-4c6a-9d8f-0e1a2b3c4d5e"
+	reward := model.Reward{ID: rewardID, Name: "Coffee", Price: 100, Stock: 5}
+
+	t.Run("should return not found if reward id is not valid", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		_, err := rewardService.Redeem(ctx, "1")
+		assert.Equal(t, model.ErrRewardWasNotFound, err)
+		mockRepo.AssertNotCalled(t, "FindReward", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if reward was not found", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		mockRepo.On("FindReward", ctx, rewardID).Return(model.Reward{}, model.ErrRewardWasNotFound)
+
+		_, err := rewardService.Redeem(ctx, rewardID)
+		assert.Equal(t, model.ErrRewardWasNotFound, err)
+	})
+
+	t.Run("should return error if reward is out of stock", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		mockRepo.On("FindReward", ctx, rewardID).Return(model.Reward{ID: rewardID, Name: "Coffee", Price: 100, Stock: 0}, nil)
+
+		_, err := rewardService.Redeem(ctx, rewardID)
+		assert.Equal(t, model.ErrRewardIsNotAvailable, err)
+		mockBalanceRepo.AssertNotCalled(t, "FindBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if balance is less than price", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		mockRepo.On("FindReward", ctx, rewardID).Return(reward, nil)
+		mockBalanceRepo.On("FindBalance", ctx, "testUser").Return(model.Balance{UserID: "testUser", Balance: 50}, nil)
+
+		_, err := rewardService.Redeem(ctx, rewardID)
+		assert.Equal(t, model.ErrUserBalanceLessThanSumToWithdraw, err)
+		mockRepo.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should redeem reward", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		balance := model.Balance{UserID: "testUser", Balance: 500, Version: 3}
+		expected := model.Redemption{ID: "r1", RewardID: rewardID, UserID: "testUser", Code: "CODE", Sum: 100}
+		mockRepo.On("FindReward", ctx, rewardID).Return(reward, nil)
+		mockBalanceRepo.On("FindBalance", ctx, "testUser").Return(balance, nil)
+		mockRepo.On("Redeem", ctx, reward, balance, mock.AnythingOfType("string")).Return(expected, nil)
+
+		redemption, err := rewardService.Redeem(ctx, rewardID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, redemption)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if redeem return error", func(t *testing.T) {
+		mockRepo := new(MockRewardRepository)
+		mockBalanceRepo := new(MockBalanceRepository)
+		rewardService := &RewardService{logger: logger, rewardRepository: mockRepo, balanceRepository: mockBalanceRepo}
+
+		balance := model.Balance{UserID: "testUser", Balance: 500, Version: 3}
+		mockRepo.On("FindReward", ctx, rewardID).Return(reward, nil)
+		mockBalanceRepo.On("FindBalance", ctx, "testUser").Return(balance, nil)
+		mockRepo.On("Redeem", ctx, reward, balance, mock.AnythingOfType("string")).Return(model.Redemption{}, errors.New("db error"))
+
+		_, err := rewardService.Redeem(ctx, rewardID)
+		assert.Error(t, err)
+		assert.Equal(t, "db error", err.Error())
+	})
+}
@@ -0,0 +1,16 @@
+package statement
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+)
+
+type statementRepository interface {
+	FindStatements(ctx context.Context, userName string) ([]model.Statement, error)
+
+	FindStatement(ctx context.Context, statementID string) (model.Statement, error)
+}
+
+type documentStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+}
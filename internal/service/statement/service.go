@@ -0,0 +1,76 @@
+package statement
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/logging"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type StatementService struct {
+	logger              *zap.Logger
+	statementRepository statementRepository
+	documentStore       documentStore
+}
+
+func NewStatementService(l *zap.Logger, r statementRepository, s documentStore) *StatementService {
+	return &StatementService{logger: l, statementRepository: r, documentStore: s}
+}
+
+func (s *StatementService) FindStatements(ctx context.Context) ([]model.Statement, error) {
+	ctx, span := tracing.Start(ctx, "StatementService.FindStatements")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	statements, err := s.statementRepository.FindStatements(ctx, currentUserID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find statements", zap.String("userID", currentUserID), zap.Error(err))
+		return []model.Statement{}, err
+	}
+
+	if len(statements) == 0 {
+		return []model.Statement{}, model.ErrStatementsWasNotFound
+	}
+
+	return statements, nil
+}
+
+func (s *StatementService) FindStatementDocument(ctx context.Context, statementID string, format string) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "StatementService.FindStatementDocument")
+	defer span.End()
+
+	if _, err := uuid.Parse(statementID); err != nil {
+		return nil, model.ErrStatementWasNotFound
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	statement, err := s.statementRepository.FindStatement(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+
+	if statement.UserID != currentUserID {
+		return nil, model.ErrStatementWasNotFound
+	}
+
+	var key string
+	switch format {
+	case "pdf":
+		key = statement.PdfKey
+	case "csv":
+		key = statement.CsvKey
+	default:
+		return nil, model.ErrDocumentWasNotFound
+	}
+
+	document, err := s.documentStore.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during read statement document", zap.String("statementID", statementID), zap.Error(err))
+		return nil, err
+	}
+
+	return document, nil
+}
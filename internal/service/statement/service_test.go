@@ -0,0 +1,139 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+)
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) FindStatements(ctx context.Context, userName string) ([]model.Statement, error) {
+	args := m.Called(ctx, userName)
+	return args.Get(0).([]model.Statement), args.Error(1)
+}
+
+func (m *MockStatementRepository) FindStatement(ctx context.Context, statementID string) (model.Statement, error) {
+	args := m.Called(ctx, statementID)
+	return args.Get(0).(model.Statement), args.Error(1)
+}
+
+type MockDocumentStore struct {
+	mock.Mock
+}
+
+func (m *MockDocumentStore) Get(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func TestStatementService_FindStatements(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return statements", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		statementService := NewStatementService(logger, mockRepo, new(MockDocumentStore))
+
+		statements := []model.Statement{{ID: "1", UserID: "testUser"}}
+		mockRepo.On("FindStatements", ctx, "testUser").Return(statements, nil)
+
+		result, err := statementService.FindStatements(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, statements, result)
+	})
+
+	t.Run("should return error if statements was not found", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		statementService := NewStatementService(logger, mockRepo, new(MockDocumentStore))
+
+		mockRepo.On("FindStatements", ctx, "testUser").Return([]model.Statement{}, nil)
+
+		_, err := statementService.FindStatements(ctx)
+		assert.Equal(t, model.ErrStatementsWasNotFound, err)
+	})
+}
+
+func TestStatementService_FindStatementDocument(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+	statementID := "5f0c3a8e-1b2d-4c6e-9f7a-2d4b6c8e0a13"
+	statement := model.Statement{ID: statementID, UserID: "testUser", PdfKey: "statements/1.pdf", CsvKey: "statements/1.csv"}
+
+	t.Run("should return document", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+		statementService := NewStatementService(logger, mockRepo, mockStore)
+
+		mockRepo.On("FindStatement", ctx, statementID).Return(statement, nil)
+		mockStore.On("Get", ctx, "statements/1.csv").Return([]byte("csv"), nil)
+
+		document, err := statementService.FindStatementDocument(ctx, statementID, "csv")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("csv"), document)
+	})
+
+	t.Run("should return error if statement belongs to other user", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+		statementService := NewStatementService(logger, mockRepo, mockStore)
+
+		mockRepo.On("FindStatement", ctx, statementID).Return(model.Statement{ID: statementID, UserID: "otherUser"}, nil)
+
+		_, err := statementService.FindStatementDocument(ctx, statementID, "pdf")
+		assert.Equal(t, model.ErrStatementWasNotFound, err)
+		mockStore.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if format is not supported", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+		statementService := NewStatementService(logger, mockRepo, mockStore)
+
+		mockRepo.On("FindStatement", ctx, statementID).Return(statement, nil)
+
+		_, err := statementService.FindStatementDocument(ctx, statementID, "xml")
+		assert.Equal(t, model.ErrDocumentWasNotFound, err)
+	})
+
+	t.Run("should return error if statement id is not valid", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		statementService := NewStatementService(logger, mockRepo, new(MockDocumentStore))
+
+		_, err := statementService.FindStatementDocument(ctx, "1", "pdf")
+		assert.Equal(t, model.ErrStatementWasNotFound, err)
+		mockRepo.AssertNotCalled(t, "FindStatement", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if document is missing", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+		statementService := NewStatementService(logger, mockRepo, mockStore)
+
+		mockRepo.On("FindStatement", ctx, statementID).Return(statement, nil)
+		mockStore.On("Get", ctx, "statements/1.pdf").Return([]byte(nil), model.ErrDocumentWasNotFound)
+
+		_, err := statementService.FindStatementDocument(ctx, statementID, "pdf")
+		assert.Equal(t, model.ErrDocumentWasNotFound, err)
+	})
+
+	t.Run("should return error if store return error", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+		statementService := NewStatementService(logger, mockRepo, mockStore)
+
+		mockRepo.On("FindStatement", ctx, statementID).Return(statement, nil)
+		mockStore.On("Get", ctx, "statements/1.pdf").Return([]byte(nil), errors.New("disk error"))
+
+		_, err := statementService.FindStatementDocument(ctx, statementID, "pdf")
+		assert.Error(t, err)
+		assert.Equal(t, "disk error", err.Error())
+	})
+}
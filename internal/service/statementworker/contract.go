@@ -0,0 +1,17 @@
+package statementworker
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"time"
+)
+
+type statementRepository interface {
+	FindStatementsToGenerate(ctx context.Context, periodStart, periodEnd time.Time, afterUserID string, limit int) ([]model.Statement, error)
+
+	CreateStatement(ctx context.Context, statement model.Statement) error
+}
+
+type documentStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+}
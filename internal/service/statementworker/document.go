@@ -0,0 +1,96 @@
+package statementworker
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"strings"
+	"time"
+)
+
+func renderCSV(statement model.Statement) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	records := [][]string{
+		{"username", "period_start", "period_end", "opening_balance", "accrued", "withdrawn", "closing_balance"},
+		{
+			statement.Username,
+			statement.PeriodStart.Format(time.DateOnly),
+			statement.PeriodEnd.AddDate(0, 0, -1).Format(time.DateOnly),
+			formatAmount(statement.OpeningBalance),
+			formatAmount(statement.Accrued),
+			formatAmount(statement.Withdrawn),
+			formatAmount(statement.ClosingBalance),
+		},
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func renderPDF(statement model.Statement) []byte {
+	lines := []string{
+		"Gophermart account statement",
+		"",
+		"User: " + statement.Username,
+		fmt.Sprintf("Period: %s - %s", statement.PeriodStart.Format(time.DateOnly), statement.PeriodEnd.AddDate(0, 0, -1).Format(time.DateOnly)),
+		"",
+		"Opening balance: " + formatAmount(statement.OpeningBalance),
+		"Accrued: " + formatAmount(statement.Accrued),
+		"Withdrawn: " + formatAmount(statement.Withdrawn),
+		"Closing balance: " + formatAmount(statement.ClosingBalance),
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n/F1 12 Tf\n16 TL\n72 760 Td\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return document.Bytes()
+}
+
+func escapePDFText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < 32 || r > 126:
+			builder.WriteRune('?')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,102 @@
+package statementworker
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	batchSize = 100
+)
+
+type Worker struct {
+	logger              *zap.Logger
+	statementRepository statementRepository
+	documentStore       documentStore
+	interval            time.Duration
+}
+
+func NewWorker(logger *zap.Logger, repository statementRepository, store documentStore, interval time.Duration) *Worker {
+	return &Worker{
+		logger:              logger,
+		statementRepository: repository,
+		documentStore:       store,
+		interval:            interval,
+	}
+}
+
+func (w *Worker) GenerateStatements(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.generateStatements(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) generateStatements(ctx context.Context, now time.Time) {
+	periodStart, periodEnd := previousMonth(now)
+	afterUserID := ""
+	for {
+		statements, err := w.statementRepository.FindStatementsToGenerate(ctx, periodStart, periodEnd, afterUserID, batchSize)
+		if err != nil {
+			w.logger.Error("Error during fetch statements to generate", zap.Error(err))
+			return
+		}
+
+		for _, statement := range statements {
+			if err := w.generateStatement(ctx, statement, now); err != nil {
+				w.logger.Error("Error during generate statement", zap.String("userName", statement.Username), zap.Error(err))
+				continue
+			}
+			w.logger.Debug("Statement generated", zap.String("userName", statement.Username), zap.Time("periodStart", periodStart))
+		}
+
+		if len(statements) < batchSize {
+			return
+		}
+		afterUserID = statements[len(statements)-1].UserID
+	}
+}
+
+func (w *Worker) generateStatement(ctx context.Context, statement model.Statement, now time.Time) error {
+	statementID, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	key := "statements/" + statement.UserID + "/" + statement.PeriodStart.Format("2006-01")
+	statement.ID = statementID.String()
+	statement.PdfKey = key + ".pdf"
+	statement.CsvKey = key + ".csv"
+	statement.CreateDate = now
+
+	csvDocument, err := renderCSV(statement)
+	if err != nil {
+		return err
+	}
+
+	if err := w.documentStore.Put(ctx, statement.CsvKey, csvDocument); err != nil {
+		return err
+	}
+
+	if err := w.documentStore.Put(ctx, statement.PdfKey, renderPDF(statement)); err != nil {
+		return err
+	}
+
+	return w.statementRepository.CreateStatement(ctx, statement)
+}
+
+func previousMonth(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return periodEnd.AddDate(0, -1, 0), periodEnd
+}
@@ -0,0 +1,122 @@
+package statementworker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) FindStatementsToGenerate(ctx context.Context, periodStart, periodEnd time.Time, afterUserID string, limit int) ([]model.Statement, error) {
+	args := m.Called(ctx, periodStart, periodEnd, afterUserID, limit)
+	return args.Get(0).([]model.Statement), args.Error(1)
+}
+
+func (m *MockStatementRepository) CreateStatement(ctx context.Context, statement model.Statement) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+type MockDocumentStore struct {
+	mock.Mock
+}
+
+func (m *MockDocumentStore) Put(ctx context.Context, key string, data []byte) error {
+	args := m.Called(ctx, key, data)
+	return args.Error(0)
+}
+
+func TestWorker_generateStatements(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	now := time.Date(2024, 9, 1, 0, 30, 0, 0, time.UTC)
+	periodStart := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should generate statements for previous month", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+
+		statement := model.Statement{UserID: "user-1", Username: "testUser", PeriodStart: periodStart, PeriodEnd: periodEnd,
+			OpeningBalance: 100, Accrued: 50, Withdrawn: 30, ClosingBalance: 120}
+		mockRepo.On("FindStatementsToGenerate", ctx, periodStart, periodEnd, "", batchSize).Return([]model.Statement{statement}, nil)
+		mockStore.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil)
+		mockRepo.On("CreateStatement", ctx, mock.AnythingOfType("model.Statement")).Return(nil).Run(func(args mock.Arguments) {
+			created := args.Get(1).(model.Statement)
+			assert.NotEmpty(t, created.ID)
+			assert.Equal(t, "statements/user-1/2024-08.pdf", created.PdfKey)
+			assert.Equal(t, "statements/user-1/2024-08.csv", created.CsvKey)
+			assert.Equal(t, 120., created.ClosingBalance)
+			assert.Equal(t, now, created.CreateDate)
+		})
+
+		worker := NewWorker(logger, mockRepo, mockStore, time.Hour)
+		worker.generateStatements(ctx, now)
+
+		mockRepo.AssertNumberOfCalls(t, "CreateStatement", 1)
+		mockStore.AssertNumberOfCalls(t, "Put", 2)
+	})
+
+	t.Run("should not create statement if document was not stored", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+
+		statement := model.Statement{Username: "testUser", PeriodStart: periodStart, PeriodEnd: periodEnd}
+		mockRepo.On("FindStatementsToGenerate", ctx, periodStart, periodEnd, "", batchSize).Return([]model.Statement{statement}, nil)
+		mockStore.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything).Return(errors.New("disk error"))
+
+		worker := NewWorker(logger, mockRepo, mockStore, time.Hour)
+		worker.generateStatements(ctx, now)
+
+		mockRepo.AssertNotCalled(t, "CreateStatement", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip user if statement was not generated", func(t *testing.T) {
+		mockRepo := new(MockStatementRepository)
+		mockStore := new(MockDocumentStore)
+
+		failed := model.Statement{UserID: "1", Username: "failedUser", PeriodStart: periodStart, PeriodEnd: periodEnd}
+		generated := model.Statement{UserID: "2", Username: "testUser", PeriodStart: periodStart, PeriodEnd: periodEnd}
+		mockRepo.On("FindStatementsToGenerate", ctx, periodStart, periodEnd, "", batchSize).Return([]model.Statement{failed, generated}, nil)
+		mockStore.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil)
+		mockRepo.On("CreateStatement", ctx, mock.MatchedBy(func(s model.Statement) bool { return s.UserID == "1" })).Return(errors.New("db error"))
+		mockRepo.On("CreateStatement", ctx, mock.MatchedBy(func(s model.Statement) bool { return s.UserID == "2" })).Return(nil)
+
+		worker := NewWorker(logger, mockRepo, mockStore, time.Hour)
+		worker.generateStatements(ctx, now)
+
+		mockRepo.AssertNumberOfCalls(t, "CreateStatement", 2)
+	})
+}
+
+func TestRenderDocuments(t *testing.T) {
+	statement := model.Statement{
+		Username:       "test(User)",
+		PeriodStart:    time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:      time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 100,
+		Accrued:        50.5,
+		Withdrawn:      30,
+		ClosingBalance: 120.5,
+	}
+
+	csvDocument, err := renderCSV(statement)
+	assert.NoError(t, err)
+	assert.Equal(t, "username,period_start,period_end,opening_balance,accrued,withdrawn,closing_balance\n"+
+		"test(User),2024-08-01,2024-08-31,100.00,50.50,30.00,120.50\n", string(csvDocument))
+
+	pdfDocument := renderPDF(statement)
+	assert.True(t, bytes.HasPrefix(pdfDocument, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(pdfDocument, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdfDocument), `(User: test\(User\)) Tj`)
+	assert.Contains(t, string(pdfDocument), "(Period: 2024-08-01 - 2024-08-31) Tj")
+}
@@ -8,7 +8,27 @@ import (
 type userRepository interface {
 	ExistUser(ctx context.Context, userName string) (bool, error)
 
-	CreateUser(ctx context.Context, userName string, password string) error
+	CreateUser(ctx context.Context, user model.User) error
 
-	FindUser(ctx context.Context, userName string) (model.User, error)
+	FindUser(ctx context.Context, identifier string) (model.User, error)
+
+	ChangeUsername(ctx context.Context, userID string, userName string) error
+
+	SaveIdentifier(ctx context.Context, identifier model.UserIdentifier) (model.UserIdentifier, error)
+
+	FindIdentifiers(ctx context.Context, userID string) ([]model.UserIdentifier, error)
+
+	FindIdentifier(ctx context.Context, identifierID string) (model.UserIdentifier, error)
+
+	IncrementVerificationAttempts(ctx context.Context, identifierID string) error
+
+	VerifyIdentifier(ctx context.Context, identifier model.UserIdentifier) error
+
+	DeleteIdentifier(ctx context.Context, userID string, identifierID string) error
+
+	IsUserActive(ctx context.Context, userID string) (bool, error)
+}
+
+type verificationNotifier interface {
+	SendVerificationCode(ctx context.Context, identifier model.UserIdentifier, code string) error
 }
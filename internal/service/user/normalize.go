@@ -0,0 +1,48 @@
+package user
+
+import (
+	"github.com/desepticon55/gofemart/internal/model"
+	"golang.org/x/text/unicode/norm"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	phonePattern    = regexp.MustCompile(`^\+[0-9]{7,15}$`)
+)
+
+func normalizeLogin(login string) string {
+	return strings.ToLower(norm.NFKC.String(strings.TrimSpace(login)))
+}
+
+func normalizeEmail(email string) (string, error) {
+	normalized := normalizeLogin(email)
+	address, err := mail.ParseAddress(normalized)
+	if err != nil || address.Address != normalized {
+		return "", model.ErrIdentifierIsNotValid
+	}
+	return normalized, nil
+}
+
+func normalizePhone(phone string) (string, error) {
+	normalized := phoneSeparators.Replace(norm.NFKC.String(strings.TrimSpace(phone)))
+	if !phonePattern.MatchString(normalized) {
+		return "", model.ErrIdentifierIsNotValid
+	}
+	return normalized, nil
+}
+
+func normalizeIdentifier(identifierType string, value string) (string, string, error) {
+	switch strings.ToUpper(identifierType) {
+	case model.EmailIdentifierType:
+		normalized, err := normalizeEmail(value)
+		return model.EmailIdentifierType, normalized, err
+	case model.PhoneIdentifierType:
+		normalized, err := normalizePhone(value)
+		return model.PhoneIdentifierType, normalized, err
+	default:
+		return "", "", model.ErrIdentifierIsNotValid
+	}
+}
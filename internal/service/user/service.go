@@ -2,59 +2,268 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/logging"
 	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"math/big"
+	"sync"
+	"time"
 )
 
+const activeUserTTL = 30 * time.Second
+
+type activeUser struct {
+	active    bool
+	checkDate time.Time
+}
+
 type UserService struct {
-	logger     *zap.Logger
-	repository userRepository
+	logger      *zap.Logger
+	repository  userRepository
+	notifier    verificationNotifier
+	mu          sync.Mutex
+	activeUsers map[string]activeUser
 }
 
-func NewUserService(l *zap.Logger, r userRepository) *UserService {
-	return &UserService{logger: l, repository: r}
+func NewUserService(l *zap.Logger, r userRepository, n verificationNotifier) *UserService {
+	return &UserService{logger: l, repository: r, notifier: n, activeUsers: make(map[string]activeUser)}
 }
 
-func (s *UserService) CreateUser(ctx context.Context, user model.User) error {
+func (s *UserService) IsUserActive(ctx context.Context, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if user, ok := s.activeUsers[userID]; ok && now.Sub(user.checkDate) < activeUserTTL {
+		return user.active, nil
+	}
+
+	active, err := s.repository.IsUserActive(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during check user is active", zap.String("userID", userID), zap.Error(err))
+		return false, err
+	}
+
+	for id, user := range s.activeUsers {
+		if now.Sub(user.checkDate) >= activeUserTTL {
+			delete(s.activeUsers, id)
+		}
+	}
+	s.activeUsers[userID] = activeUser{active: active, checkDate: now}
+	return active, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, user model.User) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	user.Username = normalizeLogin(user.Username)
 	if user.Username == "" || user.Password == "" {
-		return model.ErrUserDataIsNotValid
+		return model.User{}, model.ErrUserDataIsNotValid
 	}
 
 	exist, err := s.repository.ExistUser(ctx, user.Username)
 	if err != nil {
-		s.logger.Error("Error during check exist user", zap.String("userName", user.Username), zap.Error(err))
-		return err
+		logging.FromContext(ctx, s.logger).Error("Error during check exist user", zap.String("userName", user.Username), zap.Error(err))
+		return model.User{}, err
 	}
 
 	if exist {
-		return model.ErrUserAlreadyExists
+		return model.User{}, model.ErrUserAlreadyExists
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		s.logger.Error("Error during generate password hash", zap.String("userName", user.Username), zap.Error(err))
-		return err
+		logging.FromContext(ctx, s.logger).Error("Error during generate password hash", zap.String("userName", user.Username), zap.Error(err))
+		return model.User{}, err
 	}
 
-	err = s.repository.CreateUser(ctx, user.Username, string(hashedPassword))
+	userID, err := uuid.NewRandom()
 	if err != nil {
-		s.logger.Error("Error during save user", zap.String("userName", user.Username), zap.Error(err))
-		return err
+		logging.FromContext(ctx, s.logger).Error("Error during generate UUID", zap.Error(err))
+		return model.User{}, err
 	}
 
-	return nil
+	user.ID = userID.String()
+	user.Password = string(hashedPassword)
+	err = s.repository.CreateUser(ctx, user)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during save user", zap.String("userName", user.Username), zap.Error(err))
+		return model.User{}, err
+	}
+
+	return user, nil
 }
 
 func (s *UserService) FindUser(ctx context.Context, user model.User) (model.User, error) {
-	if user.Username == "" || user.Password == "" {
+	ctx, span := tracing.Start(ctx, "UserService.FindUser")
+	defer span.End()
+
+	identifier := normalizeLogin(user.Username)
+	if phone, err := normalizePhone(user.Username); err == nil {
+		identifier = phone
+	}
+
+	if identifier == "" || user.Password == "" {
 		return model.User{}, model.ErrUserDataIsNotValid
 	}
 
-	user, err := s.repository.FindUser(ctx, user.Username)
+	foundUser, err := s.repository.FindUser(ctx, identifier)
 	if err != nil {
-		s.logger.Error("Error during find user", zap.String("userName", user.Username), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during find user", zap.String("identifier", identifier), zap.Error(err))
 		return model.User{}, err
 	}
-	return user, nil
+	return foundUser, nil
+}
+
+func (s *UserService) ChangeUsername(ctx context.Context, userName string) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangeUsername")
+	defer span.End()
+
+	userName = normalizeLogin(userName)
+	if userName == "" {
+		return model.ErrUserDataIsNotValid
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	err := s.repository.ChangeUsername(ctx, currentUserID, userName)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during change username", zap.String("userID", currentUserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) FindIdentifiers(ctx context.Context) ([]model.UserIdentifier, error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindIdentifiers")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	identifiers, err := s.repository.FindIdentifiers(ctx, currentUserID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find identifiers", zap.String("userID", currentUserID), zap.Error(err))
+		return []model.UserIdentifier{}, err
+	}
+
+	if len(identifiers) == 0 {
+		return []model.UserIdentifier{}, model.ErrIdentifiersWasNotFound
+	}
+
+	return identifiers, nil
+}
+
+func (s *UserService) AddIdentifier(ctx context.Context, identifierType string, value string) (model.UserIdentifier, error) {
+	ctx, span := tracing.Start(ctx, "UserService.AddIdentifier")
+	defer span.End()
+
+	identifierType, value, err := normalizeIdentifier(identifierType, value)
+	if err != nil {
+		return model.UserIdentifier{}, err
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during generate verification code", zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during generate verification code hash", zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	identifierID, err := uuid.NewRandom()
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during generate UUID", zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	now := time.Now()
+	identifier, err := s.repository.SaveIdentifier(ctx, model.UserIdentifier{
+		ID:             identifierID.String(),
+		UserID:         service.CurrentUserID(ctx),
+		Type:           identifierType,
+		Value:          value,
+		CodeHash:       string(codeHash),
+		CodeExpireDate: now.Add(service.VerificationCodeTTL),
+		CreateDate:     now,
+	})
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during save identifier", zap.String("userID", service.CurrentUserID(ctx)), zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	if err := s.notifier.SendVerificationCode(ctx, identifier, code); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during send verification code", zap.String("identifierID", identifier.ID), zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	return identifier, nil
+}
+
+func (s *UserService) VerifyIdentifier(ctx context.Context, identifierID string, code string) (model.UserIdentifier, error) {
+	ctx, span := tracing.Start(ctx, "UserService.VerifyIdentifier")
+	defer span.End()
+
+	if _, err := uuid.Parse(identifierID); err != nil {
+		return model.UserIdentifier{}, model.ErrIdentifierWasNotFound
+	}
+
+	identifier, err := s.repository.FindIdentifier(ctx, identifierID)
+	if err != nil {
+		return model.UserIdentifier{}, err
+	}
+
+	if identifier.UserID != service.CurrentUserID(ctx) {
+		return model.UserIdentifier{}, model.ErrIdentifierWasNotFound
+	}
+
+	if identifier.Verified {
+		return identifier, nil
+	}
+
+	if identifier.AttemptCount >= service.MaxVerificationAttempts || time.Now().After(identifier.CodeExpireDate) {
+		return model.UserIdentifier{}, model.ErrVerificationCodeIsExpired
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(identifier.CodeHash), []byte(code)); err != nil {
+		if err := s.repository.IncrementVerificationAttempts(ctx, identifier.ID); err != nil {
+			return model.UserIdentifier{}, err
+		}
+		return model.UserIdentifier{}, model.ErrVerificationCodeIsNotValid
+	}
+
+	if err := s.repository.VerifyIdentifier(ctx, identifier); err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during verify identifier", zap.String("identifierID", identifier.ID), zap.Error(err))
+		return model.UserIdentifier{}, err
+	}
+
+	identifier.Verified = true
+	return identifier, nil
+}
+
+func (s *UserService) DeleteIdentifier(ctx context.Context, identifierID string) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteIdentifier")
+	defer span.End()
+
+	if _, err := uuid.Parse(identifierID); err != nil {
+		return model.ErrIdentifierWasNotFound
+	}
+
+	return s.repository.DeleteIdentifier(ctx, service.CurrentUserID(ctx), identifierID)
+}
+
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
 }
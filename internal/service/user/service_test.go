@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
+	"golang.org/x/crypto/bcrypt"
 	"testing"
+	"time"
 )
 
 type MockUserRepository struct {
@@ -20,18 +22,71 @@ func (m *MockUserRepository) ExistUser(ctx context.Context, userName string) (bo
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) CreateUser(ctx context.Context, userName, password string) error {
-	args := m.Called(ctx, userName, password)
+func (m *MockUserRepository) CreateUser(ctx context.Context, user model.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) FindUser(ctx context.Context, userName string) (model.User, error) {
-	args := m.Called(ctx, userName)
+var newUser = mock.MatchedBy(func(user model.User) bool {
+	return user.Username == "newuser" && user.ID != "" && user.Password != "password"
+})
+
+func (m *MockUserRepository) FindUser(ctx context.Context, identifier string) (model.User, error) {
+	args := m.Called(ctx, identifier)
 	return args.Get(0).(model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) IsUserActive(ctx context.Context, userID string) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ChangeUsername(ctx context.Context, userID string, userName string) error {
+	args := m.Called(ctx, userID, userName)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SaveIdentifier(ctx context.Context, identifier model.UserIdentifier) (model.UserIdentifier, error) {
+	args := m.Called(ctx, identifier)
+	return args.Get(0).(model.UserIdentifier), args.Error(1)
+}
+
+func (m *MockUserRepository) FindIdentifiers(ctx context.Context, userID string) ([]model.UserIdentifier, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.UserIdentifier), args.Error(1)
+}
+
+func (m *MockUserRepository) FindIdentifier(ctx context.Context, identifierID string) (model.UserIdentifier, error) {
+	args := m.Called(ctx, identifierID)
+	return args.Get(0).(model.UserIdentifier), args.Error(1)
+}
+
+func (m *MockUserRepository) IncrementVerificationAttempts(ctx context.Context, identifierID string) error {
+	args := m.Called(ctx, identifierID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyIdentifier(ctx context.Context, identifier model.UserIdentifier) error {
+	args := m.Called(ctx, identifier)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteIdentifier(ctx context.Context, userID string, identifierID string) error {
+	args := m.Called(ctx, userID, identifierID)
+	return args.Error(0)
+}
+
+type MockVerificationNotifier struct {
+	mock.Mock
+}
+
+func (m *MockVerificationNotifier) SendVerificationCode(ctx context.Context, identifier model.UserIdentifier, code string) error {
+	args := m.Called(ctx, identifier, code)
+	return args.Error(0)
+}
+
 func TestUserService_CreateUser(t *testing.T) {
-	ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 	logger := zaptest.NewLogger(t)
 
 	t.Run("should successfully create user", func(t *testing.T) {
@@ -42,14 +97,16 @@ func TestUserService_CreateUser(t *testing.T) {
 		}
 
 		user := model.User{Username: "newUser", Password: "password"}
-		mockRepo.On("ExistUser", ctx, "newUser").Return(false, nil)
-		mockRepo.On("CreateUser", ctx, "newUser", mock.Anything).Return(nil)
+		mockRepo.On("ExistUser", ctx, "newuser").Return(false, nil)
+		mockRepo.On("CreateUser", ctx, newUser).Return(nil)
 
-		err := service.CreateUser(ctx, user)
+		createdUser, err := service.CreateUser(ctx, user)
 		assert.NoError(t, err)
+		assert.NotEmpty(t, createdUser.ID)
+		assert.Equal(t, "newuser", createdUser.Username)
 
-		mockRepo.AssertCalled(t, "ExistUser", ctx, "newUser")
-		mockRepo.AssertCalled(t, "CreateUser", ctx, "newUser", mock.Anything)
+		mockRepo.AssertCalled(t, "ExistUser", ctx, "newuser")
+		mockRepo.AssertCalled(t, "CreateUser", ctx, newUser)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -61,14 +118,14 @@ func TestUserService_CreateUser(t *testing.T) {
 		}
 
 		user := model.User{Username: "newUser", Password: "password"}
-		mockRepo.On("ExistUser", ctx, "newUser").Return(true, nil)
+		mockRepo.On("ExistUser", ctx, "newuser").Return(true, nil)
 
-		err := service.CreateUser(ctx, user)
+		_, err := service.CreateUser(ctx, user)
 		assert.Error(t, err)
 		assert.Equal(t, err, model.ErrUserAlreadyExists)
 
-		mockRepo.AssertCalled(t, "ExistUser", ctx, "newUser")
-		mockRepo.AssertNotCalled(t, "CreateUser", ctx, "newUser", mock.Anything)
+		mockRepo.AssertCalled(t, "ExistUser", ctx, "newuser")
+		mockRepo.AssertNotCalled(t, "CreateUser", ctx, newUser)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -81,14 +138,14 @@ func TestUserService_CreateUser(t *testing.T) {
 
 		expectedError := errors.New("database error")
 		user := model.User{Username: "newUser", Password: "password"}
-		mockRepo.On("ExistUser", ctx, "newUser").Return(false, expectedError)
+		mockRepo.On("ExistUser", ctx, "newuser").Return(false, expectedError)
 
-		err := service.CreateUser(ctx, user)
+		_, err := service.CreateUser(ctx, user)
 		assert.Error(t, err)
 		assert.Equal(t, err, expectedError)
 
-		mockRepo.AssertCalled(t, "ExistUser", ctx, "newUser")
-		mockRepo.AssertNotCalled(t, "CreateUser", ctx, "newUser", mock.Anything)
+		mockRepo.AssertCalled(t, "ExistUser", ctx, "newuser")
+		mockRepo.AssertNotCalled(t, "CreateUser", ctx, newUser)
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -101,21 +158,21 @@ func TestUserService_CreateUser(t *testing.T) {
 
 		expectedError := errors.New("database error")
 		user := model.User{Username: "newUser", Password: "password"}
-		mockRepo.On("ExistUser", ctx, "newUser").Return(false, nil)
-		mockRepo.On("CreateUser", ctx, "newUser", mock.Anything).Return(expectedError)
+		mockRepo.On("ExistUser", ctx, "newuser").Return(false, nil)
+		mockRepo.On("CreateUser", ctx, newUser).Return(expectedError)
 
-		err := service.CreateUser(ctx, user)
+		_, err := service.CreateUser(ctx, user)
 		assert.Error(t, err)
 		assert.Equal(t, err, expectedError)
 
-		mockRepo.AssertCalled(t, "ExistUser", ctx, "newUser")
-		mockRepo.AssertCalled(t, "CreateUser", ctx, "newUser", mock.Anything)
+		mockRepo.AssertCalled(t, "ExistUser", ctx, "newuser")
+		mockRepo.AssertCalled(t, "CreateUser", ctx, newUser)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestUserService_FindUser(t *testing.T) {
-	ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 	logger := zaptest.NewLogger(t)
 
 	t.Run("should return found user", func(t *testing.T) {
@@ -126,13 +183,13 @@ func TestUserService_FindUser(t *testing.T) {
 		}
 
 		user := model.User{Username: "newUser", Password: "password"}
-		mockRepo.On("FindUser", ctx, "newUser").Return(user, nil)
+		mockRepo.On("FindUser", ctx, "newuser").Return(user, nil)
 
 		foundUser, err := service.FindUser(ctx, user)
 		assert.NoError(t, err)
 		assert.Equal(t, user, foundUser)
 
-		mockRepo.AssertCalled(t, "FindUser", ctx, "newUser")
+		mockRepo.AssertCalled(t, "FindUser", ctx, "newuser")
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -145,13 +202,13 @@ func TestUserService_FindUser(t *testing.T) {
 
 		user := model.User{Username: "newUser", Password: "password"}
 		expectedError := errors.New("database error")
-		mockRepo.On("FindUser", ctx, "newUser").Return(model.User{}, expectedError)
+		mockRepo.On("FindUser", ctx, "newuser").Return(model.User{}, expectedError)
 
 		_, err := service.FindUser(ctx, user)
 		assert.Error(t, err)
 		assert.Equal(t, expectedError, err)
 
-		mockRepo.AssertCalled(t, "FindUser", ctx, "newUser")
+		mockRepo.AssertCalled(t, "FindUser", ctx, "newuser")
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -168,7 +225,226 @@ func TestUserService_FindUser(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, model.ErrUserDataIsNotValid, err)
 
-		mockRepo.AssertNotCalled(t, "FindUser", ctx, "newUser")
+		mockRepo.AssertNotCalled(t, "FindUser", ctx, "newuser")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_Normalization(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should normalize login with NFKC and lowercase", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("ExistUser", ctx, "newuser").Return(true, nil)
+
+		_, err := service.CreateUser(ctx, model.User{Username: " ＮｅｗＵｓｅｒ ", Password: "password"})
+		assert.Equal(t, model.ErrUserAlreadyExists, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should find user by normalized phone", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("FindUser", ctx, "+79991234567").Return(model.User{ID: "1"}, nil)
+
+		foundUser, err := service.FindUser(ctx, model.User{Username: "+7 (999) 123-45-67", Password: "password"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", foundUser.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should find user by normalized email", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("FindUser", ctx, "user@example.com").Return(model.User{ID: "1"}, nil)
+
+		_, err := service.FindUser(ctx, model.User{Username: "User@Example.COM", Password: "password"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_ChangeUsername(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "1", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should change username", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("ChangeUsername", ctx, "1", "renamed").Return(nil)
+
+		err := service.ChangeUsername(ctx, "Renamed")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return error if username is taken", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("ChangeUsername", ctx, "1", "renamed").Return(model.ErrUserAlreadyExists)
+
+		err := service.ChangeUsername(ctx, "renamed")
+		assert.Equal(t, model.ErrUserAlreadyExists, err)
+	})
+
+	t.Run("should return error if username is empty", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		err := service.ChangeUsername(ctx, "  ")
+		assert.Equal(t, model.ErrUserDataIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "ChangeUsername", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_AddIdentifier(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "1", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should save identifier and send verification code", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockNotifier := new(MockVerificationNotifier)
+		service := NewUserService(logger, mockRepo, mockNotifier)
+
+		var savedIdentifier model.UserIdentifier
+		mockRepo.On("SaveIdentifier", ctx, mock.AnythingOfType("model.UserIdentifier")).
+			Run(func(args mock.Arguments) { savedIdentifier = args.Get(1).(model.UserIdentifier) }).
+			Return(model.UserIdentifier{ID: "saved"}, nil)
+		mockNotifier.On("SendVerificationCode", ctx, mock.AnythingOfType("model.UserIdentifier"), mock.AnythingOfType("string")).Return(nil)
+
+		identifier, err := service.AddIdentifier(ctx, "email", " User@Example.com ")
+		assert.NoError(t, err)
+		assert.Equal(t, "saved", identifier.ID)
+		assert.Equal(t, model.EmailIdentifierType, savedIdentifier.Type)
+		assert.Equal(t, "user@example.com", savedIdentifier.Value)
+		assert.Equal(t, "1", savedIdentifier.UserID)
+		assert.False(t, savedIdentifier.Verified)
+
+		code := mockNotifier.Calls[0].Arguments.String(2)
+		assert.Len(t, code, 6)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(savedIdentifier.CodeHash), []byte(code)))
+	})
+
+	t.Run("should return error if identifier is not valid", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		_, err := service.AddIdentifier(ctx, "phone", "not a phone")
+		assert.Equal(t, model.ErrIdentifierIsNotValid, err)
+
+		_, err = service.AddIdentifier(ctx, "email", "Name <user@example.com>")
+		assert.Equal(t, model.ErrIdentifierIsNotValid, err)
+
+		_, err = service.AddIdentifier(ctx, "fax", "123")
+		assert.Equal(t, model.ErrIdentifierIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "SaveIdentifier", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_VerifyIdentifier(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "1", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+	identifierID := "0f8c2d6e-3b1a-4c5d-9e7f-1a2b3c4d5e6f"
+	codeHash, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	pending := model.UserIdentifier{ID: identifierID, UserID: "1", Type: model.PhoneIdentifierType, Value: "+79991234567",
+		CodeHash: string(codeHash), CodeExpireDate: time.Now().Add(time.Minute)}
+
+	t.Run("should verify identifier", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("FindIdentifier", ctx, identifierID).Return(pending, nil)
+		mockRepo.On("VerifyIdentifier", ctx, pending).Return(nil)
+
+		identifier, err := service.VerifyIdentifier(ctx, identifierID, "123456")
+		assert.NoError(t, err)
+		assert.True(t, identifier.Verified)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("should count failed attempt if code is wrong", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		mockRepo.On("FindIdentifier", ctx, identifierID).Return(pending, nil)
+		mockRepo.On("IncrementVerificationAttempts", ctx, identifierID).Return(nil)
+
+		_, err := service.VerifyIdentifier(ctx, identifierID, "654321")
+		assert.Equal(t, model.ErrVerificationCodeIsNotValid, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "VerifyIdentifier", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error if code is expired or attempts are exhausted", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		expired := pending
+		expired.CodeExpireDate = time.Now().Add(-time.Minute)
+		exhausted := pending
+		exhausted.ID = "1c9e8d7f-6a5b-4c3d-8e2f-0a1b2c3d4e5f"
+		exhausted.AttemptCount = 5
+		mockRepo.On("FindIdentifier", ctx, identifierID).Return(expired, nil)
+		mockRepo.On("FindIdentifier", ctx, exhausted.ID).Return(exhausted, nil)
+
+		_, err := service.VerifyIdentifier(ctx, identifierID, "123456")
+		assert.Equal(t, model.ErrVerificationCodeIsExpired, err)
+
+		_, err = service.VerifyIdentifier(ctx, exhausted.ID, "123456")
+		assert.Equal(t, model.ErrVerificationCodeIsExpired, err)
+	})
+
+	t.Run("should return error if identifier belongs to other user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(logger, mockRepo, nil)
+
+		other := pending
+		other.UserID = "2"
+		mockRepo.On("FindIdentifier", ctx, identifierID).Return(other, nil)
+
+		_, err := service.VerifyIdentifier(ctx, identifierID, "123456")
+		assert.Equal(t, model.ErrIdentifierWasNotFound, err)
+
+		_, err = service.VerifyIdentifier(ctx, "not-uuid", "123456")
+		assert.Equal(t, model.ErrIdentifierWasNotFound, err)
+	})
+}
+
+func TestUserService_IsUserActive(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should cache answer of repository", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("IsUserActive", ctx, "deleted").Return(false, nil).Once()
+		service := NewUserService(logger, mockRepo, nil)
+
+		for i := 0; i < 2; i++ {
+			active, err := service.IsUserActive(ctx, "deleted")
+			assert.NoError(t, err)
+			assert.False(t, active)
+		}
+		mockRepo.AssertNumberOfCalls(t, "IsUserActive", 1)
+	})
+
+	t.Run("should not cache error", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("IsUserActive", ctx, "user").Return(false, errors.New("db error")).Once()
+		mockRepo.On("IsUserActive", ctx, "user").Return(true, nil).Once()
+		service := NewUserService(logger, mockRepo, nil)
+
+		_, err := service.IsUserActive(ctx, "user")
+		assert.Error(t, err)
+
+		active, err := service.IsUserActive(ctx, "user")
+		assert.NoError(t, err)
+		assert.True(t, active)
+	})
 }
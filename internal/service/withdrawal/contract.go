@@ -7,4 +7,6 @@ import (
 
 type withdrawalRepository interface {
 	FindAllWithdrawals(ctx context.Context, userName string) ([]model.Withdrawal, error)
+
+	FindWithdrawalsPage(ctx context.Context, userName string, page model.PageRequest) (model.Page[model.Withdrawal], error)
 }
@@ -2,9 +2,11 @@ package withdrawal
 
 import (
 	"context"
-	"fmt"
+	"github.com/desepticon55/gofemart/internal/logging"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/desepticon55/gofemart/internal/service"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -18,10 +20,13 @@ func NewWithdrawalService(l *zap.Logger, r withdrawalRepository) *WithdrawalServ
 }
 
 func (s *WithdrawalService) FindAllWithdrawals(ctx context.Context) ([]model.Withdrawal, error) {
-	currentUserName := fmt.Sprintf("%v", ctx.Value(service.UserNameContextKey))
-	withdrawals, err := s.withdrawalRepository.FindAllWithdrawals(ctx, currentUserName)
+	ctx, span := tracing.Start(ctx, "WithdrawalService.FindAllWithdrawals")
+	defer span.End()
+
+	currentUserID := service.CurrentUserID(ctx)
+	withdrawals, err := s.withdrawalRepository.FindAllWithdrawals(ctx, currentUserID)
 	if err != nil {
-		s.logger.Error("Error during find withdrawals", zap.String("userName", currentUserName), zap.Error(err))
+		logging.FromContext(ctx, s.logger).Error("Error during find withdrawals", zap.String("userID", currentUserID), zap.Error(err))
 		return []model.Withdrawal{}, err
 	}
 
@@ -31,3 +36,27 @@ func (s *WithdrawalService) FindAllWithdrawals(ctx context.Context) ([]model.Wit
 
 	return withdrawals, nil
 }
+
+func (s *WithdrawalService) FindWithdrawalsPage(ctx context.Context, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+	ctx, span := tracing.Start(ctx, "WithdrawalService.FindWithdrawalsPage")
+	defer span.End()
+
+	if page.Cursor != nil {
+		if _, err := uuid.Parse(page.Cursor.Key); err != nil {
+			return model.Page[model.Withdrawal]{}, model.ErrPageRequestIsNotValid
+		}
+	}
+
+	currentUserID := service.CurrentUserID(ctx)
+	result, err := s.withdrawalRepository.FindWithdrawalsPage(ctx, currentUserID, page)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Error during find withdrawals page", zap.String("userID", currentUserID), zap.Error(err))
+		return model.Page[model.Withdrawal]{}, err
+	}
+
+	if len(result.Items) == 0 {
+		return model.Page[model.Withdrawal]{}, model.ErrWithdrawalsWasNotFound
+	}
+
+	return result, nil
+}
@@ -21,8 +21,13 @@ func (m *MockWithdrawalRepository) FindAllWithdrawals(ctx context.Context, userN
 	return args.Get(0).([]model.Withdrawal), args.Error(1)
 }
 
+func (m *MockWithdrawalRepository) FindWithdrawalsPage(ctx context.Context, userName string, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+	args := m.Called(ctx, userName, page)
+	return args.Get(0).(model.Page[model.Withdrawal]), args.Error(1)
+}
+
 func TestWithdrawalService_TestFindAllWithdrawals(t *testing.T) {
-	ctx := context.WithValue(context.Background(), service.UserNameContextKey, "testUser")
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
 	logger := zaptest.NewLogger(t)
 
 	t.Run("should return found withdrawals", func(t *testing.T) {
@@ -33,8 +38,8 @@ func TestWithdrawalService_TestFindAllWithdrawals(t *testing.T) {
 		}
 
 		expectedWithdrawals := []model.Withdrawal{
-			{ID: "1", Username: "testUser", OrderNumber: "123456", Sum: 100.0},
-			{ID: "2", Username: "testUser", OrderNumber: "123456", Sum: 200.0},
+			{ID: "1", UserID: "testUser", OrderNumber: "123456", Sum: 100.0},
+			{ID: "2", UserID: "testUser", OrderNumber: "123456", Sum: 200.0},
 		}
 		mockRepo.On("FindAllWithdrawals", ctx, "testUser").Return(expectedWithdrawals, nil)
 
@@ -83,3 +88,51 @@ func TestWithdrawalService_TestFindAllWithdrawals(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestWithdrawalService_FindWithdrawalsPage(t *testing.T) {
+	ctx := service.WithPrincipal(context.Background(), service.Principal{UserID: "testUser", Username: "testUser"})
+	logger := zaptest.NewLogger(t)
+
+	t.Run("should return found page", func(t *testing.T) {
+		mockRepo := new(MockWithdrawalRepository)
+		service := &WithdrawalService{
+			withdrawalRepository: mockRepo,
+			logger:               logger,
+		}
+
+		page := model.PageRequest{Limit: 1}
+		expected := model.Page[model.Withdrawal]{Items: []model.Withdrawal{{ID: "1", Sum: 100.0}}, NextCursor: "next"}
+		mockRepo.On("FindWithdrawalsPage", ctx, "testUser", page).Return(expected, nil)
+
+		result, err := service.FindWithdrawalsPage(ctx, page)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("should return error when page is empty", func(t *testing.T) {
+		mockRepo := new(MockWithdrawalRepository)
+		service := &WithdrawalService{
+			withdrawalRepository: mockRepo,
+			logger:               logger,
+		}
+
+		page := model.PageRequest{Limit: 1}
+		mockRepo.On("FindWithdrawalsPage", ctx, "testUser", page).Return(model.Page[model.Withdrawal]{}, nil)
+
+		_, err := service.FindWithdrawalsPage(ctx, page)
+		assert.Equal(t, model.ErrWithdrawalsWasNotFound, err)
+	})
+	t.Run("should return error when cursor key is not valid", func(t *testing.T) {
+		mockRepo := new(MockWithdrawalRepository)
+		service := &WithdrawalService{
+			withdrawalRepository: mockRepo,
+			logger:               logger,
+		}
+
+		page := model.PageRequest{Limit: 1, Cursor: &model.PageCursor{Key: "not-uuid"}}
+
+		_, err := service.FindWithdrawalsPage(ctx, page)
+		assert.Equal(t, model.ErrPageRequestIsNotValid, err)
+		mockRepo.AssertNotCalled(t, "FindWithdrawalsPage", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type AccountRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewAccountRepository(pool *pgxpool.Pool, logger *zap.Logger) *AccountRepository {
+	return &AccountRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *AccountRepository) RequestDeletion(ctx context.Context, deletion model.AccountDeletion) error {
+	var payoutID *string
+	if deletion.PayoutID != "" {
+		payoutID = &deletion.PayoutID
+	}
+
+	query := `update gofemart.user set delete_after = $1, balance_settlement = $2, payout_id = $3
+			  where id = $4 and delete_after is null and deleted_date is null`
+	result, err := r.pool.Exec(ctx, query, deletion.DeleteAfter, deletion.Settlement, payoutID, deletion.UserID)
+	if err != nil {
+		r.logger.Error("Error during request account deletion", zap.String("userID", deletion.UserID), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrAccountDeletionIsAlreadyRequested
+	}
+	return nil
+}
+
+func (r *AccountRepository) CancelDeletion(ctx context.Context, userID string, now time.Time) error {
+	query := `update gofemart.user set delete_after = null, balance_settlement = null, payout_id = null
+			  where id = $1 and delete_after > $2`
+	result, err := r.pool.Exec(ctx, query, userID, now)
+	if err != nil {
+		r.logger.Error("Error during cancel account deletion", zap.String("userID", userID), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrAccountDeletionWasNotFound
+	}
+	return nil
+}
+
+func (r *AccountRepository) FindAccountsToDelete(ctx context.Context, now time.Time, limit int) ([]model.AccountDeletion, error) {
+	query := `select id, balance_settlement, coalesce(payout_id::text, ''), delete_after
+			  from gofemart.user where delete_after <= $1
+			  order by delete_after
+			  limit $2`
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deletions []model.AccountDeletion
+	for rows.Next() {
+		var deletion model.AccountDeletion
+		if err := rows.Scan(&deletion.UserID, &deletion.Settlement, &deletion.PayoutID, &deletion.DeleteAfter); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		deletions = append(deletions, deletion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deletions, nil
+}
+
+func (r *AccountRepository) DeleteAccount(ctx context.Context, deletion model.AccountDeletion, anonymousName string, now time.Time) ([]string, error) {
+	var documentKeys []string
+	err := transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		userQuery := "select id from gofemart.user where id = $1 and delete_after <= $2 for update"
+		if err := tx.QueryRow(ctx, userQuery, deletion.UserID, now).Scan(new(string)); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.ErrAccountDeletionWasNotFound
+			}
+			return err
+		}
+
+		holdQuery := "update gofemart.hold set status = $1, last_modify_date = $2 where user_id = $3 and status = $4"
+		if _, err := tx.Exec(ctx, holdQuery, model.VoidedHoldStatus, now, deletion.UserID, model.AuthorizedHoldStatus); err != nil {
+			r.logger.Error("Error during void holds", zap.String("userID", deletion.UserID), zap.Error(err))
+			return err
+		}
+
+		var remaining float64
+		balanceQuery := "select balance from gofemart.balance where user_id = $1 for update"
+		if err := tx.QueryRow(ctx, balanceQuery, deletion.UserID).Scan(&remaining); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("Error during find balance", zap.String("userID", deletion.UserID), zap.Error(err))
+			return err
+		}
+
+		rows, err := tx.Query(ctx, "delete from gofemart.statement where user_id = $1 returning pdf_key, csv_key", deletion.UserID)
+		if err != nil {
+			r.logger.Error("Error during delete statements", zap.String("userID", deletion.UserID), zap.Error(err))
+			return err
+		}
+		for rows.Next() {
+			var pdfKey, csvKey string
+			if err := rows.Scan(&pdfKey, &csvKey); err != nil {
+				rows.Close()
+				return err
+			}
+			documentKeys = append(documentKeys, pdfKey, csvKey)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if remaining > 0 {
+			if err := r.settleBalance(ctx, tx, deletion, remaining, now); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, "delete from gofemart.user_identifier where user_id = $1", deletion.UserID); err != nil {
+			r.logger.Error("Error during delete identifiers", zap.String("userID", deletion.UserID), zap.Error(err))
+			return err
+		}
+
+		userQuery = `update gofemart.user
+					 set username = $1, password = '', deleted_date = $2, delete_after = null, balance_settlement = null, payout_id = null
+					 where id = $3`
+		if _, err := tx.Exec(ctx, userQuery, anonymousName, now, deletion.UserID); err != nil {
+			r.logger.Error("Error during anonymize user", zap.String("userID", deletion.UserID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documentKeys, nil
+}
+
+func (r *AccountRepository) settleBalance(ctx context.Context, tx pgx.Tx, deletion model.AccountDeletion, sum float64, now time.Time) error {
+	withdrawID, err := uuid.NewRandom()
+	if err != nil {
+		r.logger.Error("Error during generate UUID", zap.Error(err))
+		return err
+	}
+
+	reference := model.ForfeitBalanceSettlement
+	if deletion.Settlement == model.PayoutBalanceSettlement {
+		reference = deletion.PayoutID
+		payoutQuery := "insert into gofemart.payout(id, user_id, sum, status, create_date) values ($1, $2, $3, $4, $5)"
+		if _, err := tx.Exec(ctx, payoutQuery, deletion.PayoutID, deletion.UserID, sum, model.PendingPayoutStatus, now); err != nil {
+			r.logger.Error("Error during create payout", zap.String("payoutID", deletion.PayoutID), zap.Error(err))
+			return err
+		}
+	}
+
+	withdrawQuery := "insert into gofemart.withdrawal(id, order_number, user_id, sum, create_date) values ($1, $2, $3, $4, $5)"
+	if _, err := tx.Exec(ctx, withdrawQuery, withdrawID, reference, deletion.UserID, sum, now); err != nil {
+		r.logger.Error("Error during create withdrawal", zap.String("userID", deletion.UserID), zap.Error(err))
+		return err
+	}
+
+	balanceQuery := "update gofemart.balance set balance = 0, reserved = 0, opt_lock = opt_lock + 1 where user_id = $1"
+	if _, err := tx.Exec(ctx, balanceQuery, deletion.UserID); err != nil {
+		r.logger.Error("Error during change balance", zap.String("userID", deletion.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *AccountRepository) FindUserData(ctx context.Context, userID string) (model.UserData, error) {
+	var data model.UserData
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return model.UserData{}, fmt.Errorf("error during open transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	profileQuery := "select id, username, delete_after from gofemart.user where id = $1 and deleted_date is null"
+	err = tx.QueryRow(ctx, profileQuery, userID).Scan(&data.Profile.ID, &data.Profile.Username, &data.Profile.DeleteAfter)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.UserData{}, model.ErrUserWasNotFound
+		}
+		return model.UserData{}, err
+	}
+
+	balanceQuery := "select user_id, balance, reserved, opt_lock from gofemart.balance where user_id = $1"
+	err = tx.QueryRow(ctx, balanceQuery, userID).Scan(&data.Balance.UserID, &data.Balance.Balance, &data.Balance.Reserved, &data.Balance.Version)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return model.UserData{}, err
+	}
+
+	ordersQuery := `select order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock
+					from gofemart.order where user_id = $1 order by create_date`
+	data.Orders, err = queryAll(ctx, tx, ordersQuery, userID, func(rows pgx.Rows, order *model.OrderWithHistory) error {
+		return rows.Scan(&order.Order.OrderNumber, &order.Order.UserID, &order.Order.CreateDate, &order.Order.LastModifyDate,
+			&order.Order.Status, &order.Order.Accrual, &order.Order.KeyHash, &order.Order.KeyHashModule, &order.Order.Version)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	type transition struct {
+		orderNumber string
+		model.OrderStatusTransition
+	}
+	historyQuery := `select h.order_number, h.status, h.accrual, h.create_date
+					 from gofemart.order_status_history h join gofemart.order o on o.order_number = h.order_number
+					 where o.user_id = $1 order by h.create_date, h.id`
+	transitions, err := queryAll(ctx, tx, historyQuery, userID, func(rows pgx.Rows, t *transition) error {
+		return rows.Scan(&t.orderNumber, &t.Status, &t.Accrual, &t.CreateDate)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	orderIndexes := make(map[string]int, len(data.Orders))
+	for i, order := range data.Orders {
+		orderIndexes[order.Order.OrderNumber] = i
+	}
+	for _, t := range transitions {
+		if i, ok := orderIndexes[t.orderNumber]; ok {
+			data.Orders[i].History = append(data.Orders[i].History, t.OrderStatusTransition)
+		}
+	}
+
+	withdrawalsQuery := `select id, order_number, user_id, sum, create_date, coalesce(reward_id::text, '')
+						 from gofemart.withdrawal where user_id = $1 order by create_date`
+	data.Withdrawals, err = queryAll(ctx, tx, withdrawalsQuery, userID, func(rows pgx.Rows, withdrawal *model.Withdrawal) error {
+		return rows.Scan(&withdrawal.ID, &withdrawal.OrderNumber, &withdrawal.UserID, &withdrawal.Sum, &withdrawal.CreateDate, &withdrawal.RewardID)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	holdsQuery := `select id, order_number, user_id, sum, status, create_date, last_modify_date, expire_date
+				   from gofemart.hold where user_id = $1 order by create_date`
+	data.Holds, err = queryAll(ctx, tx, holdsQuery, userID, func(rows pgx.Rows, hold *model.Hold) error {
+		return rows.Scan(&hold.ID, &hold.OrderNumber, &hold.UserID, &hold.Sum, &hold.Status, &hold.CreateDate, &hold.LastModifyDate, &hold.ExpireDate)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	redemptionsQuery := `select id, reward_id, user_id, code, sum, create_date
+						 from gofemart.redemption where user_id = $1 order by create_date`
+	data.Redemptions, err = queryAll(ctx, tx, redemptionsQuery, userID, func(rows pgx.Rows, redemption *model.Redemption) error {
+		return rows.Scan(&redemption.ID, &redemption.RewardID, &redemption.UserID, &redemption.Code, &redemption.Sum, &redemption.CreateDate)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	statementsQuery := `select id, user_id, period_start, period_end, opening_balance, accrued, withdrawn, closing_balance, pdf_key, csv_key, create_date
+						from gofemart.statement where user_id = $1 order by period_start`
+	data.Statements, err = queryAll(ctx, tx, statementsQuery, userID, func(rows pgx.Rows, statement *model.Statement) error {
+		return rows.Scan(&statement.ID, &statement.UserID, &statement.PeriodStart, &statement.PeriodEnd, &statement.OpeningBalance,
+			&statement.Accrued, &statement.Withdrawn, &statement.ClosingBalance, &statement.PdfKey, &statement.CsvKey, &statement.CreateDate)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	identifiersQuery := `select id, user_id, type, value, verified, attempt_count, create_date
+						 from gofemart.user_identifier where user_id = $1 order by create_date`
+	data.Identifiers, err = queryAll(ctx, tx, identifiersQuery, userID, func(rows pgx.Rows, identifier *model.UserIdentifier) error {
+		return rows.Scan(&identifier.ID, &identifier.UserID, &identifier.Type, &identifier.Value, &identifier.Verified,
+			&identifier.AttemptCount, &identifier.CreateDate)
+	})
+	if err != nil {
+		return model.UserData{}, err
+	}
+
+	return data, nil
+}
+
+func queryAll[T any](ctx context.Context, tx pgx.Tx, query string, userID string, scan func(pgx.Rows, *T) error) ([]T, error) {
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var item T
+		if err := scan(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestAccountRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	accountRepository := NewAccountRepository(pool, logger)
+	userRepository := NewUserRepository(pool, logger)
+	now := time.Now()
+	testUser := model.User{ID: internal.TestUserID, Username: "testUser", Password: "password"}
+
+	t.Run("RequestDeletion and CancelDeletion", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		err := userRepository.CreateUser(ctx, testUser)
+		assert.NoError(t, err)
+
+		deletion := model.AccountDeletion{UserID: internal.TestUserID, Settlement: model.ForfeitBalanceSettlement, DeleteAfter: now.Add(time.Hour)}
+		err = accountRepository.RequestDeletion(ctx, deletion)
+		assert.NoError(t, err)
+
+		err = accountRepository.RequestDeletion(ctx, deletion)
+		assert.Equal(t, model.ErrAccountDeletionIsAlreadyRequested, err)
+
+		deletions, err := accountRepository.FindAccountsToDelete(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(deletions))
+
+		err = accountRepository.CancelDeletion(ctx, internal.TestUserID, now)
+		assert.NoError(t, err)
+
+		err = accountRepository.CancelDeletion(ctx, internal.TestUserID, now)
+		assert.Equal(t, model.ErrAccountDeletionWasNotFound, err)
+	})
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		err := userRepository.CreateUser(ctx, testUser)
+		assert.NoError(t, err)
+		if _, err := pool.Exec(ctx, `UPDATE gofemart.balance SET balance = 70 WHERE user_id = $1`, internal.TestUserID); err != nil {
+			t.Fatalf("failed to update balance: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.withdrawal (id, order_number, user_id, sum, create_date) VALUES ($1, $2, $3, $4, $5)`,
+			"0b6b1a9e-2f4c-4b5a-8d1e-3c7f9a2b4d10", "79927398713", internal.TestUserID, 30, now); err != nil {
+			t.Fatalf("failed to insert withdrawal: %v", err)
+		}
+
+		deletion := model.AccountDeletion{UserID: internal.TestUserID, Settlement: model.PayoutBalanceSettlement,
+			PayoutID: "5f0c3a8e-1b2d-4c6e-9f7a-2d4b6c8e0a13", DeleteAfter: now.Add(-time.Minute)}
+		err = accountRepository.RequestDeletion(ctx, deletion)
+		assert.NoError(t, err)
+
+		deletions, err := accountRepository.FindAccountsToDelete(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{internal.TestUserID}, []string{deletions[0].UserID})
+
+		_, err = accountRepository.DeleteAccount(ctx, deletions[0], "deleted-1", now)
+		assert.NoError(t, err)
+
+		exist, err := userRepository.ExistUser(ctx, "testUser")
+		assert.NoError(t, err)
+		assert.False(t, exist)
+
+		var userName string
+		err = pool.QueryRow(ctx, `SELECT username FROM gofemart.user WHERE id = $1`, internal.TestUserID).Scan(&userName)
+		assert.NoError(t, err)
+		assert.Equal(t, "deleted-1", userName)
+
+		var withdrawn float64
+		err = pool.QueryRow(ctx, `SELECT sum(sum) FROM gofemart.withdrawal WHERE user_id = $1`, internal.TestUserID).Scan(&withdrawn)
+		assert.NoError(t, err)
+		assert.Equal(t, 100., withdrawn)
+
+		var payout float64
+		err = pool.QueryRow(ctx, `SELECT sum FROM gofemart.payout WHERE id = $1`, deletion.PayoutID).Scan(&payout)
+		assert.NoError(t, err)
+		assert.Equal(t, 70., payout)
+
+		_, err = accountRepository.FindUserData(ctx, internal.TestUserID)
+		assert.Equal(t, model.ErrUserWasNotFound, err)
+	})
+
+	t.Run("FindUserData", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		err := userRepository.CreateUser(ctx, testUser)
+		assert.NoError(t, err)
+		orderRepository := NewOrderRepository(pool, logger)
+		err = orderRepository.CreateOrder(ctx, model.Order{OrderNumber: "12345678903", UserID: internal.TestUserID, Status: model.NewOrderStatus,
+			CreateDate: now, LastModifyDate: now})
+		assert.NoError(t, err)
+
+		data, err := accountRepository.FindUserData(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, "testUser", data.Profile.Username)
+		assert.Equal(t, 1, len(data.Orders))
+		assert.Equal(t, 1, len(data.Orders[0].History))
+	})
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
@@ -22,10 +23,10 @@ func NewBalanceRepository(pool *pgxpool.Pool, logger *zap.Logger) *BalanceReposi
 	}
 }
 
-func (r *BalanceRepository) FindBalance(ctx context.Context, userName string) (model.Balance, error) {
-	query := "select username, balance, opt_lock from gofemart.balance where username = $1"
+func (r *BalanceRepository) FindBalance(ctx context.Context, userID string) (model.Balance, error) {
+	query := "select user_id, balance, reserved, opt_lock from gofemart.balance where user_id = $1"
 	var balance model.Balance
-	err := r.pool.QueryRow(ctx, query, userName).Scan(&balance.Username, &balance.Balance, &balance.Version)
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&balance.UserID, &balance.Balance, &balance.Reserved, &balance.Version)
 	if err != nil {
 		return model.Balance{}, err
 	}
@@ -33,16 +34,16 @@ func (r *BalanceRepository) FindBalance(ctx context.Context, userName string) (m
 	return balance, nil
 }
 
-func (r *BalanceRepository) FindBalanceStats(ctx context.Context, userName string) (model.BalanceStats, error) {
+func (r *BalanceRepository) FindBalanceStats(ctx context.Context, userID string) (model.BalanceStats, error) {
 	query := `
-		select b.username, b.balance, coalesce(sum(w.sum), 0) 
+		select b.user_id, b.balance, coalesce(sum(w.sum), 0) 
 		from gofemart.balance b
-		left join gofemart.withdrawal w on b.username = w.username
-		where b.username = $1
-		group by b.username, b.balance
+		left join gofemart.withdrawal w on b.user_id = w.user_id
+		where b.user_id = $1
+		group by b.user_id, b.balance
     `
 	var balance model.BalanceStats
-	err := r.pool.QueryRow(ctx, query, userName).Scan(&balance.Username, &balance.Balance, &balance.Withdrawn)
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&balance.UserID, &balance.Balance, &balance.Withdrawn)
 	if err != nil {
 		return model.BalanceStats{}, err
 	}
@@ -52,16 +53,16 @@ func (r *BalanceRepository) FindBalanceStats(ctx context.Context, userName strin
 
 func (r *BalanceRepository) Withdraw(ctx context.Context, balance model.Balance, sum float64, orderNumber string) error {
 	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
-		query := "update gofemart.balance set balance = $1, opt_lock = $2 where username = $3 and opt_lock = $4"
-		result, err := tx.Exec(ctx, query, balance.Balance-sum, balance.Version+1, balance.Username, balance.Version)
+		query := "update gofemart.balance set balance = $1, opt_lock = $2 where user_id = $3 and opt_lock = $4"
+		result, err := tx.Exec(ctx, query, balance.Balance-sum, balance.Version+1, balance.UserID, balance.Version)
 		if err != nil {
-			r.logger.Error("Error during change balance", zap.String("userName", balance.Username), zap.Error(err))
+			r.logger.Error("Error during change balance", zap.String("userID", balance.UserID), zap.Error(err))
 			return err
 		}
 
 		rowsAffected := result.RowsAffected()
 		if rowsAffected == 0 {
-			r.logger.Error("User balance has changed in other transaction", zap.String("userName", balance.Username), zap.Error(err))
+			r.logger.Error("User balance has changed in other transaction", zap.String("userID", balance.UserID), zap.Error(err))
 			return model.ErrUserBalanceHasChanged
 		}
 		withdrawID, err := uuid.NewRandom()
@@ -70,8 +71,8 @@ func (r *BalanceRepository) Withdraw(ctx context.Context, balance model.Balance,
 			return err
 		}
 
-		withdrawQuery := "insert into gofemart.withdrawal(id, order_number, username, sum, create_date) values ($1, $2, $3, $4, $5)"
-		_, err = tx.Exec(ctx, withdrawQuery, withdrawID, orderNumber, balance.Username, sum, time.Now())
+		withdrawQuery := "insert into gofemart.withdrawal(id, order_number, user_id, sum, create_date) values ($1, $2, $3, $4, $5)"
+		_, err = tx.Exec(ctx, withdrawQuery, withdrawID, orderNumber, balance.UserID, sum, time.Now())
 		if err != nil {
 			r.logger.Error("Error during create withdrawal", zap.String("orderNumber", orderNumber), zap.Error(err))
 			return err
@@ -79,3 +80,161 @@ func (r *BalanceRepository) Withdraw(ctx context.Context, balance model.Balance,
 		return nil
 	})
 }
+
+func (r *BalanceRepository) Authorize(ctx context.Context, balance model.Balance, sum float64, orderNumber string, ttl time.Duration) (model.Hold, error) {
+	holdID, err := uuid.NewRandom()
+	if err != nil {
+		r.logger.Error("Error during generate UUID", zap.Error(err))
+		return model.Hold{}, err
+	}
+
+	now := time.Now()
+	hold := model.Hold{
+		ID:             holdID.String(),
+		UserID:         balance.UserID,
+		OrderNumber:    orderNumber,
+		Sum:            sum,
+		Status:         model.AuthorizedHoldStatus,
+		CreateDate:     now,
+		LastModifyDate: now,
+		ExpireDate:     now.Add(ttl),
+	}
+
+	err = transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		query := "update gofemart.balance set reserved = $1, opt_lock = $2 where user_id = $3 and opt_lock = $4"
+		result, err := tx.Exec(ctx, query, balance.Reserved+sum, balance.Version+1, balance.UserID, balance.Version)
+		if err != nil {
+			r.logger.Error("Error during reserve balance", zap.String("userID", balance.UserID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Error("User balance has changed in other transaction", zap.String("userID", balance.UserID))
+			return model.ErrUserBalanceHasChanged
+		}
+
+		holdQuery := `insert into gofemart.hold(id, order_number, user_id, sum, status, create_date, last_modify_date, expire_date)
+					  values ($1, $2, $3, $4, $5, $6, $7, $8)`
+		_, err = tx.Exec(ctx, holdQuery, hold.ID, hold.OrderNumber, hold.UserID, hold.Sum, hold.Status,
+			hold.CreateDate, hold.LastModifyDate, hold.ExpireDate)
+		if err != nil {
+			r.logger.Error("Error during create hold", zap.String("orderNumber", orderNumber), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (r *BalanceRepository) FindHold(ctx context.Context, holdID string) (model.Hold, error) {
+	var hold model.Hold
+	query := `select id, order_number, user_id, sum, status, create_date, last_modify_date, expire_date
+			  from gofemart.hold where id = $1`
+	err := r.pool.QueryRow(ctx, query, holdID).Scan(&hold.ID, &hold.OrderNumber, &hold.UserID, &hold.Sum,
+		&hold.Status, &hold.CreateDate, &hold.LastModifyDate, &hold.ExpireDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Hold{}, model.ErrHoldWasNotFound
+		}
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func (r *BalanceRepository) FindExpiredHolds(ctx context.Context, limit int) ([]model.Hold, error) {
+	query := `select id, order_number, user_id, sum, status, create_date, last_modify_date, expire_date
+			  from gofemart.hold where status = $1 and expire_date <= $2
+			  order by expire_date
+			  limit $3`
+	rows, err := r.pool.Query(ctx, query, model.AuthorizedHoldStatus, time.Now(), limit)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []model.Hold
+	for rows.Next() {
+		var hold model.Hold
+		if err := rows.Scan(&hold.ID, &hold.OrderNumber, &hold.UserID, &hold.Sum, &hold.Status,
+			&hold.CreateDate, &hold.LastModifyDate, &hold.ExpireDate); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r *BalanceRepository) Capture(ctx context.Context, hold model.Hold) error {
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		now := time.Now()
+		holdQuery := `update gofemart.hold set status = $1, last_modify_date = $2
+					  where id = $3 and status = $4 and expire_date > $2`
+		result, err := tx.Exec(ctx, holdQuery, model.CapturedHoldStatus, now, hold.ID, model.AuthorizedHoldStatus)
+		if err != nil {
+			r.logger.Error("Error during capture hold", zap.String("holdID", hold.ID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return model.ErrHoldIsNotAuthorized
+		}
+
+		balanceQuery := `update gofemart.balance set balance = balance - $1, reserved = reserved - $1, opt_lock = opt_lock + 1
+						 where user_id = $2`
+		_, err = tx.Exec(ctx, balanceQuery, hold.Sum, hold.UserID)
+		if err != nil {
+			r.logger.Error("Error during change balance", zap.String("userID", hold.UserID), zap.Error(err))
+			return err
+		}
+
+		withdrawID, err := uuid.NewRandom()
+		if err != nil {
+			r.logger.Error("Error during generate UUID", zap.Error(err))
+			return err
+		}
+
+		withdrawQuery := "insert into gofemart.withdrawal(id, order_number, user_id, sum, create_date) values ($1, $2, $3, $4, $5)"
+		_, err = tx.Exec(ctx, withdrawQuery, withdrawID, hold.OrderNumber, hold.UserID, hold.Sum, now)
+		if err != nil {
+			r.logger.Error("Error during create withdrawal", zap.String("orderNumber", hold.OrderNumber), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *BalanceRepository) Void(ctx context.Context, hold model.Hold, status string) error {
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		holdQuery := "update gofemart.hold set status = $1, last_modify_date = $2 where id = $3 and status = $4"
+		result, err := tx.Exec(ctx, holdQuery, status, time.Now(), hold.ID, model.AuthorizedHoldStatus)
+		if err != nil {
+			r.logger.Error("Error during void hold", zap.String("holdID", hold.ID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return model.ErrHoldIsNotAuthorized
+		}
+
+		balanceQuery := "update gofemart.balance set reserved = reserved - $1, opt_lock = opt_lock + 1 where user_id = $2"
+		_, err = tx.Exec(ctx, balanceQuery, hold.Sum, hold.UserID)
+		if err != nil {
+			r.logger.Error("Error during release balance", zap.String("userID", hold.UserID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 	"testing"
+	"time"
 )
 
 func TestBalanceRepository(t *testing.T) {
@@ -23,9 +24,9 @@ func TestBalanceRepository(t *testing.T) {
 	balanceRepository := NewBalanceRepository(pool, logger)
 
 	balance := model.Balance{
-		Username: "testuser",
-		Balance:  1000.0,
-		Version:  1,
+		UserID:  internal.TestUserID,
+		Balance: 1000.0,
+		Version: 1,
 	}
 
 	t.Run("FindBalance", func(t *testing.T) {
@@ -35,12 +36,16 @@ func TestBalanceRepository(t *testing.T) {
 			}
 		})
 
-		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (username, balance, opt_lock) VALUES ($1, $2, $3)`,
-			balance.Username, balance.Balance, balance.Version); err != nil {
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`,
+			balance.UserID, balance.Balance, balance.Version); err != nil {
 			t.Fatalf("failed to insert balance: %v", err)
 		}
 
-		result, err := balanceRepository.FindBalance(ctx, "testuser")
+		result, err := balanceRepository.FindBalance(ctx, internal.TestUserID)
 		assert.NoError(t, err)
 		assert.Equal(t, balance, result)
 	})
@@ -52,15 +57,19 @@ func TestBalanceRepository(t *testing.T) {
 			}
 		})
 
-		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (username, balance, opt_lock) VALUES ($1, $2, $3)`,
-			balance.Username, balance.Balance, balance.Version); err != nil {
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`,
+			balance.UserID, balance.Balance, balance.Version); err != nil {
 			t.Fatalf("failed to insert balance: %v", err)
 		}
 
 		err := balanceRepository.Withdraw(ctx, balance, 200, "12345678903")
 		assert.NoError(t, err)
 
-		updatedBalance, err := balanceRepository.FindBalance(ctx, "testuser")
+		updatedBalance, err := balanceRepository.FindBalance(ctx, internal.TestUserID)
 		assert.NoError(t, err)
 		assert.Equal(t, 800., updatedBalance.Balance)
 
@@ -69,4 +78,73 @@ func TestBalanceRepository(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 1, count)
 	})
+	t.Run("AuthorizeAndCapture", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`,
+			balance.UserID, balance.Balance, balance.Version); err != nil {
+			t.Fatalf("failed to insert balance: %v", err)
+		}
+
+		hold, err := balanceRepository.Authorize(ctx, balance, 300, "12345678903", time.Minute)
+		assert.NoError(t, err)
+
+		reserved, err := balanceRepository.FindBalance(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000., reserved.Balance)
+		assert.Equal(t, 300., reserved.Reserved)
+		assert.Equal(t, 700., reserved.Spendable())
+
+		err = balanceRepository.Capture(ctx, hold)
+		assert.NoError(t, err)
+
+		captured, err := balanceRepository.FindBalance(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, 700., captured.Balance)
+		assert.Equal(t, 0., captured.Reserved)
+
+		err = balanceRepository.Void(ctx, hold, model.VoidedHoldStatus)
+		assert.Equal(t, model.ErrHoldIsNotAuthorized, err)
+	})
+
+	t.Run("FindExpiredHoldsAndVoid", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`,
+			balance.UserID, balance.Balance, balance.Version); err != nil {
+			t.Fatalf("failed to insert balance: %v", err)
+		}
+
+		hold, err := balanceRepository.Authorize(ctx, balance, 300, "12345678903", -time.Minute)
+		assert.NoError(t, err)
+
+		expired, err := balanceRepository.FindExpiredHolds(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(expired))
+		assert.Equal(t, hold.ID, expired[0].ID)
+
+		err = balanceRepository.Void(ctx, expired[0], model.ExpiredHoldStatus)
+		assert.NoError(t, err)
+
+		released, err := balanceRepository.FindBalance(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000., released.Balance)
+		assert.Equal(t, 0., released.Reserved)
+	})
 }
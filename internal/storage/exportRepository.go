@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const exportFetchSize = 500
+
+type ExportRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewExportRepository(pool *pgxpool.Pool, logger *zap.Logger) *ExportRepository {
+	return &ExportRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *ExportRepository) StreamTransactions(ctx context.Context, userID string, from, to *time.Time, consumer func(model.Transaction) error) error {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	if from != nil {
+		args = append(args, *from)
+		conditions = append(conditions, fmt.Sprintf("create_date >= $%d", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		conditions = append(conditions, fmt.Sprintf("create_date < $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`declare export_cursor no scroll cursor for
+			  select type, order_number, status, amount, create_date from (
+				  select '%s' as type, order_number, status, 0::numeric as amount, create_date, user_id
+				  from gofemart.order
+				  union all
+				  select '%s', h.order_number, h.status, h.accrual, h.create_date, o.user_id
+				  from gofemart.order_status_history h join gofemart.order o on o.order_number = h.order_number
+				  where h.status = '%s' and h.accrual is not null
+				  union all
+				  select '%s', order_number, '', sum, create_date, user_id
+				  from gofemart.withdrawal
+			  ) t where %s order by create_date, type, order_number`,
+		model.OrderTransactionType, model.AccrualTransactionType, model.ProcessedOrderStatus,
+		model.WithdrawalTransactionType, strings.Join(conditions, " and "))
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("error during open transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		r.logger.Error("Error during declare export cursor", zap.String("userID", userID), zap.Error(err))
+		return err
+	}
+
+	fetchQuery := fmt.Sprintf("fetch forward %d from export_cursor", exportFetchSize)
+	for {
+		rows, err := tx.Query(ctx, fetchQuery)
+		if err != nil {
+			r.logger.Error("Error during fetch export cursor", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var transaction model.Transaction
+			if err := rows.Scan(&transaction.Type, &transaction.OrderNumber, &transaction.Status, &transaction.Amount, &transaction.CreateDate); err != nil {
+				rows.Close()
+				r.logger.Error("Error during scan row", zap.Error(err))
+				return err
+			}
+			fetched++
+
+			if err := consumer(transaction); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestExportRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	exportRepository := NewExportRepository(pool, logger)
+	orderRepository := NewOrderRepository(pool, logger)
+
+	t.Run("StreamTransactions", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+		otherUserID := "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d"
+		if err := internal.CreateTestUser(ctx, pool, otherUserID, "otherUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		createDate := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+		order := model.Order{
+			OrderNumber:    "12345678903",
+			CreateDate:     createDate,
+			LastModifyDate: createDate,
+			Status:         model.NewOrderStatus,
+			UserID:         internal.TestUserID,
+		}
+		err := orderRepository.CreateOrder(ctx, order)
+		assert.NoError(t, err)
+
+		withdrawalQuery := `INSERT INTO gofemart.withdrawal (id, order_number, user_id, sum, create_date) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := pool.Exec(ctx, withdrawalQuery, "0b6b1a9e-2f4c-4b5a-8d1e-3c7f9a2b4d10", "79927398713", internal.TestUserID, 100, createDate.Add(time.Hour)); err != nil {
+			t.Fatalf("failed to insert withdrawal: %v", err)
+		}
+		if _, err := pool.Exec(ctx, withdrawalQuery, "0b6b1a9e-2f4c-4b5a-8d1e-3c7f9a2b4d11", "4561261212345467", otherUserID, 100, createDate); err != nil {
+			t.Fatalf("failed to insert withdrawal: %v", err)
+		}
+
+		var transactions []model.Transaction
+		err = exportRepository.StreamTransactions(ctx, internal.TestUserID, nil, nil, func(transaction model.Transaction) error {
+			transactions = append(transactions, transaction)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(transactions))
+		assert.Equal(t, model.OrderTransactionType, transactions[0].Type)
+		assert.Equal(t, model.WithdrawalTransactionType, transactions[1].Type)
+		assert.Equal(t, 100., transactions[1].Amount)
+
+		from := createDate.Add(time.Minute)
+		transactions = nil
+		err = exportRepository.StreamTransactions(ctx, internal.TestUserID, &from, nil, func(transaction model.Transaction) error {
+			transactions = append(transactions, transaction)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(transactions))
+		assert.Equal(t, "79927398713", transactions[0].OrderNumber)
+	})
+}
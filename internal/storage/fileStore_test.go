@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	fileStore := NewFileStore(t.TempDir())
+
+	t.Run("Put and Get", func(t *testing.T) {
+		err := fileStore.Put(ctx, "statements/1.csv", []byte("data"))
+		assert.NoError(t, err)
+
+		err = fileStore.Put(ctx, "statements/1.csv", []byte("new data"))
+		assert.NoError(t, err)
+
+		data, err := fileStore.Get(ctx, "statements/1.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "new data", string(data))
+	})
+
+	t.Run("Get not existing", func(t *testing.T) {
+		_, err := fileStore.Get(ctx, "statements/2.csv")
+		assert.Equal(t, model.ErrDocumentWasNotFound, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		err := fileStore.Put(ctx, "statements/3.csv", []byte("data"))
+		assert.NoError(t, err)
+
+		err = fileStore.Delete(ctx, "statements/3.csv")
+		assert.NoError(t, err)
+
+		_, err = fileStore.Get(ctx, "statements/3.csv")
+		assert.Equal(t, model.ErrDocumentWasNotFound, err)
+
+		err = fileStore.Delete(ctx, "statements/3.csv")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Key outside of directory", func(t *testing.T) {
+		err := fileStore.Put(ctx, "../1.csv", []byte("data"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type HealthRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewHealthRepository(pool *pgxpool.Pool, logger *zap.Logger) *HealthRepository {
+	return &HealthRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.pool.Ping(ctx)
+}
+
+func (r *HealthRepository) FindMigrationVersion(ctx context.Context) (int64, error) {
+	query := `select coalesce(max(version_id), 0) from goose_db_version where is_applied`
+
+	var version int64
+	if err := r.pool.QueryRow(ctx, query).Scan(&version); err != nil {
+		r.logger.Error("Error during find migration version", zap.Error(err))
+		return 0, err
+	}
+	return version, nil
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+)
+
+func TestHealthRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	healthRepository := NewHealthRepository(pool, logger)
+
+	t.Run("Ping", func(t *testing.T) {
+		assert.NoError(t, healthRepository.Ping(ctx))
+	})
+
+	t.Run("FindMigrationVersion", func(t *testing.T) {
+		version, err := healthRepository.FindMigrationVersion(ctx)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, version, int64(17))
+	})
+}
@@ -2,7 +2,11 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/desepticon55/gofemart/internal/tracing"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
@@ -11,6 +15,11 @@ import (
 type TransactionFunc func(tx pgx.Tx) error
 
 func transactional(ctx context.Context, logger *zap.Logger, pool *pgxpool.Pool, fn TransactionFunc) (err error) {
+	ctx, span := tracing.Start(ctx, "db.transaction")
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	tx, err := pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("error during open transaction: %w", err)
@@ -28,3 +37,54 @@ func transactional(ctx context.Context, logger *zap.Logger, pool *pgxpool.Pool,
 	err = fn(tx)
 	return err
 }
+
+func execBatch(ctx context.Context, tx pgx.Tx, batch *pgx.Batch) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return err
+		}
+	}
+	return results.Close()
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func appendPageConditions(conditions []string, args []any, page model.PageRequest, dateColumn, keyColumn string) ([]string, []any) {
+	if page.From != nil {
+		args = append(args, *page.From)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", dateColumn, len(args)))
+	}
+
+	if page.To != nil {
+		args = append(args, *page.To)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", dateColumn, len(args)))
+	}
+
+	if page.Cursor != nil {
+		operator := ">"
+		if page.Desc {
+			operator = "<"
+		}
+		args = append(args, page.Cursor.CreateDate, page.Cursor.Key)
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s ($%d, $%d)", dateColumn, keyColumn, operator, len(args)-1, len(args)))
+	}
+
+	return conditions, args
+}
+
+func pageOrderBy(page model.PageRequest, dateColumn, keyColumn string) string {
+	direction := "asc"
+	if page.Desc {
+		direction = "desc"
+	}
+	return fmt.Sprintf("order by %s %s, %s %s limit %d", dateColumn, direction, keyColumn, direction, page.Limit+1)
+}
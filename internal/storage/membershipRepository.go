@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type MembershipRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewMembershipRepository(pool *pgxpool.Pool, logger *zap.Logger) *MembershipRepository {
+	return &MembershipRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *MembershipRepository) Heartbeat(ctx context.Context, workerID string) error {
+	query := `insert into gofemart.worker_membership(worker_id, heartbeat_date, create_date) values ($1, now(), now())
+			  on conflict (worker_id) do update set heartbeat_date = excluded.heartbeat_date`
+	if _, err := r.pool.Exec(ctx, query, workerID); err != nil {
+		r.logger.Error("Error during save worker heartbeat", zap.String("workerID", workerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *MembershipRepository) FindActiveMembers(ctx context.Context, ttl time.Duration) ([]string, error) {
+	query := `select worker_id from gofemart.worker_membership
+			  where heartbeat_date > now() - make_interval(secs => $1)
+			  order by worker_id`
+	rows, err := r.pool.Query(ctx, query, ttl.Seconds())
+	if err != nil {
+		r.logger.Error("Error during find active members", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []string
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *MembershipRepository) DeleteExpiredMembers(ctx context.Context, ttl time.Duration) error {
+	query := "delete from gofemart.worker_membership where heartbeat_date <= now() - make_interval(secs => $1)"
+	if _, err := r.pool.Exec(ctx, query, ttl.Seconds()); err != nil {
+		r.logger.Error("Error during delete expired members", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *MembershipRepository) DeleteMember(ctx context.Context, workerID string) error {
+	query := "delete from gofemart.worker_membership where worker_id = $1"
+	if _, err := r.pool.Exec(ctx, query, workerID); err != nil {
+		r.logger.Error("Error during delete member", zap.String("workerID", workerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestMembershipRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	membershipRepository := NewMembershipRepository(pool, logger)
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		assert.NoError(t, membershipRepository.Heartbeat(ctx, "replica-b-0"))
+		assert.NoError(t, membershipRepository.Heartbeat(ctx, "replica-a-0"))
+		assert.NoError(t, membershipRepository.Heartbeat(ctx, "replica-a-0"))
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.worker_membership (worker_id, heartbeat_date, create_date) VALUES ($1, $2, $2)`,
+			"replica-c-0", time.Now().Add(-time.Hour)); err != nil {
+			t.Fatalf("failed to insert member: %v", err)
+		}
+
+		members, err := membershipRepository.FindActiveMembers(ctx, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica-a-0", "replica-b-0"}, members)
+
+		assert.NoError(t, membershipRepository.DeleteExpiredMembers(ctx, time.Minute))
+		assert.NoError(t, membershipRepository.DeleteMember(ctx, "replica-b-0"))
+
+		var count int
+		err = pool.QueryRow(ctx, `SELECT count(*) FROM gofemart.worker_membership`).Scan(&count)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
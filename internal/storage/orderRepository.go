@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
+	"strings"
+	"time"
 )
 
 type OrderRepository struct {
@@ -33,10 +37,13 @@ func (r *OrderRepository) ExistOrder(ctx context.Context, orderNumber string) (b
 
 func (r *OrderRepository) FindOrder(ctx context.Context, orderNumber string) (model.Order, error) {
 	var order model.Order
-	query := "select order_number, username, create_date, last_modify_date, status, accrual, opt_lock, key_hash, key_hash_module from gofemart.order where order_number = $1"
-	err := r.pool.QueryRow(ctx, query, orderNumber).Scan(&order.OrderNumber, &order.Username, &order.CreateDate,
+	query := "select order_number, user_id, create_date, last_modify_date, status, accrual, opt_lock, key_hash, key_hash_module from gofemart.order where order_number = $1"
+	err := r.pool.QueryRow(ctx, query, orderNumber).Scan(&order.OrderNumber, &order.UserID, &order.CreateDate,
 		&order.LastModifyDate, &order.Status, &order.Accrual, &order.Version, &order.KeyHash, &order.KeyHashModule)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Order{}, model.ErrOrderWasNotFound
+		}
 		return model.Order{}, err
 	}
 
@@ -44,21 +51,143 @@ func (r *OrderRepository) FindOrder(ctx context.Context, orderNumber string) (mo
 }
 
 func (r *OrderRepository) CreateOrder(ctx context.Context, order model.Order) error {
-	query := `insert into gofemart.order(order_number, username, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock)
-			      values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.pool.Exec(ctx, query, order.OrderNumber, order.Username, order.CreateDate, order.LastModifyDate,
-		order.Status, order.Accrual, order.KeyHash, order.KeyHashModule, 0)
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		query := `insert into gofemart.order(order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock)
+				      values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		_, err := tx.Exec(ctx, query, order.OrderNumber, order.UserID, order.CreateDate, order.LastModifyDate,
+			order.Status, order.Accrual, order.KeyHash, order.KeyHashModule, 0)
+		if err != nil {
+			r.logger.Error("Error during create order", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+			return err
+		}
+
+		return r.addStatusTransition(ctx, tx, order.OrderNumber, order.Status, nil, order.CreateDate)
+	})
+}
+
+func (r *OrderRepository) CreateOrders(ctx context.Context, orders []model.Order) ([]string, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	orderNumbers := make([]string, 0, len(orders))
+	keyHashes := make([]int64, 0, len(orders))
+	keyHashModules := make([]int64, 0, len(orders))
+	for _, order := range orders {
+		orderNumbers = append(orderNumbers, order.OrderNumber)
+		keyHashes = append(keyHashes, order.KeyHash)
+		keyHashModules = append(keyHashModules, order.KeyHashModule)
+	}
+
+	var created []string
+	err := transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		query := `insert into gofemart.order(order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock)
+				  select unnest($1::varchar[]), $2, $3, $3, $4, 0, unnest($5::bigint[]), unnest($6::int[]), 0
+				  on conflict (order_number) do nothing
+				  returning order_number`
+		rows, err := tx.Query(ctx, query, orderNumbers, orders[0].UserID, orders[0].CreateDate, orders[0].Status, keyHashes, keyHashModules)
+		if err != nil {
+			r.logger.Error("Error during create orders", zap.Int("count", len(orders)), zap.Error(err))
+			return err
+		}
+
+		for rows.Next() {
+			var orderNumber string
+			if err := rows.Scan(&orderNumber); err != nil {
+				rows.Close()
+				return err
+			}
+			created = append(created, orderNumber)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		historyQuery := `insert into gofemart.order_status_history(order_number, status, accrual, create_date)
+						 select unnest($1::varchar[]), $2, null, $3`
+		_, err = tx.Exec(ctx, historyQuery, created, orders[0].Status, orders[0].CreateDate)
+		if err != nil {
+			r.logger.Error("Error during create order status transitions", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *OrderRepository) FindOrderOwners(ctx context.Context, orderNumbers []string) (map[string]string, error) {
+	query := "select order_number, user_id from gofemart.order where order_number = any($1)"
+	rows, err := r.pool.Query(ctx, query, orderNumbers)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make(map[string]string, len(orderNumbers))
+	for rows.Next() {
+		var orderNumber, userID string
+		if err := rows.Scan(&orderNumber, &userID); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+		owners[orderNumber] = userID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
+
+func (r *OrderRepository) FindOrderHistory(ctx context.Context, orderNumber string) ([]model.OrderStatusTransition, error) {
+	query := `select status, accrual, create_date from gofemart.order_status_history
+			  where order_number = $1 order by create_date, id`
+	rows, err := r.pool.Query(ctx, query, orderNumber)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.OrderStatusTransition
+	for rows.Next() {
+		var transition model.OrderStatusTransition
+		if err := rows.Scan(&transition.Status, &transition.Accrual, &transition.CreateDate); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		history = append(history, transition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (r *OrderRepository) addStatusTransition(ctx context.Context, tx pgx.Tx, orderNumber, status string, accrual *float64, createDate time.Time) error {
+	query := "insert into gofemart.order_status_history(order_number, status, accrual, create_date) values ($1, $2, $3, $4)"
+	_, err := tx.Exec(ctx, query, orderNumber, status, accrual, createDate)
 	if err != nil {
-		r.logger.Error("Error during create order", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+		r.logger.Error("Error during create order status transition", zap.String("orderNumber", orderNumber), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (r *OrderRepository) FindAllOrders(ctx context.Context, userName string) ([]model.Order, error) {
-	query := `select order_number, username, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock
-			  from gofemart.order where username = $1 order by create_date`
-	rows, err := r.pool.Query(ctx, query, userName)
+func (r *OrderRepository) FindAllOrders(ctx context.Context, userID string) ([]model.Order, error) {
+	query := `select order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock
+			  from gofemart.order where user_id = $1 order by create_date`
+	rows, err := r.pool.Query(ctx, query, userID)
 	if err != nil {
 		r.logger.Error("Error during execute query", zap.Error(err))
 		return []model.Order{}, err
@@ -68,7 +197,7 @@ func (r *OrderRepository) FindAllOrders(ctx context.Context, userName string) ([
 	var orders []model.Order
 	for rows.Next() {
 		var order model.Order
-		if err := rows.Scan(&order.OrderNumber, &order.Username, &order.CreateDate, &order.LastModifyDate,
+		if err := rows.Scan(&order.OrderNumber, &order.UserID, &order.CreateDate, &order.LastModifyDate,
 			&order.Status, &order.Accrual, &order.KeyHash, &order.KeyHashModule, &order.Version); err != nil {
 			r.logger.Error("Error during scan row", zap.Error(err))
 			continue
@@ -84,15 +213,65 @@ func (r *OrderRepository) FindAllOrders(ctx context.Context, userName string) ([
 	return orders, nil
 }
 
-func (r *OrderRepository) FindOrdersToProcess(ctx context.Context, from, to int) ([]model.Order, error) {
-	query := `select order_number, username, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock 
-              from gofemart.order
-			  where status in ('NEW', 'PROCESSING') and key_hash_module >= $1 and key_hash_module < $2
-			  limit 100
-    `
-	rows, err := r.pool.Query(ctx, query, from, to)
+func (r *OrderRepository) FindOrdersPage(ctx context.Context, userID string, page model.PageRequest) (model.Page[model.Order], error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	if len(page.Statuses) > 0 {
+		args = append(args, page.Statuses)
+		conditions = append(conditions, fmt.Sprintf("status = any($%d)", len(args)))
+	}
+	conditions, args = appendPageConditions(conditions, args, page, "create_date", "order_number")
+
+	query := fmt.Sprintf(`select order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock
+			  from gofemart.order where %s %s`, strings.Join(conditions, " and "), pageOrderBy(page, "create_date", "order_number"))
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		r.logger.Error("Error during execute query", zap.Error(err))
+		return model.Page[model.Order]{}, err
+	}
+	defer rows.Close()
+
+	var orders []model.Order
+	for rows.Next() {
+		var order model.Order
+		if err := rows.Scan(&order.OrderNumber, &order.UserID, &order.CreateDate, &order.LastModifyDate,
+			&order.Status, &order.Accrual, &order.KeyHash, &order.KeyHashModule, &order.Version); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Order]{}, err
+	}
+
+	result := model.Page[model.Order]{Items: orders}
+	if len(orders) > page.Limit {
+		result.Items = orders[:page.Limit]
+		last := result.Items[page.Limit-1]
+		result.NextCursor = model.PageCursor{CreateDate: last.CreateDate, Key: last.OrderNumber}.Encode()
+	}
+	return result, nil
+}
+
+func (r *OrderRepository) ClaimOrders(ctx context.Context, workerID string, shards []int, limit int, leaseTTL time.Duration, pollDelay time.Duration) ([]model.Order, error) {
+	query := `update gofemart.order o set claimed_by = $1, lease_until = now() + make_interval(secs => $3)
+			  from (select order_number from gofemart.order
+			        where status in ('NEW', 'PROCESSING') and key_hash_module = any($4) and dead_lettered_at is null
+			          and (next_attempt_at is null or next_attempt_at <= now())
+			          and (lease_until is null or lease_until < now())
+			          and last_modify_date <= now() - make_interval(secs => $5)
+			        order by last_modify_date
+			        limit $2
+			        for update skip locked) claimed
+			  where o.order_number = claimed.order_number
+			  returning o.order_number, o.user_id, o.create_date, o.last_modify_date, o.status, o.accrual, o.key_hash, o.key_hash_module, o.opt_lock,
+			            o.attempt_count`
+	rows, err := r.pool.Query(ctx, query, workerID, limit, leaseTTL.Seconds(), shards, pollDelay.Seconds())
+	if err != nil {
+		r.logger.Error("Error during claim orders", zap.String("workerID", workerID), zap.Error(err))
 		return nil, err
 	}
 	defer rows.Close()
@@ -100,8 +279,8 @@ func (r *OrderRepository) FindOrdersToProcess(ctx context.Context, from, to int)
 	var orders []model.Order
 	for rows.Next() {
 		var order model.Order
-		if err := rows.Scan(&order.OrderNumber, &order.Username, &order.CreateDate, &order.LastModifyDate, &order.Status,
-			&order.Accrual, &order.KeyHash, &order.KeyHashModule, &order.Version); err != nil {
+		if err := rows.Scan(&order.OrderNumber, &order.UserID, &order.CreateDate, &order.LastModifyDate, &order.Status,
+			&order.Accrual, &order.KeyHash, &order.KeyHashModule, &order.Version, &order.AttemptCount); err != nil {
 			r.logger.Error("Error during scan row", zap.Error(err))
 			continue
 		}
@@ -116,44 +295,259 @@ func (r *OrderRepository) FindOrdersToProcess(ctx context.Context, from, to int)
 	return orders, nil
 }
 
-func (r *OrderRepository) ChangeOrderStatus(ctx context.Context, order model.Order, status string, accrual float64) error {
+func (r *OrderRepository) ReleaseOrder(ctx context.Context, workerID string, order model.Order) error {
+	query := "update gofemart.order set claimed_by = null, lease_until = null where order_number = $1 and claimed_by = $2"
+	result, err := r.pool.Exec(ctx, query, order.OrderNumber, workerID)
+	if err != nil {
+		r.logger.Error("Error during release order", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrOrderLeaseIsLost
+	}
+	return nil
+}
+
+func (r *OrderRepository) RetryOrder(ctx context.Context, workerID string, order model.Order, lastError string, nextAttempt time.Time, deadLetter bool) error {
+	query := `update gofemart.order set claimed_by = null, lease_until = null, attempt_count = attempt_count + 1,
+			  next_attempt_at = $3, last_error = $4, dead_lettered_at = case when $5 then now() end
+			  where order_number = $1 and claimed_by = $2`
+	result, err := r.pool.Exec(ctx, query, order.OrderNumber, workerID, nextAttempt, lastError, deadLetter)
+	if err != nil {
+		r.logger.Error("Error during schedule order retry", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrOrderLeaseIsLost
+	}
+	return nil
+}
+
+func (r *OrderRepository) CountOrdersToProcess(ctx context.Context) (map[string]int, error) {
+	query := "select status, count(*) from gofemart.order where status in ('NEW', 'PROCESSING') and dead_lettered_at is null group by status"
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *OrderRepository) AckOrder(ctx context.Context, workerID string, order model.Order, status string, accrual float64) error {
 	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
-		if status == model.ProcessedOrderStatus {
-			findBalanceQuery := "select username, balance, opt_lock from gofemart.balance where username = $1"
-			var balance model.Balance
-			err := r.pool.QueryRow(ctx, findBalanceQuery, order.Username).Scan(&balance.Username, &balance.Balance, &balance.Version)
-			if err != nil {
-				r.logger.Error("Error during find balance", zap.String("userName", balance.Username), zap.Error(err))
-				return err
-			}
+		failed, err := r.changeOrderStatuses(ctx, tx, []model.OrderAccrual{{Order: order, Status: status, Accrual: accrual}}, &workerID)
+		if err != nil {
+			return err
+		}
+		return failed[order.OrderNumber]
+	})
+}
 
-			changeBalanceQuery := "update gofemart.balance set balance = $1, opt_lock = $2 where username = $3 and opt_lock = $4"
-			result, err := tx.Exec(ctx, changeBalanceQuery, balance.Balance+accrual, balance.Version+1, balance.Username, balance.Version)
-			if err != nil {
-				r.logger.Error("Error during change balance", zap.String("userName", balance.Username), zap.Error(err))
-				return err
+func (r *OrderRepository) AckOrders(ctx context.Context, workerID string, accruals []model.OrderAccrual) (map[string]error, error) {
+	var failed map[string]error
+	err := transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) (err error) {
+		failed, err = r.changeOrderStatuses(ctx, tx, accruals, &workerID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+func (r *OrderRepository) ApplyOrderAccrual(ctx context.Context, orderNumber string, status string, accrual float64) error {
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		var order model.Order
+		query := `select order_number, user_id, create_date, last_modify_date, status, accrual, opt_lock, key_hash, key_hash_module
+				  from gofemart.order where order_number = $1 for update`
+		err := tx.QueryRow(ctx, query, orderNumber).Scan(&order.OrderNumber, &order.UserID, &order.CreateDate,
+			&order.LastModifyDate, &order.Status, &order.Accrual, &order.Version, &order.KeyHash, &order.KeyHashModule)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.ErrOrderWasNotFound
 			}
+			return err
+		}
 
-			rowsAffected := result.RowsAffected()
-			if rowsAffected == 0 {
-				r.logger.Error("User balance has changed in other transaction", zap.String("userName", balance.Username), zap.Error(err))
-				return model.ErrUserBalanceHasChanged
+		if order.Status == model.ProcessedOrderStatus || order.Status == model.InvalidOrderStatus {
+			if order.Status == status && order.Accrual == accrual {
+				return nil
 			}
+			return model.ErrOrderIsAlreadyProcessed
 		}
 
-		changeOrderQuery := "update gofemart.order set status = $1, accrual = $2, opt_lock = $3 where order_number = $4 and opt_lock = $5"
-		result, err := tx.Exec(ctx, changeOrderQuery, status, accrual, order.Version+1, order.OrderNumber, order.Version)
+		failed, err := r.changeOrderStatuses(ctx, tx, []model.OrderAccrual{{Order: order, Status: status, Accrual: accrual}}, nil)
 		if err != nil {
-			r.logger.Error("Error during change order", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
 			return err
 		}
+		return failed[orderNumber]
+	})
+}
+
+func (r *OrderRepository) changeOrderStatuses(ctx context.Context, tx pgx.Tx, accruals []model.OrderAccrual, workerID *string) (map[string]error, error) {
+	failed := make(map[string]error)
+	balances, err := r.lockBalances(ctx, tx, accruals)
+	if err != nil {
+		return nil, err
+	}
 
-		rowsAffected := result.RowsAffected()
-		if rowsAffected == 0 {
-			r.logger.Error("Order has changed in other transaction", zap.String("orderNumber", order.OrderNumber), zap.Error(err))
-			return model.ErrUserBalanceHasChanged
+	now := time.Now()
+	changeOrderQuery := `update gofemart.order set status = $1, accrual = $2, opt_lock = $3, last_modify_date = $4, claimed_by = null, lease_until = null,
+						 attempt_count = 0, next_attempt_at = null, last_error = null, dead_lettered_at = null
+						 where order_number = $5 and opt_lock = $6 and ($7::text is null or claimed_by = $7)`
+	batch := &pgx.Batch{}
+	queued := make([]model.OrderAccrual, 0, len(accruals))
+	for _, accrual := range accruals {
+		if accrual.Status == model.ProcessedOrderStatus && !balances[accrual.Order.UserID] {
+			r.logger.Error("User balance was not found", zap.String("userID", accrual.Order.UserID))
+			failed[accrual.Order.OrderNumber] = model.ErrUserBalanceWasNotFound
+			continue
 		}
+		batch.Queue(changeOrderQuery, accrual.Status, accrual.Accrual, accrual.Order.Version+1, now,
+			accrual.Order.OrderNumber, accrual.Order.Version, workerID)
+		queued = append(queued, accrual)
+	}
 
-		return nil
-	})
+	changed := make([]model.OrderAccrual, 0, len(queued))
+	if len(queued) == 0 {
+		return failed, nil
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for _, accrual := range queued {
+		result, err := results.Exec()
+		if err != nil {
+			r.logger.Error("Error during change order", zap.String("orderNumber", accrual.Order.OrderNumber), zap.Error(err))
+			results.Close()
+			return nil, err
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Error("Order lease is lost or order has changed in other transaction", zap.String("orderNumber", accrual.Order.OrderNumber))
+			failed[accrual.Order.OrderNumber] = model.ErrOrderLeaseIsLost
+			continue
+		}
+		changed = append(changed, accrual)
+	}
+	if err := results.Close(); err != nil {
+		return nil, err
+	}
+
+	transitionQuery := "insert into gofemart.order_status_history(order_number, status, accrual, create_date) values ($1, $2, $3, $4)"
+	batch = &pgx.Batch{}
+	credits := make(map[string]float64)
+	for _, accrual := range changed {
+		if accrual.Status != accrual.Order.Status || accrual.Accrual != accrual.Order.Accrual {
+			batch.Queue(transitionQuery, accrual.Order.OrderNumber, accrual.Status, accrual.Accrual, now)
+		}
+		if accrual.Status == model.ProcessedOrderStatus {
+			credits[accrual.Order.UserID] += accrual.Accrual
+		}
+	}
+
+	creditQuery := "update gofemart.balance set balance = balance + $1, opt_lock = opt_lock + 1 where user_id = $2"
+	for userID, credit := range credits {
+		batch.Queue(creditQuery, credit, userID)
+	}
+	if err := execBatch(ctx, tx, batch); err != nil {
+		r.logger.Error("Error during record order transitions and credit balances", zap.Error(err))
+		return nil, err
+	}
+	return failed, nil
+}
+
+func (r *OrderRepository) lockBalances(ctx context.Context, tx pgx.Tx, accruals []model.OrderAccrual) (map[string]bool, error) {
+	var userIDs []string
+	for _, accrual := range accruals {
+		if accrual.Status == model.ProcessedOrderStatus {
+			userIDs = append(userIDs, accrual.Order.UserID)
+		}
+	}
+	balances := make(map[string]bool)
+	if len(userIDs) == 0 {
+		return balances, nil
+	}
+
+	query := "select user_id from gofemart.balance where user_id = any($1) order by user_id for update"
+	rows, err := tx.Query(ctx, query, userIDs)
+	if err != nil {
+		r.logger.Error("Error during lock balances", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		balances[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (r *OrderRepository) FindDeadLetterOrders(ctx context.Context) ([]model.DeadLetterOrder, error) {
+	query := `select order_number, user_id, status, attempt_count, coalesce(last_error, ''), dead_lettered_at
+			  from gofemart.order
+			  where dead_lettered_at is not null
+			  order by dead_lettered_at`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []model.DeadLetterOrder
+	for rows.Next() {
+		var order model.DeadLetterOrder
+		if err := rows.Scan(&order.OrderNumber, &order.UserID, &order.Status, &order.AttemptCount, &order.LastError, &order.DeadLetterDate); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *OrderRepository) ReplayDeadLetterOrder(ctx context.Context, orderNumber string) error {
+	query := `update gofemart.order set dead_lettered_at = null, attempt_count = 0, next_attempt_at = null, last_error = null
+			  where order_number = $1 and dead_lettered_at is not null`
+	result, err := r.pool.Exec(ctx, query, orderNumber)
+	if err != nil {
+		r.logger.Error("Error during replay order", zap.String("orderNumber", orderNumber), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrDeadLetterOrderWasNotFound
+	}
+	return nil
 }
@@ -29,7 +29,7 @@ func TestOrderRepository(t *testing.T) {
 		CreateDate:     time.Now(),
 		LastModifyDate: time.Now(),
 		Status:         "NEW",
-		Username:       "testUser",
+		UserID:         internal.TestUserID,
 		Accrual:        40.,
 		KeyHash:        10,
 		KeyHashModule:  0,
@@ -43,6 +43,10 @@ func TestOrderRepository(t *testing.T) {
 			}
 		})
 
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
 		err := orderRepository.CreateOrder(ctx, order)
 		assert.NoError(t, err)
 
@@ -58,6 +62,10 @@ func TestOrderRepository(t *testing.T) {
 			}
 		})
 
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
 		err := orderRepository.CreateOrder(ctx, order)
 		assert.NoError(t, err)
 	})
@@ -69,6 +77,10 @@ func TestOrderRepository(t *testing.T) {
 			}
 		})
 
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
 		err := orderRepository.CreateOrder(ctx, order)
 		assert.NoError(t, err)
 
@@ -76,7 +88,7 @@ func TestOrderRepository(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, order.OrderNumber, result.OrderNumber)
 		assert.Equal(t, order.Status, result.Status)
-		assert.Equal(t, order.Username, result.Username)
+		assert.Equal(t, order.UserID, result.UserID)
 		assert.Equal(t, order.Accrual, result.Accrual)
 		assert.Equal(t, order.KeyHash, result.KeyHash)
 		assert.Equal(t, order.KeyHashModule, result.KeyHashModule)
@@ -90,50 +102,350 @@ func TestOrderRepository(t *testing.T) {
 			}
 		})
 
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
 		err := orderRepository.CreateOrder(ctx, order)
 		assert.NoError(t, err)
 
-		result, err := orderRepository.FindAllOrders(ctx, "testUser")
+		result, err := orderRepository.FindAllOrders(ctx, internal.TestUserID)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(result))
 		assert.Equal(t, order.OrderNumber, result[0].OrderNumber)
 		assert.Equal(t, order.Status, result[0].Status)
-		assert.Equal(t, order.Username, result[0].Username)
+		assert.Equal(t, order.UserID, result[0].UserID)
 		assert.Equal(t, order.Accrual, result[0].Accrual)
 		assert.Equal(t, order.KeyHash, result[0].KeyHash)
 		assert.Equal(t, order.KeyHashModule, result[0].KeyHashModule)
 		assert.Equal(t, order.Version, result[0].Version)
 	})
 
-	t.Run("ChangeOrderStatus", func(t *testing.T) {
+	t.Run("ClaimOrders", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		processedOrder := order
+		processedOrder.OrderNumber = "79927398713"
+		processedOrder.Status = model.ProcessedOrderStatus
+		for _, o := range []model.Order{order, processedOrder} {
+			assert.NoError(t, orderRepository.CreateOrder(ctx, o))
+		}
+
+		claimed, err := orderRepository.ClaimOrders(ctx, "worker-1", []int{1, 2}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(claimed), "orders of other shards should not be claimed")
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed))
+		assert.Equal(t, order.OrderNumber, claimed[0].OrderNumber)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-2", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(claimed))
+
+		err = orderRepository.RetryOrder(ctx, "worker-2", order, "timeout", time.Now(), false)
+		assert.ErrorIs(t, err, model.ErrOrderLeaseIsLost)
+
+		err = orderRepository.RetryOrder(ctx, "worker-1", order, "timeout", time.Now(), false)
+		assert.NoError(t, err)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-2", []int{0}, 10, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed))
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed), "expired lease should be reclaimed")
+
+		err = orderRepository.ReleaseOrder(ctx, "worker-2", order)
+		assert.ErrorIs(t, err, model.ErrOrderLeaseIsLost)
+
+		err = orderRepository.ReleaseOrder(ctx, "worker-1", order)
+		assert.NoError(t, err)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-2", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed), "released order should be claimed without waiting for lease expiration")
+	})
+
+	t.Run("RetryOrder", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, orderRepository.CreateOrder(ctx, order))
+
+		claimed, err := orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed))
+		assert.Equal(t, 0, claimed[0].AttemptCount)
+
+		err = orderRepository.RetryOrder(ctx, "worker-1", claimed[0], "timeout", time.Now().Add(time.Hour), false)
+		assert.NoError(t, err)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(claimed), "order should not be claimed before next attempt")
+
+		_, err = pool.Exec(ctx, "update gofemart.order set next_attempt_at = now() where order_number = $1", order.OrderNumber)
+		assert.NoError(t, err)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed))
+		assert.Equal(t, 1, claimed[0].AttemptCount)
+
+		err = orderRepository.RetryOrder(ctx, "worker-1", claimed[0], "unexpected accrual response status: 500", time.Now(), true)
+		assert.NoError(t, err)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(claimed), "dead-lettered order should not be claimed")
+
+		deadLetters, err := orderRepository.FindDeadLetterOrders(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deadLetters))
+		assert.Equal(t, order.OrderNumber, deadLetters[0].OrderNumber)
+		assert.Equal(t, 2, deadLetters[0].AttemptCount)
+		assert.Equal(t, "unexpected accrual response status: 500", deadLetters[0].LastError)
+
+		assert.NoError(t, orderRepository.ReplayDeadLetterOrder(ctx, order.OrderNumber))
+		assert.ErrorIs(t, orderRepository.ReplayDeadLetterOrder(ctx, order.OrderNumber), model.ErrDeadLetterOrderWasNotFound)
+
+		claimed, err = orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed), "replayed order should be claimed again")
+		assert.Equal(t, 0, claimed[0].AttemptCount)
+	})
+
+	t.Run("AckOrder", func(t *testing.T) {
 		t.Cleanup(func() {
 			if err := internal.ClearTables(ctx, pool); err != nil {
 				t.Fatalf("failed to clear tables: %s", err)
 			}
 		})
-		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (username, balance, opt_lock) VALUES ($1, $2, $3)`, "testUser", 100, 0); err != nil {
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`, internal.TestUserID, 100, 0); err != nil {
 			t.Fatalf("failed to insert balance: %v", err)
 		}
 
 		err := orderRepository.CreateOrder(ctx, order)
 		assert.NoError(t, err)
 
-		err = orderRepository.ChangeOrderStatus(ctx, order, "PROCESSED", 555.0)
+		claimed, err := orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(claimed))
+
+		err = orderRepository.AckOrder(ctx, "worker-2", claimed[0], "PROCESSED", 555.0)
+		assert.ErrorIs(t, err, model.ErrOrderLeaseIsLost)
+
+		err = orderRepository.AckOrder(ctx, "worker-1", claimed[0], "PROCESSED", 555.0)
 		assert.NoError(t, err)
 
 		result, err := orderRepository.FindOrder(ctx, "12345678903")
 		assert.NoError(t, err)
 		assert.Equal(t, order.OrderNumber, result.OrderNumber)
 		assert.Equal(t, "PROCESSED", result.Status)
-		assert.Equal(t, order.Username, result.Username)
+		assert.Equal(t, order.UserID, result.UserID)
 		assert.Equal(t, 555.0, result.Accrual)
 		assert.Equal(t, order.KeyHash, result.KeyHash)
 		assert.Equal(t, order.KeyHashModule, result.KeyHashModule)
 		assert.Equal(t, int64(1), result.Version)
 
 		var balance float64
-		err = pool.QueryRow(ctx, `SELECT balance FROM gofemart.balance WHERE username = $1`, "testUser").Scan(&balance)
+		err = pool.QueryRow(ctx, `SELECT balance FROM gofemart.balance WHERE user_id = $1`, internal.TestUserID).Scan(&balance)
 		assert.NoError(t, err)
 		assert.Equal(t, 655., balance)
+
+		history, err := orderRepository.FindOrderHistory(ctx, "12345678903")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, "NEW", history[0].Status)
+		assert.Nil(t, history[0].Accrual)
+		assert.Equal(t, "PROCESSED", history[1].Status)
+		assert.Equal(t, 555.0, *history[1].Accrual)
+	})
+	t.Run("FindOrdersPage", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		for i, orderNumber := range []string{"12345678903", "4561261212345467", "79927398713"} {
+			next := order
+			next.OrderNumber = orderNumber
+			next.CreateDate = time.Date(2024, 8, i+1, 0, 0, 0, 0, time.UTC)
+			assert.NoError(t, orderRepository.CreateOrder(ctx, next))
+		}
+
+		first, err := orderRepository.FindOrdersPage(ctx, internal.TestUserID, model.PageRequest{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(first.Items))
+		assert.Equal(t, "12345678903", first.Items[0].OrderNumber)
+		assert.NotEmpty(t, first.NextCursor)
+
+		cursor, err := model.DecodePageCursor(first.NextCursor)
+		assert.NoError(t, err)
+
+		second, err := orderRepository.FindOrdersPage(ctx, internal.TestUserID, model.PageRequest{Limit: 2, Cursor: &cursor})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(second.Items))
+		assert.Equal(t, "79927398713", second.Items[0].OrderNumber)
+		assert.Empty(t, second.NextCursor)
+
+		desc, err := orderRepository.FindOrdersPage(ctx, internal.TestUserID, model.PageRequest{Limit: 10, Desc: true, Statuses: []string{"NEW"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(desc.Items))
+		assert.Equal(t, "79927398713", desc.Items[0].OrderNumber)
+	})
+	t.Run("CreateOrders", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := orderRepository.CreateOrder(ctx, order)
+		assert.NoError(t, err)
+
+		first := order
+		second := order
+		second.OrderNumber = "79927398713"
+		created, err := orderRepository.CreateOrders(ctx, []model.Order{first, second})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"79927398713"}, created)
+
+		owners, err := orderRepository.FindOrderOwners(ctx, []string{"12345678903", "79927398713", "4561261212345467"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"12345678903": internal.TestUserID, "79927398713": internal.TestUserID}, owners)
+
+		history, err := orderRepository.FindOrderHistory(ctx, "79927398713")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+	})
+
+	t.Run("ApplyOrderAccrual", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`, internal.TestUserID, 100, 0); err != nil {
+			t.Fatalf("failed to insert balance: %v", err)
+		}
+
+		err := orderRepository.CreateOrder(ctx, order)
+		assert.NoError(t, err)
+
+		claimed, err := orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(claimed))
+
+		if _, err := pool.Exec(ctx, `UPDATE gofemart.order SET dead_lettered_at = now() WHERE order_number = $1`, order.OrderNumber); err != nil {
+			t.Fatalf("failed to dead-letter order: %v", err)
+		}
+		assert.NoError(t, orderRepository.ApplyOrderAccrual(ctx, order.OrderNumber, "PROCESSING", 0))
+
+		deadLetterOrders, err := orderRepository.FindDeadLetterOrders(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(deadLetterOrders))
+
+		assert.ErrorIs(t, orderRepository.ApplyOrderAccrual(ctx, "79927398713", "PROCESSED", 500), model.ErrOrderWasNotFound)
+		assert.NoError(t, orderRepository.ApplyOrderAccrual(ctx, order.OrderNumber, "PROCESSED", 500))
+		assert.NoError(t, orderRepository.ApplyOrderAccrual(ctx, order.OrderNumber, "PROCESSED", 500))
+		assert.ErrorIs(t, orderRepository.ApplyOrderAccrual(ctx, order.OrderNumber, "INVALID", 0), model.ErrOrderIsAlreadyProcessed)
+
+		result, err := orderRepository.FindOrder(ctx, order.OrderNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, "PROCESSED", result.Status)
+		assert.Equal(t, 500., result.Accrual)
+	})
+
+	t.Run("AckOrders", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`, internal.TestUserID, 100, 0); err != nil {
+			t.Fatalf("failed to insert balance: %v", err)
+		}
+
+		for _, orderNumber := range []string{"12345678903", "79927398713", "4561261212345467"} {
+			next := order
+			next.OrderNumber = orderNumber
+			assert.NoError(t, orderRepository.CreateOrder(ctx, next))
+		}
+
+		claimed, err := orderRepository.ClaimOrders(ctx, "worker-1", []int{0}, 10, time.Minute, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(claimed))
+
+		stale := claimed[2]
+		stale.Version++
+		withoutBalance := claimed[1]
+		withoutBalance.UserID = "00000000-0000-0000-0000-000000000000"
+		failed, err := orderRepository.AckOrders(ctx, "worker-1", []model.OrderAccrual{
+			{Order: claimed[0], Status: "PROCESSED", Accrual: 100},
+			{Order: claimed[1], Status: "PROCESSED", Accrual: 50},
+			{Order: stale, Status: "PROCESSED", Accrual: 1000},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(failed))
+		assert.ErrorIs(t, failed[stale.OrderNumber], model.ErrOrderLeaseIsLost)
+
+		failed, err = orderRepository.AckOrders(ctx, "worker-1", []model.OrderAccrual{
+			{Order: withoutBalance, Status: "PROCESSED", Accrual: 50},
+		})
+		assert.NoError(t, err)
+		assert.ErrorIs(t, failed[withoutBalance.OrderNumber], model.ErrUserBalanceWasNotFound)
+
+		var balance float64
+		err = pool.QueryRow(ctx, `SELECT balance FROM gofemart.balance WHERE user_id = $1`, internal.TestUserID).Scan(&balance)
+		assert.NoError(t, err)
+		assert.Equal(t, 250., balance)
+
+		result, err := orderRepository.FindOrder(ctx, stale.OrderNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, "NEW", result.Status)
+
+		history, err := orderRepository.FindOrderHistory(ctx, claimed[0].OrderNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(history))
 	})
 }
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+const (
+	rateRecoveryInterval = time.Minute
+	rateRecoveryFactor   = 2
+)
+
+type RateLimitRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewRateLimitRepository(pool *pgxpool.Pool, logger *zap.Logger) *RateLimitRepository {
+	return &RateLimitRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *RateLimitRepository) InitRateLimit(ctx context.Context, name string, ratePerSecond float64, capacity float64) error {
+	query := `insert into gofemart.rate_limit(name, tokens, capacity, rate_per_second, max_rate_per_second, update_date, adjust_date)
+			  values ($1, $3, $3, $2, $2, now(), now())
+			  on conflict (name) do update set capacity = excluded.capacity, max_rate_per_second = excluded.max_rate_per_second,
+			  rate_per_second = least(gofemart.rate_limit.rate_per_second, excluded.max_rate_per_second),
+			  tokens = least(gofemart.rate_limit.tokens, excluded.capacity)`
+	if _, err := r.pool.Exec(ctx, query, name, ratePerSecond, capacity); err != nil {
+		r.logger.Error("Error during init rate limit", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *RateLimitRepository) TakeToken(ctx context.Context, name string) (time.Duration, error) {
+	var wait time.Duration
+	err := transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		var tokens, capacity, ratePerSecond, maxRatePerSecond float64
+		var pausedUntil *time.Time
+		var updateDate, adjustDate, now time.Time
+		query := `select tokens, capacity, rate_per_second, max_rate_per_second, paused_until, update_date, adjust_date, now()
+				  from gofemart.rate_limit where name = $1 for update`
+		err := tx.QueryRow(ctx, query, name).
+			Scan(&tokens, &capacity, &ratePerSecond, &maxRatePerSecond, &pausedUntil, &updateDate, &adjustDate, &now)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.ErrRateLimitWasNotFound
+			}
+			return err
+		}
+
+		if pausedUntil != nil && pausedUntil.After(now) {
+			wait = pausedUntil.Sub(now)
+			return nil
+		}
+
+		// a rate lowered after a 429 goes back to the configured one step by step while no new 429 arrives
+		if ratePerSecond < maxRatePerSecond && now.Sub(adjustDate) >= rateRecoveryInterval {
+			ratePerSecond = math.Min(maxRatePerSecond, ratePerSecond*rateRecoveryFactor)
+			adjustDate = now
+		}
+
+		tokens = math.Min(capacity, tokens+now.Sub(updateDate).Seconds()*ratePerSecond)
+		if tokens >= 1 {
+			tokens--
+		} else {
+			wait = time.Duration((1 - tokens) / ratePerSecond * float64(time.Second))
+		}
+
+		query = `update gofemart.rate_limit set tokens = $1, rate_per_second = $2, update_date = $3, adjust_date = $4
+				 where name = $5`
+		_, err = tx.Exec(ctx, query, tokens, ratePerSecond, now, adjustDate, name)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Error during take rate limit token", zap.String("name", name), zap.Error(err))
+		return 0, err
+	}
+	return wait, nil
+}
+
+func (r *RateLimitRepository) PauseRateLimit(ctx context.Context, name string, duration time.Duration) error {
+	query := `update gofemart.rate_limit set tokens = 0, update_date = now(), adjust_date = now(),
+			  paused_until = greatest(coalesce(paused_until, now()), now() + make_interval(secs => $2))
+			  where name = $1`
+	if _, err := r.pool.Exec(ctx, query, name, duration.Seconds()); err != nil {
+		r.logger.Error("Error during pause rate limit", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *RateLimitRepository) ChangeRate(ctx context.Context, name string, ratePerSecond float64) error {
+	query := `update gofemart.rate_limit set rate_per_second = least($2, max_rate_per_second), adjust_date = now()
+			  where name = $1`
+	if _, err := r.pool.Exec(ctx, query, name, ratePerSecond); err != nil {
+		r.logger.Error("Error during change rate limit", zap.String("name", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
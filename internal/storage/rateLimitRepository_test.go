@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	rateLimitRepository := NewRateLimitRepository(pool, logger)
+
+	t.Run("TakeToken", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		_, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.ErrorIs(t, err, model.ErrRateLimitWasNotFound)
+
+		assert.NoError(t, rateLimitRepository.InitRateLimit(ctx, "accrual", 0.1, 2))
+		for i := 0; i < 2; i++ {
+			wait, err := rateLimitRepository.TakeToken(ctx, "accrual")
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), wait)
+		}
+
+		wait, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+		assert.Greater(t, wait, 9*time.Second, "empty bucket should be refilled with a rate of one token per 10 seconds")
+	})
+
+	t.Run("PauseRateLimit", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		assert.NoError(t, rateLimitRepository.InitRateLimit(ctx, "accrual", 100, 10))
+		assert.NoError(t, rateLimitRepository.PauseRateLimit(ctx, "accrual", time.Minute))
+		assert.NoError(t, rateLimitRepository.PauseRateLimit(ctx, "accrual", time.Second))
+
+		wait, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+		assert.Greater(t, wait, 50*time.Second, "shorter pause should not shorten the longer one")
+	})
+
+	t.Run("ChangeRate", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		assert.NoError(t, rateLimitRepository.InitRateLimit(ctx, "accrual", 100, 1))
+		assert.NoError(t, rateLimitRepository.ChangeRate(ctx, "accrual", 0.5))
+
+		_, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+
+		wait, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+		assert.Greater(t, wait, time.Second)
+
+		assert.NoError(t, rateLimitRepository.InitRateLimit(ctx, "accrual", 100, 1))
+		var ratePerSecond float64
+		err = pool.QueryRow(ctx, `SELECT rate_per_second FROM gofemart.rate_limit WHERE name = 'accrual'`).Scan(&ratePerSecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.5, ratePerSecond, "restarted replica should not reset the lowered rate")
+	})
+
+	t.Run("RestoreRate", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		assert.NoError(t, rateLimitRepository.InitRateLimit(ctx, "accrual", 1, 1))
+		assert.NoError(t, rateLimitRepository.ChangeRate(ctx, "accrual", 0.4))
+		if _, err := pool.Exec(ctx, `UPDATE gofemart.rate_limit SET adjust_date = now() - interval '2 minutes' WHERE name = 'accrual'`); err != nil {
+			t.Fatalf("failed to update rate limit: %s", err)
+		}
+
+		_, err := rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+
+		var ratePerSecond float64
+		err = pool.QueryRow(ctx, `SELECT rate_per_second FROM gofemart.rate_limit WHERE name = 'accrual'`).Scan(&ratePerSecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.8, ratePerSecond)
+
+		_, err = rateLimitRepository.TakeToken(ctx, "accrual")
+		assert.NoError(t, err)
+		err = pool.QueryRow(ctx, `SELECT rate_per_second FROM gofemart.rate_limit WHERE name = 'accrual'`).Scan(&ratePerSecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.8, ratePerSecond, "rate should not be raised again before the recovery interval")
+	})
+}
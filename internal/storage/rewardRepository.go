@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type RewardRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewRewardRepository(pool *pgxpool.Pool, logger *zap.Logger) *RewardRepository {
+	return &RewardRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *RewardRepository) CreateReward(ctx context.Context, reward model.Reward) error {
+	query := `insert into gofemart.reward(id, name, description, price, stock, valid_from, valid_to, archived, create_date, opt_lock)
+			  values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.pool.Exec(ctx, query, reward.ID, reward.Name, reward.Description, reward.Price, reward.Stock,
+		reward.ValidFrom, reward.ValidTo, reward.Archived, reward.CreateDate, 0)
+	if err != nil {
+		r.logger.Error("Error during create reward", zap.String("rewardID", reward.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *RewardRepository) UpdateReward(ctx context.Context, reward model.Reward) error {
+	query := `update gofemart.reward
+			  set name = $1, description = $2, price = $3, stock = $4, valid_from = $5, valid_to = $6, opt_lock = opt_lock + 1
+			  where id = $7 and not archived`
+	result, err := r.pool.Exec(ctx, query, reward.Name, reward.Description, reward.Price, reward.Stock,
+		reward.ValidFrom, reward.ValidTo, reward.ID)
+	if err != nil {
+		r.logger.Error("Error during update reward", zap.String("rewardID", reward.ID), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrRewardWasNotFound
+	}
+	return nil
+}
+
+func (r *RewardRepository) ArchiveReward(ctx context.Context, rewardID string) error {
+	query := "update gofemart.reward set archived = true, opt_lock = opt_lock + 1 where id = $1 and not archived"
+	result, err := r.pool.Exec(ctx, query, rewardID)
+	if err != nil {
+		r.logger.Error("Error during archive reward", zap.String("rewardID", rewardID), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrRewardWasNotFound
+	}
+	return nil
+}
+
+func (r *RewardRepository) FindReward(ctx context.Context, rewardID string) (model.Reward, error) {
+	var reward model.Reward
+	query := `select id, name, description, price, stock, valid_from, valid_to, archived, create_date, opt_lock
+			  from gofemart.reward where id = $1`
+	err := r.pool.QueryRow(ctx, query, rewardID).Scan(&reward.ID, &reward.Name, &reward.Description, &reward.Price,
+		&reward.Stock, &reward.ValidFrom, &reward.ValidTo, &reward.Archived, &reward.CreateDate, &reward.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Reward{}, model.ErrRewardWasNotFound
+		}
+		return model.Reward{}, err
+	}
+
+	return reward, nil
+}
+
+func (r *RewardRepository) FindAllRewards(ctx context.Context) ([]model.Reward, error) {
+	query := `select id, name, description, price, stock, valid_from, valid_to, archived, create_date, opt_lock
+			  from gofemart.reward order by create_date`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rewards []model.Reward
+	for rows.Next() {
+		var reward model.Reward
+		if err := rows.Scan(&reward.ID, &reward.Name, &reward.Description, &reward.Price, &reward.Stock,
+			&reward.ValidFrom, &reward.ValidTo, &reward.Archived, &reward.CreateDate, &reward.Version); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		rewards = append(rewards, reward)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rewards, nil
+}
+
+func (r *RewardRepository) Redeem(ctx context.Context, reward model.Reward, balance model.Balance, code string) (model.Redemption, error) {
+	redemptionID, err := uuid.NewRandom()
+	if err != nil {
+		r.logger.Error("Error during generate UUID", zap.Error(err))
+		return model.Redemption{}, err
+	}
+
+	withdrawID, err := uuid.NewRandom()
+	if err != nil {
+		r.logger.Error("Error during generate UUID", zap.Error(err))
+		return model.Redemption{}, err
+	}
+
+	redemption := model.Redemption{
+		ID:         redemptionID.String(),
+		RewardID:   reward.ID,
+		UserID:     balance.UserID,
+		Code:       code,
+		Sum:        reward.Price,
+		CreateDate: time.Now(),
+	}
+
+	err = transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		reserveQuery := `update gofemart.reward set stock = stock - 1, opt_lock = opt_lock + 1
+						 where id = $1 and price = $2 and stock > 0 and not archived
+						   and (valid_from is null or valid_from <= $3) and (valid_to is null or valid_to > $3)`
+		result, err := tx.Exec(ctx, reserveQuery, reward.ID, reward.Price, redemption.CreateDate)
+		if err != nil {
+			r.logger.Error("Error during reserve reward", zap.String("rewardID", reward.ID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return model.ErrRewardIsNotAvailable
+		}
+
+		balanceQuery := "update gofemart.balance set balance = $1, opt_lock = $2 where user_id = $3 and opt_lock = $4"
+		result, err = tx.Exec(ctx, balanceQuery, balance.Balance-reward.Price, balance.Version+1, balance.UserID, balance.Version)
+		if err != nil {
+			r.logger.Error("Error during change balance", zap.String("userID", balance.UserID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			r.logger.Error("User balance has changed in other transaction", zap.String("userID", balance.UserID))
+			return model.ErrUserBalanceHasChanged
+		}
+
+		withdrawQuery := "insert into gofemart.withdrawal(id, order_number, user_id, sum, create_date, reward_id) values ($1, $2, $3, $4, $5, $6)"
+		_, err = tx.Exec(ctx, withdrawQuery, withdrawID, redemption.Code, redemption.UserID, redemption.Sum, redemption.CreateDate, reward.ID)
+		if err != nil {
+			r.logger.Error("Error during create withdrawal", zap.String("code", redemption.Code), zap.Error(err))
+			return err
+		}
+
+		redemptionQuery := "insert into gofemart.redemption(id, reward_id, user_id, code, sum, create_date) values ($1, $2, $3, $4, $5, $6)"
+		_, err = tx.Exec(ctx, redemptionQuery, redemption.ID, redemption.RewardID, redemption.UserID, redemption.Code,
+			redemption.Sum, redemption.CreateDate)
+		if err != nil {
+			r.logger.Error("Error during create redemption", zap.String("code", redemption.Code), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Redemption{}, err
+	}
+
+	return redemption, nil
+}
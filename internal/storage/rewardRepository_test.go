@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestRewardRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	rewardRepository := NewRewardRepository(pool, logger)
+
+	reward := model.Reward{
+		ID:          "8a3c0a6e-4d0c-4f39-9c1e-0f5a2d3c8b11",
+		Name:        "Coffee",
+		Description: "Large latte",
+		Price:       100.,
+		Stock:       1,
+		CreateDate:  time.Now(),
+	}
+
+	t.Run("FindReward", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := rewardRepository.CreateReward(ctx, reward)
+		assert.NoError(t, err)
+
+		result, err := rewardRepository.FindReward(ctx, reward.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, reward.Name, result.Name)
+		assert.Equal(t, reward.Price, result.Price)
+		assert.Equal(t, reward.Stock, result.Stock)
+	})
+
+	t.Run("ArchiveReward", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		err := rewardRepository.CreateReward(ctx, reward)
+		assert.NoError(t, err)
+
+		err = rewardRepository.ArchiveReward(ctx, reward.ID)
+		assert.NoError(t, err)
+
+		err = rewardRepository.ArchiveReward(ctx, reward.ID)
+		assert.Equal(t, model.ErrRewardWasNotFound, err)
+	})
+
+	t.Run("Redeem", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.balance (user_id, balance, opt_lock) VALUES ($1, $2, $3)`, internal.TestUserID, 150, 0); err != nil {
+			t.Fatalf("failed to insert balance: %v", err)
+		}
+		err := rewardRepository.CreateReward(ctx, reward)
+		assert.NoError(t, err)
+
+		balance := model.Balance{UserID: internal.TestUserID, Balance: 150, Version: 0}
+		redemption, err := rewardRepository.Redeem(ctx, reward, balance, "CODE1")
+		assert.NoError(t, err)
+		assert.Equal(t, "CODE1", redemption.Code)
+		assert.Equal(t, 100., redemption.Sum)
+
+		var stock int64
+		err = pool.QueryRow(ctx, `SELECT stock FROM gofemart.reward WHERE id = $1`, reward.ID).Scan(&stock)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), stock)
+
+		var withdrawn float64
+		err = pool.QueryRow(ctx, `SELECT sum FROM gofemart.withdrawal WHERE reward_id = $1`, reward.ID).Scan(&withdrawn)
+		assert.NoError(t, err)
+		assert.Equal(t, 100., withdrawn)
+
+		_, err = rewardRepository.Redeem(ctx, reward, model.Balance{UserID: internal.TestUserID, Balance: 50, Version: 1}, "CODE2")
+		assert.Equal(t, model.ErrRewardIsNotAvailable, err)
+	})
+}
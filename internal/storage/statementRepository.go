@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+type StatementRepository struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+func NewStatementRepository(pool *pgxpool.Pool, logger *zap.Logger) *StatementRepository {
+	return &StatementRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+func (r *StatementRepository) FindStatementsToGenerate(ctx context.Context, periodStart, periodEnd time.Time, afterUserID string, limit int) ([]model.Statement, error) {
+	var after *string
+	if afterUserID != "" {
+		after = &afterUserID
+	}
+
+	query := `
+		with processed as (
+			select o.user_id, o.accrual, min(h.create_date) as processed_date
+			from gofemart.order o join gofemart.order_status_history h on h.order_number = o.order_number and h.status = o.status
+			where o.status = $4
+			group by o.order_number, o.user_id, o.accrual
+		), accruals as (
+			select user_id,
+				   coalesce(sum(accrual) filter (where processed_date < $1), 0) as opening,
+				   coalesce(sum(accrual) filter (where processed_date >= $1), 0) as period
+			from processed
+			where processed_date < $2
+			group by user_id
+		), withdrawals as (
+			select user_id,
+				   coalesce(sum(sum) filter (where create_date < $1), 0) as opening,
+				   coalesce(sum(sum) filter (where create_date >= $1), 0) as period
+			from gofemart.withdrawal
+			where create_date < $2
+			group by user_id
+		), totals as (
+			select coalesce(a.user_id, w.user_id) as user_id,
+				   coalesce(a.opening, 0) - coalesce(w.opening, 0) as opening_balance,
+				   coalesce(a.period, 0) as accrued,
+				   coalesce(w.period, 0) as withdrawn
+			from accruals a full outer join withdrawals w on a.user_id = w.user_id
+		)
+		select t.user_id, u.username, t.opening_balance, t.accrued, t.withdrawn
+		from totals t join gofemart.user u on u.id = t.user_id
+		where u.deleted_date is null and (t.opening_balance <> 0 or t.accrued <> 0 or t.withdrawn <> 0)
+		  and not exists (select 1 from gofemart.statement s where s.user_id = t.user_id and s.period_start = $1)
+		  and ($5::uuid is null or t.user_id > $5)
+		order by t.user_id
+		limit $3`
+	rows, err := r.pool.Query(ctx, query, periodStart, periodEnd, limit, model.ProcessedOrderStatus, after)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []model.Statement
+	for rows.Next() {
+		statement := model.Statement{PeriodStart: periodStart, PeriodEnd: periodEnd}
+		if err := rows.Scan(&statement.UserID, &statement.Username, &statement.OpeningBalance, &statement.Accrued, &statement.Withdrawn); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+		statement.ClosingBalance = statement.OpeningBalance + statement.Accrued - statement.Withdrawn
+
+		statements = append(statements, statement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+func (r *StatementRepository) CreateStatement(ctx context.Context, statement model.Statement) error {
+	query := `insert into gofemart.statement(id, user_id, period_start, period_end, opening_balance, accrued, withdrawn, closing_balance, pdf_key, csv_key, create_date)
+			  values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			  on conflict (user_id, period_start) do nothing`
+	_, err := r.pool.Exec(ctx, query, statement.ID, statement.UserID, statement.PeriodStart, statement.PeriodEnd,
+		statement.OpeningBalance, statement.Accrued, statement.Withdrawn, statement.ClosingBalance,
+		statement.PdfKey, statement.CsvKey, statement.CreateDate)
+	if err != nil {
+		r.logger.Error("Error during create statement", zap.String("userID", statement.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *StatementRepository) FindStatements(ctx context.Context, userID string) ([]model.Statement, error) {
+	query := `select id, user_id, period_start, period_end, opening_balance, accrued, withdrawn, closing_balance, pdf_key, csv_key, create_date
+			  from gofemart.statement where user_id = $1 order by period_start desc`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []model.Statement
+	for rows.Next() {
+		var statement model.Statement
+		if err := rows.Scan(&statement.ID, &statement.UserID, &statement.PeriodStart, &statement.PeriodEnd, &statement.OpeningBalance,
+			&statement.Accrued, &statement.Withdrawn, &statement.ClosingBalance, &statement.PdfKey, &statement.CsvKey, &statement.CreateDate); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		statements = append(statements, statement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
+
+func (r *StatementRepository) FindStatement(ctx context.Context, statementID string) (model.Statement, error) {
+	var statement model.Statement
+	query := `select id, user_id, period_start, period_end, opening_balance, accrued, withdrawn, closing_balance, pdf_key, csv_key, create_date
+			  from gofemart.statement where id = $1`
+	err := r.pool.QueryRow(ctx, query, statementID).Scan(&statement.ID, &statement.UserID, &statement.PeriodStart, &statement.PeriodEnd,
+		&statement.OpeningBalance, &statement.Accrued, &statement.Withdrawn, &statement.ClosingBalance, &statement.PdfKey, &statement.CsvKey,
+		&statement.CreateDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Statement{}, model.ErrStatementWasNotFound
+		}
+		return model.Statement{}, err
+	}
+
+	return statement, nil
+}
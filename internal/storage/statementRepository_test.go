@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"testing"
+	"time"
+)
+
+func TestStatementRepository(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	pool, cleanup := internal.InitPostgresIntegrationTest(t, ctx, logger)
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Fatalf("failed to cleanup test database: %s", err)
+		}
+	})
+
+	statementRepository := NewStatementRepository(pool, logger)
+	periodStart := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("FindStatementsToGenerate", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		orderQuery := `INSERT INTO gofemart.order (order_number, user_id, create_date, last_modify_date, status, accrual, key_hash, key_hash_module, opt_lock)
+					   VALUES ($1, $2, $3, $3, $4, $5, 0, 0, 0)`
+		historyQuery := `INSERT INTO gofemart.order_status_history (order_number, status, accrual, create_date) VALUES ($1, $2, $3, $4)`
+		for _, order := range []struct {
+			number        string
+			uploadDate    time.Time
+			processedDate time.Time
+			accrual       float64
+		}{
+			{"12345678903", periodStart.AddDate(0, 0, -15), periodStart.AddDate(0, 0, -10), 100},
+			{"79927398713", periodStart.AddDate(0, 0, -3), periodStart.AddDate(0, 0, 10), 50},
+			{"4561261212345467", periodStart.AddDate(0, 0, 20), periodEnd.AddDate(0, 0, 1), 500},
+		} {
+			if _, err := pool.Exec(ctx, orderQuery, order.number, internal.TestUserID, order.uploadDate, model.ProcessedOrderStatus, order.accrual); err != nil {
+				t.Fatalf("failed to insert order: %v", err)
+			}
+			if _, err := pool.Exec(ctx, historyQuery, order.number, model.ProcessedOrderStatus, order.accrual, order.processedDate); err != nil {
+				t.Fatalf("failed to insert order status transition: %v", err)
+			}
+		}
+		withdrawalQuery := `INSERT INTO gofemart.withdrawal (id, order_number, user_id, sum, create_date) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := pool.Exec(ctx, withdrawalQuery, "0b6b1a9e-2f4c-4b5a-8d1e-3c7f9a2b4d10", "2377225624", internal.TestUserID, 30, periodStart.AddDate(0, 0, 5)); err != nil {
+			t.Fatalf("failed to insert withdrawal: %v", err)
+		}
+
+		statements, err := statementRepository.FindStatementsToGenerate(ctx, periodStart, periodEnd, "", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(statements))
+		assert.Equal(t, internal.TestUserID, statements[0].UserID)
+		assert.Equal(t, "testUser", statements[0].Username)
+		assert.Equal(t, 100., statements[0].OpeningBalance)
+		assert.Equal(t, 50., statements[0].Accrued)
+		assert.Equal(t, 30., statements[0].Withdrawn)
+		assert.Equal(t, 120., statements[0].ClosingBalance)
+
+		statement := statements[0]
+		statement.ID = "5f0c3a8e-1b2d-4c6e-9f7a-2d4b6c8e0a13"
+		statement.PdfKey = "statements/1.pdf"
+		statement.CsvKey = "statements/1.csv"
+		statement.CreateDate = periodEnd
+		err = statementRepository.CreateStatement(ctx, statement)
+		assert.NoError(t, err)
+
+		statements, err = statementRepository.FindStatementsToGenerate(ctx, periodStart, periodEnd, "", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(statements))
+
+		result, err := statementRepository.FindStatement(ctx, statement.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 120., result.ClosingBalance)
+
+		userStatements, err := statementRepository.FindStatements(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(userStatements))
+	})
+}
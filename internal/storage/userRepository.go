@@ -2,10 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
+	"time"
 )
 
 type UserRepository struct {
@@ -22,7 +24,10 @@ func NewUserRepository(pool *pgxpool.Pool, logger *zap.Logger) *UserRepository {
 
 func (r *UserRepository) ExistUser(ctx context.Context, userName string) (bool, error) {
 	var exist bool
-	query := "select exists(select 1 from gofemart.user where username = $1)"
+	query := `
+		select exists(select 1 from gofemart.user where lower(username) = lower($1))
+		    or exists(select 1 from gofemart.user_identifier where value = lower($1) and verified)
+	`
 	err := r.pool.QueryRow(ctx, query, userName).Scan(&exist)
 	if err != nil {
 		return false, err
@@ -31,32 +36,284 @@ func (r *UserRepository) ExistUser(ctx context.Context, userName string) (bool,
 	return exist, nil
 }
 
-func (r *UserRepository) CreateUser(ctx context.Context, userName string, password string) error {
+func (r *UserRepository) CreateUser(ctx context.Context, user model.User) error {
 	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
-		query := "insert into gofemart.user(username, password) values ($1, $2)"
-		_, err := tx.Exec(ctx, query, userName, password)
+		taken, err := r.isLoginTakenByIdentifier(ctx, tx, user.Username, user.ID)
 		if err != nil {
-			r.logger.Error("Error during create user", zap.String("userName", userName), zap.Error(err))
 			return err
 		}
 
-		balanceQuery := "insert into gofemart.balance(username, balance, opt_lock) values ($1, $2, $3)"
-		_, err = tx.Exec(ctx, balanceQuery, userName, 0, 0)
+		if taken {
+			return model.ErrUserAlreadyExists
+		}
+
+		query := "insert into gofemart.user(id, username, password) values ($1, $2, $3)"
+		_, err = tx.Exec(ctx, query, user.ID, user.Username, user.Password)
 		if err != nil {
-			r.logger.Error("Error during create balance", zap.String("userName", userName), zap.Error(err))
+			if isUniqueViolation(err) {
+				return model.ErrUserAlreadyExists
+			}
+			r.logger.Error("Error during create user", zap.String("userName", user.Username), zap.Error(err))
+			return err
+		}
+
+		balanceQuery := "insert into gofemart.balance(user_id, balance, opt_lock) values ($1, $2, $3)"
+		_, err = tx.Exec(ctx, balanceQuery, user.ID, 0, 0)
+		if err != nil {
+			r.logger.Error("Error during create balance", zap.String("userID", user.ID), zap.Error(err))
 			return err
 		}
 		return nil
 	})
 }
 
-func (r *UserRepository) FindUser(ctx context.Context, userName string) (model.User, error) {
-	var user model.User
-	query := "select username, password from gofemart.user where username = $1"
-	err := r.pool.QueryRow(ctx, query, userName).Scan(&user.Username, &user.Password)
+func (r *UserRepository) FindUser(ctx context.Context, identifier string) (model.User, error) {
+	query := `
+		select u.id, u.username, u.password, 0 as priority from gofemart.user u
+		where lower(u.username) = lower($1) and u.deleted_date is null
+		union all
+		select u.id, u.username, u.password, 1 as priority from gofemart.user u
+		join gofemart.user_identifier i on i.user_id = u.id
+		where i.value = $1 and i.verified and u.deleted_date is null
+		order by priority
+	`
+	rows, err := r.pool.Query(ctx, query, identifier)
 	if err != nil {
 		return model.User{}, err
 	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		var priority int
+		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &priority); err != nil {
+			return model.User{}, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return model.User{}, err
+	}
+
+	if len(users) == 0 {
+		return model.User{}, model.ErrUserWasNotFound
+	}
+	for _, user := range users[1:] {
+		if user.ID != users[0].ID {
+			r.logger.Error("Login matches several users", zap.String("userID", users[0].ID), zap.String("otherUserID", user.ID))
+			return model.User{}, model.ErrUserWasNotFound
+		}
+	}
+	return users[0], nil
+}
+
+func (r *UserRepository) ChangeUsername(ctx context.Context, userID string, userName string) error {
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		taken, err := r.isLoginTakenByIdentifier(ctx, tx, userName, userID)
+		if err != nil {
+			return err
+		}
+
+		if taken {
+			return model.ErrUserAlreadyExists
+		}
+
+		query := "update gofemart.user set username = $1 where id = $2 and deleted_date is null"
+		result, err := tx.Exec(ctx, query, userName, userID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return model.ErrUserAlreadyExists
+			}
+			r.logger.Error("Error during change username", zap.String("userID", userID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return model.ErrUserWasNotFound
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) SaveIdentifier(ctx context.Context, identifier model.UserIdentifier) (model.UserIdentifier, error) {
+	err := transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		var taken bool
+		existQuery := "select exists(select 1 from gofemart.user_identifier where value = $1 and verified)"
+		if err := tx.QueryRow(ctx, existQuery, identifier.Value).Scan(&taken); err != nil {
+			r.logger.Error("Error during check exist identifier", zap.String("userID", identifier.UserID), zap.Error(err))
+			return err
+		}
+
+		if taken {
+			return model.ErrIdentifierAlreadyExists
+		}
+
+		query := `
+			insert into gofemart.user_identifier(id, user_id, type, value, code_hash, code_expire_date, create_date)
+			values ($1, $2, $3, $4, $5, $6, $7)
+			on conflict (user_id, value) do update
+			set code_hash = excluded.code_hash, code_expire_date = excluded.code_expire_date, attempt_count = 0
+			where not gofemart.user_identifier.verified
+			returning id, create_date
+		`
+		err := tx.QueryRow(ctx, query, identifier.ID, identifier.UserID, identifier.Type, identifier.Value,
+			identifier.CodeHash, identifier.CodeExpireDate, identifier.CreateDate).Scan(&identifier.ID, &identifier.CreateDate)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return model.ErrIdentifierAlreadyExists
+			}
+			r.logger.Error("Error during save identifier", zap.String("userID", identifier.UserID), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return model.UserIdentifier{}, err
+	}
+
+	return identifier, nil
+}
+
+func (r *UserRepository) FindIdentifiers(ctx context.Context, userID string) ([]model.UserIdentifier, error) {
+	query := `select id, user_id, type, value, verified, attempt_count, create_date
+			  from gofemart.user_identifier where user_id = $1 order by create_date`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identifiers []model.UserIdentifier
+	for rows.Next() {
+		var identifier model.UserIdentifier
+		if err := rows.Scan(&identifier.ID, &identifier.UserID, &identifier.Type, &identifier.Value, &identifier.Verified,
+			&identifier.AttemptCount, &identifier.CreateDate); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		identifiers = append(identifiers, identifier)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identifiers, nil
+}
+
+func (r *UserRepository) FindIdentifier(ctx context.Context, identifierID string) (model.UserIdentifier, error) {
+	var identifier model.UserIdentifier
+	var codeHash *string
+	var codeExpireDate *time.Time
+	query := `select id, user_id, type, value, verified, code_hash, code_expire_date, attempt_count, create_date
+			  from gofemart.user_identifier where id = $1`
+	err := r.pool.QueryRow(ctx, query, identifierID).Scan(&identifier.ID, &identifier.UserID, &identifier.Type, &identifier.Value,
+		&identifier.Verified, &codeHash, &codeExpireDate, &identifier.AttemptCount, &identifier.CreateDate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.UserIdentifier{}, model.ErrIdentifierWasNotFound
+		}
+		return model.UserIdentifier{}, err
+	}
+
+	if codeHash != nil {
+		identifier.CodeHash = *codeHash
+	}
+	if codeExpireDate != nil {
+		identifier.CodeExpireDate = *codeExpireDate
+	}
+	return identifier, nil
+}
+
+func (r *UserRepository) IncrementVerificationAttempts(ctx context.Context, identifierID string) error {
+	query := "update gofemart.user_identifier set attempt_count = attempt_count + 1 where id = $1 and not verified"
+	_, err := r.pool.Exec(ctx, query, identifierID)
+	if err != nil {
+		r.logger.Error("Error during increment verification attempts", zap.String("identifierID", identifierID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) VerifyIdentifier(ctx context.Context, identifier model.UserIdentifier) error {
+	return transactional(ctx, r.logger, r.pool, func(tx pgx.Tx) error {
+		if err := lockLogin(ctx, tx, identifier.Value); err != nil {
+			r.logger.Error("Error during lock login", zap.String("identifierID", identifier.ID), zap.Error(err))
+			return err
+		}
+
+		var taken bool
+		existQuery := "select exists(select 1 from gofemart.user where lower(username) = $1 and id <> $2)"
+		if err := tx.QueryRow(ctx, existQuery, identifier.Value, identifier.UserID).Scan(&taken); err != nil {
+			r.logger.Error("Error during check exist user", zap.String("identifierID", identifier.ID), zap.Error(err))
+			return err
+		}
+
+		if taken {
+			return model.ErrIdentifierAlreadyExists
+		}
+
+		query := `update gofemart.user_identifier set verified = true, code_hash = null, code_expire_date = null
+				  where id = $1 and not verified`
+		result, err := tx.Exec(ctx, query, identifier.ID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return model.ErrIdentifierAlreadyExists
+			}
+			r.logger.Error("Error during verify identifier", zap.String("identifierID", identifier.ID), zap.Error(err))
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return model.ErrIdentifierWasNotFound
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) DeleteIdentifier(ctx context.Context, userID string, identifierID string) error {
+	query := "delete from gofemart.user_identifier where id = $1 and user_id = $2"
+	result, err := r.pool.Exec(ctx, query, identifierID, userID)
+	if err != nil {
+		r.logger.Error("Error during delete identifier", zap.String("identifierID", identifierID), zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrIdentifierWasNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) IsUserActive(ctx context.Context, userID string) (bool, error) {
+	var active bool
+	query := "select exists(select 1 from gofemart.user where id = $1 and deleted_date is null)"
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&active); err != nil {
+		r.logger.Error("Error during check user is active", zap.String("userID", userID), zap.Error(err))
+		return false, err
+	}
+	return active, nil
+}
+
+func (r *UserRepository) isLoginTakenByIdentifier(ctx context.Context, tx pgx.Tx, userName string, userID string) (bool, error) {
+	if err := lockLogin(ctx, tx, userName); err != nil {
+		r.logger.Error("Error during lock login", zap.String("userName", userName), zap.Error(err))
+		return false, err
+	}
+
+	var taken bool
+	query := "select exists(select 1 from gofemart.user_identifier where value = lower($1) and verified and user_id <> $2)"
+	if err := tx.QueryRow(ctx, query, userName, userID).Scan(&taken); err != nil {
+		r.logger.Error("Error during check exist identifier", zap.String("userName", userName), zap.Error(err))
+		return false, err
+	}
+	return taken, nil
+}
 
-	return user, nil
+func lockLogin(ctx context.Context, tx pgx.Tx, login string) error {
+	_, err := tx.Exec(ctx, "select pg_advisory_xact_lock(hashtext(lower($1)))", login)
+	return err
 }
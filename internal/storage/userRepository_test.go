@@ -3,9 +3,11 @@ package storage
 import (
 	"context"
 	"github.com/desepticon55/gofemart/internal"
+	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 	"testing"
+	"time"
 )
 
 func TestUserRepository(t *testing.T) {
@@ -21,6 +23,7 @@ func TestUserRepository(t *testing.T) {
 	})
 
 	userRepository := NewUserRepository(pool, logger)
+	testUser := model.User{ID: internal.TestUserID, Username: "testUser", Password: "testPassword"}
 
 	t.Run("ExistUser", func(t *testing.T) {
 		t.Cleanup(func() {
@@ -29,7 +32,7 @@ func TestUserRepository(t *testing.T) {
 			}
 		})
 
-		err := userRepository.CreateUser(ctx, "testUser", "testPassword")
+		err := userRepository.CreateUser(ctx, testUser)
 		assert.NoError(t, err)
 
 		result, err := userRepository.ExistUser(ctx, "testUser")
@@ -44,13 +47,24 @@ func TestUserRepository(t *testing.T) {
 			}
 		})
 
-		err := userRepository.CreateUser(ctx, "testUser", "testPassword")
+		err := userRepository.CreateUser(ctx, testUser)
 		assert.NoError(t, err)
 
 		var balance float64
-		err = pool.QueryRow(ctx, `SELECT balance FROM gofemart.balance WHERE username = $1`, "testUser").Scan(&balance)
+		err = pool.QueryRow(ctx, `SELECT balance FROM gofemart.balance WHERE user_id = $1`, internal.TestUserID).Scan(&balance)
 		assert.NoError(t, err)
 		assert.Equal(t, 0., balance)
+
+		err = userRepository.CreateUser(ctx, model.User{ID: "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d", Username: "TESTUSER", Password: "testPassword"})
+		assert.Equal(t, model.ErrUserAlreadyExists, err)
+
+		active, err := userRepository.IsUserActive(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.True(t, active)
+
+		active, err = userRepository.IsUserActive(ctx, "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d")
+		assert.NoError(t, err)
+		assert.False(t, active)
 	})
 
 	t.Run("FindUser", func(t *testing.T) {
@@ -59,12 +73,84 @@ func TestUserRepository(t *testing.T) {
 				t.Fatalf("failed to clear tables: %s", err)
 			}
 		})
-		err := userRepository.CreateUser(ctx, "testUser", "testPassword")
+		err := userRepository.CreateUser(ctx, testUser)
 		assert.NoError(t, err)
 
 		result, err := userRepository.FindUser(ctx, "testUser")
 		assert.NoError(t, err)
+		assert.Equal(t, internal.TestUserID, result.ID)
 		assert.Equal(t, "testUser", result.Username)
 		assert.Equal(t, "testPassword", result.Password)
 	})
+	t.Run("ChangeUsername", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		err := userRepository.CreateUser(ctx, testUser)
+		assert.NoError(t, err)
+		err = userRepository.CreateUser(ctx, model.User{ID: "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d", Username: "otheruser", Password: "testPassword"})
+		assert.NoError(t, err)
+
+		err = userRepository.ChangeUsername(ctx, internal.TestUserID, "otheruser")
+		assert.Equal(t, model.ErrUserAlreadyExists, err)
+
+		err = userRepository.ChangeUsername(ctx, internal.TestUserID, "renamed")
+		assert.NoError(t, err)
+
+		result, err := userRepository.FindUser(ctx, "renamed")
+		assert.NoError(t, err)
+		assert.Equal(t, internal.TestUserID, result.ID)
+	})
+
+	t.Run("Identifiers", func(t *testing.T) {
+		t.Cleanup(func() {
+			if err := internal.ClearTables(ctx, pool); err != nil {
+				t.Fatalf("failed to clear tables: %s", err)
+			}
+		})
+
+		err := userRepository.CreateUser(ctx, testUser)
+		assert.NoError(t, err)
+
+		now := time.Now()
+		identifier := model.UserIdentifier{ID: "0f8c2d6e-3b1a-4c5d-9e7f-1a2b3c4d5e6f", UserID: internal.TestUserID, Type: model.EmailIdentifierType,
+			Value: "user@example.com", CodeHash: "hash", CodeExpireDate: now.Add(time.Minute), CreateDate: now}
+		saved, err := userRepository.SaveIdentifier(ctx, identifier)
+		assert.NoError(t, err)
+		assert.Equal(t, identifier.ID, saved.ID)
+
+		_, err = userRepository.FindUser(ctx, "user@example.com")
+		assert.Error(t, err)
+
+		err = userRepository.IncrementVerificationAttempts(ctx, identifier.ID)
+		assert.NoError(t, err)
+
+		found, err := userRepository.FindIdentifier(ctx, identifier.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, found.AttemptCount)
+		assert.Equal(t, "hash", found.CodeHash)
+
+		err = userRepository.VerifyIdentifier(ctx, found)
+		assert.NoError(t, err)
+
+		result, err := userRepository.FindUser(ctx, "user@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, internal.TestUserID, result.ID)
+
+		_, err = userRepository.SaveIdentifier(ctx, identifier)
+		assert.Equal(t, model.ErrIdentifierAlreadyExists, err)
+
+		identifiers, err := userRepository.FindIdentifiers(ctx, internal.TestUserID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(identifiers))
+		assert.True(t, identifiers[0].Verified)
+
+		err = userRepository.DeleteIdentifier(ctx, internal.TestUserID, identifier.ID)
+		assert.NoError(t, err)
+		err = userRepository.DeleteIdentifier(ctx, internal.TestUserID, identifier.ID)
+		assert.Equal(t, model.ErrIdentifierWasNotFound, err)
+	})
 }
@@ -2,9 +2,11 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"github.com/desepticon55/gofemart/internal/model"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
+	"strings"
 )
 
 type WithdrawalRepository struct {
@@ -12,16 +14,17 @@ type WithdrawalRepository struct {
 	logger *zap.Logger
 }
 
-func NewWithdrawalRepository(pool *pgxpool.Pool, logger *zap.Logger) *OrderRepository {
-	return &OrderRepository{
+func NewWithdrawalRepository(pool *pgxpool.Pool, logger *zap.Logger) *WithdrawalRepository {
+	return &WithdrawalRepository{
 		pool:   pool,
 		logger: logger,
 	}
 }
 
-func (r *OrderRepository) FindAllWithdrawals(ctx context.Context, userName string) ([]model.Withdrawal, error) {
-	query := "select id, order_number, username, sum, create_date from gofemart.withdrawal where username = $1 order by create_date"
-	rows, err := r.pool.Query(ctx, query, userName)
+func (r *WithdrawalRepository) FindAllWithdrawals(ctx context.Context, userID string) ([]model.Withdrawal, error) {
+	query := `select id, order_number, user_id, sum, create_date, coalesce(reward_id::text, '')
+			  from gofemart.withdrawal where user_id = $1 order by create_date`
+	rows, err := r.pool.Query(ctx, query, userID)
 	if err != nil {
 		r.logger.Error("Error during execute query", zap.Error(err))
 		return nil, err
@@ -31,7 +34,7 @@ func (r *OrderRepository) FindAllWithdrawals(ctx context.Context, userName strin
 	var withdrawals []model.Withdrawal
 	for rows.Next() {
 		var withdrawal model.Withdrawal
-		if err := rows.Scan(&withdrawal.ID, &withdrawal.OrderNumber, &withdrawal.Username, &withdrawal.Sum, &withdrawal.CreateDate); err != nil {
+		if err := rows.Scan(&withdrawal.ID, &withdrawal.OrderNumber, &withdrawal.UserID, &withdrawal.Sum, &withdrawal.CreateDate, &withdrawal.RewardID); err != nil {
 			r.logger.Error("Error during scan row", zap.Error(err))
 			continue
 		}
@@ -45,3 +48,41 @@ func (r *OrderRepository) FindAllWithdrawals(ctx context.Context, userName strin
 
 	return withdrawals, nil
 }
+
+func (r *WithdrawalRepository) FindWithdrawalsPage(ctx context.Context, userID string, page model.PageRequest) (model.Page[model.Withdrawal], error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	conditions, args = appendPageConditions(conditions, args, page, "create_date", "id")
+
+	query := fmt.Sprintf(`select id, order_number, user_id, sum, create_date, coalesce(reward_id::text, '')
+			  from gofemart.withdrawal where %s %s`, strings.Join(conditions, " and "), pageOrderBy(page, "create_date", "id"))
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Error during execute query", zap.Error(err))
+		return model.Page[model.Withdrawal]{}, err
+	}
+	defer rows.Close()
+
+	var withdrawals []model.Withdrawal
+	for rows.Next() {
+		var withdrawal model.Withdrawal
+		if err := rows.Scan(&withdrawal.ID, &withdrawal.OrderNumber, &withdrawal.UserID, &withdrawal.Sum, &withdrawal.CreateDate, &withdrawal.RewardID); err != nil {
+			r.logger.Error("Error during scan row", zap.Error(err))
+			continue
+		}
+
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return model.Page[model.Withdrawal]{}, err
+	}
+
+	result := model.Page[model.Withdrawal]{Items: withdrawals}
+	if len(withdrawals) > page.Limit {
+		result.Items = withdrawals[:page.Limit]
+		last := result.Items[page.Limit-1]
+		result.NextCursor = model.PageCursor{CreateDate: last.CreateDate, Key: last.ID}.Encode()
+	}
+	return result, nil
+}
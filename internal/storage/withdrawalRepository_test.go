@@ -30,17 +30,21 @@ func TestWithdrawalRepository(t *testing.T) {
 			}
 		})
 
-		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.withdrawal (id, order_number, username, sum, create_date) VALUES ($1, $2, $3, $4, $5)`,
-			"c6c2a5b1-5c3b-4a70-a18b-7e1a7397c118", "12345678903", "testUser", 45., time.Now()); err != nil {
+		if err := internal.CreateTestUser(ctx, pool, internal.TestUserID, "testUser"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, `INSERT INTO gofemart.withdrawal (id, order_number, user_id, sum, create_date) VALUES ($1, $2, $3, $4, $5)`,
+			"c6c2a5b1-5c3b-4a70-a18b-7e1a7397c118", "12345678903", internal.TestUserID, 45., time.Now()); err != nil {
 			t.Fatalf("failed to insert balance: %v", err)
 		}
 
-		result, err := withdrawalRepository.FindAllWithdrawals(ctx, "testUser")
+		result, err := withdrawalRepository.FindAllWithdrawals(ctx, internal.TestUserID)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(result))
 		assert.Equal(t, "c6c2a5b1-5c3b-4a70-a18b-7e1a7397c118", result[0].ID)
 		assert.Equal(t, "12345678903", result[0].OrderNumber)
-		assert.Equal(t, "testUser", result[0].Username)
+		assert.Equal(t, internal.TestUserID, result[0].UserID)
 		assert.Equal(t, 45., result[0].Sum)
 	})
 }
@@ -52,8 +52,18 @@ func InitPostgresIntegrationTest(t *testing.T, ctx context.Context, logger *zap.
 	return pool, cleanup
 }
 
+const TestUserID = "5f0d1a3c-8b7e-4c2d-9a6f-1e2b3c4d5e6f"
+
+func CreateTestUser(ctx context.Context, pool *pgxpool.Pool, userID string, userName string) error {
+	query := "INSERT INTO gofemart.user (id, username, password) VALUES ($1, $2, '')"
+	if _, err := pool.Exec(ctx, query, userID, userName); err != nil {
+		return fmt.Errorf("failed to create user %s: %w", userName, err)
+	}
+	return nil
+}
+
 func ClearTables(ctx context.Context, pool *pgxpool.Pool) error {
-	tables := []string{"balance", "withdrawal", "order", "user"}
+	tables := []string{"rate_limit", "worker_membership", "user_identifier", "payout", "statement", "hold", "redemption", "reward", "balance", "withdrawal", "order_status_history", "order", "user"}
 	for _, table := range tables {
 		query := fmt.Sprintf("TRUNCATE TABLE gofemart.%s CASCADE", table)
 		if _, err := pool.Exec(ctx, query); err != nil {
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+type PgxLogger struct{}
+
+func NewPgxLogger() *PgxLogger {
+	return &PgxLogger{}
+}
+
+func (l *PgxLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	duration, ok := data["time"].(time.Duration)
+	if !ok {
+		return
+	}
+
+	end := time.Now()
+	attributes := []attribute.KeyValue{semconv.DBSystemPostgreSQL}
+	if sql, ok := data["sql"].(string); ok {
+		attributes = append(attributes, semconv.DBStatement(strings.TrimSpace(sql)))
+	}
+	if rowCount, ok := data["rowCount"].(int); ok {
+		attributes = append(attributes, attribute.Int("db.row_count", rowCount))
+	}
+
+	// pgx reports a query only after it finished
+	_, span := Tracer().Start(ctx, "db."+strings.ToLower(msg),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-duration)),
+		trace.WithAttributes(attributes...))
+	if err, ok := data["err"]; ok && err != nil {
+		span.SetStatus(codes.Error, fmt.Sprint(err))
+	}
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestPgxLogger(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	ctx, parent := Start(context.Background(), "OrderService.FindOrder")
+	logger := NewPgxLogger()
+	logger.Log(ctx, pgx.LogLevelInfo, "Query", map[string]interface{}{
+		"sql":      " SELECT 1 ",
+		"time":     50 * time.Millisecond,
+		"rowCount": 1,
+	})
+	logger.Log(ctx, pgx.LogLevelError, "Exec", map[string]interface{}{
+		"sql":  "UPDATE gofemart.order SET status = $1",
+		"time": time.Millisecond,
+		"err":  errors.New("deadlock detected"),
+	})
+	logger.Log(ctx, pgx.LogLevelInfo, "closed connection", map[string]interface{}{})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 3, len(spans))
+
+	query := spans[0]
+	assert.Equal(t, "db.query", query.Name)
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID())
+	assert.Equal(t, 50*time.Millisecond, query.EndTime.Sub(query.StartTime))
+	assert.Contains(t, query.Attributes, semconv.DBStatement("SELECT 1"))
+
+	exec := spans[1]
+	assert.Equal(t, "db.exec", exec.Name)
+	assert.Equal(t, codes.Error, exec.Status.Code)
+	assert.Equal(t, "deadlock detected", exec.Status.Description)
+
+	assert.Equal(t, "OrderService.FindOrder", spans[2].Name)
+}
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE gofemart.reward
+(
+    id          UUID UNIQUE              NOT NULL,
+    name        VARCHAR(255)             NOT NULL,
+    description TEXT                     NOT NULL,
+    price       NUMERIC(18, 2)           NOT NULL,
+    stock       BIGINT                   NOT NULL,
+    valid_from  TIMESTAMP WITH TIME ZONE,
+    valid_to    TIMESTAMP WITH TIME ZONE,
+    archived    BOOLEAN                  NOT NULL DEFAULT FALSE,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    opt_lock    BIGINT                   NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE gofemart.redemption
+(
+    id          UUID UNIQUE              NOT NULL,
+    reward_id   UUID                     NOT NULL REFERENCES gofemart.reward (id),
+    username    VARCHAR(255)             NOT NULL,
+    code        VARCHAR(32) UNIQUE       NOT NULL,
+    sum         NUMERIC(18, 2)           NOT NULL,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX redemption_username_idx ON gofemart.redemption (username);
+
+ALTER TABLE gofemart.withdrawal ADD COLUMN reward_id UUID;
+
+-- +goose Down
+ALTER TABLE gofemart.withdrawal DROP COLUMN reward_id;
+DROP TABLE gofemart.redemption;
+DROP TABLE gofemart.reward;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE gofemart.balance ADD COLUMN reserved NUMERIC(18, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE gofemart.hold
+(
+    id               UUID UNIQUE              NOT NULL,
+    order_number     VARCHAR(255)             NOT NULL,
+    username         VARCHAR(255)             NOT NULL,
+    sum              NUMERIC(18, 2)           NOT NULL,
+    status           VARCHAR(50)              NOT NULL,
+    create_date      TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_modify_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    expire_date      TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX hold_username_idx ON gofemart.hold (username);
+CREATE INDEX hold_status_expire_date_idx ON gofemart.hold (status, expire_date);
+
+-- +goose Down
+DROP TABLE gofemart.hold;
+ALTER TABLE gofemart.balance DROP COLUMN reserved;
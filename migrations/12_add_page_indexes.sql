@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE INDEX order_username_create_date_idx ON gofemart.order (username, create_date, order_number);
+CREATE INDEX withdrawal_username_create_date_idx ON gofemart.withdrawal (username, create_date, id);
+
+-- +goose Down
+DROP INDEX gofemart.order_username_create_date_idx;
+DROP INDEX gofemart.withdrawal_username_create_date_idx;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE gofemart.order_status_history
+(
+    id           BIGSERIAL                NOT NULL,
+    order_number VARCHAR(255)             NOT NULL REFERENCES gofemart.order (order_number) ON DELETE CASCADE,
+    status       VARCHAR(50)              NOT NULL,
+    accrual      NUMERIC(18, 2),
+    create_date  TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX order_status_history_order_number_idx ON gofemart.order_status_history (order_number, create_date);
+
+INSERT INTO gofemart.order_status_history (order_number, status, accrual, create_date)
+SELECT order_number, 'NEW', NULL, create_date
+FROM gofemart.order;
+
+INSERT INTO gofemart.order_status_history (order_number, status, accrual, create_date)
+SELECT order_number, status, accrual, last_modify_date
+FROM gofemart.order
+WHERE status <> 'NEW';
+
+-- +goose Down
+DROP TABLE gofemart.order_status_history;
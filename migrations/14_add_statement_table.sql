@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE gofemart.statement
+(
+    id              UUID UNIQUE              NOT NULL,
+    username        VARCHAR(255)             NOT NULL,
+    period_start    TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end      TIMESTAMP WITH TIME ZONE NOT NULL,
+    opening_balance NUMERIC(18, 2)           NOT NULL,
+    accrued         NUMERIC(18, 2)           NOT NULL,
+    withdrawn       NUMERIC(18, 2)           NOT NULL,
+    closing_balance NUMERIC(18, 2)           NOT NULL,
+    pdf_key         VARCHAR(255)             NOT NULL,
+    csv_key         VARCHAR(255)             NOT NULL,
+    create_date     TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (username, period_start)
+);
+
+CREATE INDEX order_status_last_modify_date_idx ON gofemart.order (status, last_modify_date);
+
+-- +goose Down
+DROP INDEX gofemart.order_status_last_modify_date_idx;
+DROP TABLE gofemart.statement;
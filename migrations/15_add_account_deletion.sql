@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE gofemart.user ADD COLUMN delete_after TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gofemart.user ADD COLUMN balance_settlement VARCHAR(50);
+ALTER TABLE gofemart.user ADD COLUMN payout_id UUID;
+
+CREATE INDEX user_delete_after_idx ON gofemart.user (delete_after) WHERE delete_after IS NOT NULL;
+CREATE INDEX redemption_username_create_date_idx ON gofemart.redemption (username, create_date);
+
+CREATE TABLE gofemart.payout
+(
+    id          UUID UNIQUE              NOT NULL,
+    username    VARCHAR(255)             NOT NULL,
+    sum         NUMERIC(18, 2)           NOT NULL,
+    status      VARCHAR(50)              NOT NULL,
+    create_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id)
+);
+
+-- +goose Down
+DROP TABLE gofemart.payout;
+DROP INDEX gofemart.redemption_username_create_date_idx;
+DROP INDEX gofemart.user_delete_after_idx;
+ALTER TABLE gofemart.user DROP COLUMN payout_id;
+ALTER TABLE gofemart.user DROP COLUMN balance_settlement;
+ALTER TABLE gofemart.user DROP COLUMN delete_after;
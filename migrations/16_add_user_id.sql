@@ -0,0 +1,124 @@
+-- +goose Up
+ALTER TABLE gofemart.user ADD COLUMN id UUID;
+ALTER TABLE gofemart.user ADD COLUMN deleted_date TIMESTAMP WITH TIME ZONE;
+
+INSERT INTO gofemart.user (username, password, deleted_date)
+SELECT DISTINCT t.username, '', now()
+FROM (SELECT username FROM gofemart.order
+      UNION
+      SELECT username FROM gofemart.balance
+      UNION
+      SELECT username FROM gofemart.withdrawal
+      UNION
+      SELECT username FROM gofemart.hold
+      UNION
+      SELECT username FROM gofemart.redemption
+      UNION
+      SELECT username FROM gofemart.statement
+      UNION
+      SELECT username FROM gofemart.payout) t
+WHERE NOT EXISTS (SELECT 1 FROM gofemart.user u WHERE u.username = t.username);
+
+UPDATE gofemart.user SET id = gen_random_uuid();
+ALTER TABLE gofemart.user ALTER COLUMN id SET NOT NULL;
+ALTER TABLE gofemart.user DROP CONSTRAINT user_pkey;
+ALTER TABLE gofemart.user ADD PRIMARY KEY (id);
+
+ALTER TABLE gofemart.order ADD COLUMN user_id UUID;
+UPDATE gofemart.order t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.order ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.order ADD CONSTRAINT order_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.order DROP COLUMN username;
+
+ALTER TABLE gofemart.balance ADD COLUMN user_id UUID;
+UPDATE gofemart.balance t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.balance ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.balance ADD CONSTRAINT balance_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.balance DROP COLUMN username;
+
+ALTER TABLE gofemart.withdrawal ADD COLUMN user_id UUID;
+UPDATE gofemart.withdrawal t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.withdrawal ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.withdrawal ADD CONSTRAINT withdrawal_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.withdrawal DROP COLUMN username;
+
+ALTER TABLE gofemart.hold ADD COLUMN user_id UUID;
+UPDATE gofemart.hold t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.hold ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.hold ADD CONSTRAINT hold_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.hold DROP COLUMN username;
+
+ALTER TABLE gofemart.redemption ADD COLUMN user_id UUID;
+UPDATE gofemart.redemption t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.redemption ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.redemption ADD CONSTRAINT redemption_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.redemption DROP COLUMN username;
+
+ALTER TABLE gofemart.statement ADD COLUMN user_id UUID;
+UPDATE gofemart.statement t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.statement ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.statement ADD CONSTRAINT statement_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.statement DROP COLUMN username;
+
+ALTER TABLE gofemart.payout ADD COLUMN user_id UUID;
+UPDATE gofemart.payout t SET user_id = u.id FROM gofemart.user u WHERE u.username = t.username;
+ALTER TABLE gofemart.payout ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE gofemart.payout ADD CONSTRAINT payout_user_id_fkey FOREIGN KEY (user_id) REFERENCES gofemart.user (id);
+ALTER TABLE gofemart.payout DROP COLUMN username;
+
+ALTER TABLE gofemart.balance ADD PRIMARY KEY (user_id);
+ALTER TABLE gofemart.statement ADD CONSTRAINT statement_user_id_period_start_key UNIQUE (user_id, period_start);
+CREATE INDEX order_user_id_create_date_idx ON gofemart.order (user_id, create_date, order_number);
+CREATE INDEX withdrawal_user_id_create_date_idx ON gofemart.withdrawal (user_id, create_date, id);
+CREATE INDEX hold_user_id_idx ON gofemart.hold (user_id);
+CREATE INDEX redemption_user_id_create_date_idx ON gofemart.redemption (user_id, create_date);
+
+-- +goose Down
+ALTER TABLE gofemart.order ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.order t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.order ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.order DROP COLUMN user_id;
+
+ALTER TABLE gofemart.balance ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.balance t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.balance ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.balance DROP COLUMN user_id;
+
+ALTER TABLE gofemart.withdrawal ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.withdrawal t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.withdrawal ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.withdrawal DROP COLUMN user_id;
+
+ALTER TABLE gofemart.hold ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.hold t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.hold ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.hold DROP COLUMN user_id;
+
+ALTER TABLE gofemart.redemption ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.redemption t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.redemption ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.redemption DROP COLUMN user_id;
+
+ALTER TABLE gofemart.statement ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.statement t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.statement ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.statement DROP COLUMN user_id;
+
+ALTER TABLE gofemart.payout ADD COLUMN username VARCHAR(255);
+UPDATE gofemart.payout t SET username = u.username FROM gofemart.user u WHERE u.id = t.user_id;
+ALTER TABLE gofemart.payout ALTER COLUMN username SET NOT NULL;
+ALTER TABLE gofemart.payout DROP COLUMN user_id;
+
+ALTER TABLE gofemart.balance ADD PRIMARY KEY (username);
+ALTER TABLE gofemart.statement ADD CONSTRAINT statement_username_period_start_key UNIQUE (username, period_start);
+CREATE INDEX order_username_idx ON gofemart.order (username);
+CREATE INDEX order_username_create_date_idx ON gofemart.order (username, create_date, order_number);
+CREATE INDEX withdrawal_username_create_date_idx ON gofemart.withdrawal (username, create_date, id);
+CREATE INDEX hold_username_idx ON gofemart.hold (username);
+CREATE INDEX redemption_username_idx ON gofemart.redemption (username);
+CREATE INDEX redemption_username_create_date_idx ON gofemart.redemption (username, create_date);
+
+ALTER TABLE gofemart.user DROP CONSTRAINT user_pkey;
+ALTER TABLE gofemart.user ADD PRIMARY KEY (username);
+ALTER TABLE gofemart.user DROP COLUMN deleted_date;
+ALTER TABLE gofemart.user DROP COLUMN id;
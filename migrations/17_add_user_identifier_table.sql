@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE gofemart.user_identifier
+(
+    id               UUID UNIQUE              NOT NULL,
+    user_id          UUID                     NOT NULL REFERENCES gofemart.user (id) ON DELETE CASCADE,
+    type             VARCHAR(50)              NOT NULL,
+    value            VARCHAR(255)             NOT NULL,
+    verified         BOOLEAN                  NOT NULL DEFAULT FALSE,
+    code_hash        TEXT,
+    code_expire_date TIMESTAMP WITH TIME ZONE,
+    attempt_count    INTEGER                  NOT NULL DEFAULT 0,
+    create_date      TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (user_id, value)
+);
+
+CREATE UNIQUE INDEX user_identifier_verified_value_idx ON gofemart.user_identifier (value) WHERE verified;
+CREATE INDEX user_username_lower_idx ON gofemart.user (lower(username));
+
+-- +goose Down
+DROP INDEX gofemart.user_username_lower_idx;
+DROP TABLE gofemart.user_identifier;
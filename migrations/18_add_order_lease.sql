@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE gofemart.order ADD COLUMN claimed_by VARCHAR(255);
+ALTER TABLE gofemart.order ADD COLUMN lease_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX order_to_process_idx ON gofemart.order (last_modify_date) WHERE status IN ('NEW', 'PROCESSING');
+
+-- +goose Down
+DROP INDEX gofemart.order_to_process_idx;
+ALTER TABLE gofemart.order DROP COLUMN lease_until;
+ALTER TABLE gofemart.order DROP COLUMN claimed_by;
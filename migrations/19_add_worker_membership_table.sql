@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE gofemart.worker_membership
+(
+    worker_id      VARCHAR(255)             NOT NULL,
+    heartbeat_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    create_date    TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (worker_id)
+);
+
+UPDATE gofemart.order SET key_hash_module = abs(hashtext(order_number)) % 256 WHERE key_hash_module IS NULL;
+
+-- +goose Down
+DROP TABLE gofemart.worker_membership;
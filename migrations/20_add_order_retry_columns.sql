@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE gofemart.order ADD COLUMN attempt_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE gofemart.order ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE gofemart.order ADD COLUMN last_error TEXT;
+ALTER TABLE gofemart.order ADD COLUMN dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX order_dead_lettered_at_idx ON gofemart.order (dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX gofemart.order_dead_lettered_at_idx;
+ALTER TABLE gofemart.order DROP COLUMN dead_lettered_at;
+ALTER TABLE gofemart.order DROP COLUMN last_error;
+ALTER TABLE gofemart.order DROP COLUMN next_attempt_at;
+ALTER TABLE gofemart.order DROP COLUMN attempt_count;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE gofemart.rate_limit
+(
+    name            VARCHAR(255)             NOT NULL,
+    tokens          DOUBLE PRECISION         NOT NULL,
+    capacity        DOUBLE PRECISION         NOT NULL,
+    rate_per_second DOUBLE PRECISION         NOT NULL,
+    paused_until    TIMESTAMP WITH TIME ZONE,
+    update_date     TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (name)
+);
+
+-- +goose Down
+DROP TABLE gofemart.rate_limit;
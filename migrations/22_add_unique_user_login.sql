@@ -0,0 +1,19 @@
+-- +goose Up
+WITH ranked AS (SELECT id, row_number() OVER (PARTITION BY lower(username) ORDER BY deleted_date IS NOT NULL, id) AS rn
+                FROM gofemart.user)
+UPDATE gofemart.user u
+SET username = left(u.username, 246) || '~' || left(u.id::text, 8)
+FROM ranked r
+WHERE r.id = u.id AND r.rn > 1;
+
+UPDATE gofemart.user_identifier i
+SET verified = false
+WHERE i.verified
+  AND EXISTS (SELECT 1 FROM gofemart.user u WHERE lower(u.username) = i.value AND u.id <> i.user_id);
+
+DROP INDEX gofemart.user_username_lower_idx;
+CREATE UNIQUE INDEX user_username_lower_idx ON gofemart.user (lower(username));
+
+-- +goose Down
+DROP INDEX gofemart.user_username_lower_idx;
+CREATE INDEX user_username_lower_idx ON gofemart.user (lower(username));
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE gofemart.rate_limit ADD COLUMN max_rate_per_second DOUBLE PRECISION;
+UPDATE gofemart.rate_limit SET max_rate_per_second = rate_per_second;
+ALTER TABLE gofemart.rate_limit ALTER COLUMN max_rate_per_second SET NOT NULL;
+ALTER TABLE gofemart.rate_limit ADD COLUMN adjust_date TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+-- +goose Down
+ALTER TABLE gofemart.rate_limit DROP COLUMN adjust_date;
+ALTER TABLE gofemart.rate_limit DROP COLUMN max_rate_per_second;